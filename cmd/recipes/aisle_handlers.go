@@ -0,0 +1,170 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/cdriehuys/recipes/internal/ingredients"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/validation"
+	"github.com/google/uuid"
+)
+
+// shoppingListAisle is the group of shopping list items found in a single aisle. Items without a
+// known aisle are grouped under the zero StoreAisle.
+type shoppingListAisle struct {
+	Aisle models.StoreAisle
+	Items []models.ShoppingListItem
+}
+
+// groupByAisle arranges shopping list items by the aisle they are found in, following the order of
+// the provided aisles. Items in unknown aisles are listed last, and the relative order of items
+// within an aisle is preserved. Aisles without any items are omitted.
+func groupByAisle(
+	items []models.ShoppingListItem,
+	aisles []models.StoreAisle,
+	assignments map[string]uuid.UUID,
+) []shoppingListAisle {
+	groups := make([]shoppingListAisle, len(aisles)+1)
+	positions := make(map[uuid.UUID]int, len(aisles))
+	for i, aisle := range aisles {
+		groups[i].Aisle = aisle
+		positions[aisle.ID] = i
+	}
+
+	for _, item := range items {
+		group := len(aisles)
+		if aisle, ok := assignments[ingredients.Key(item.Name)]; ok {
+			if i, ok := positions[aisle]; ok {
+				group = i
+			}
+		}
+
+		groups[group].Items = append(groups[group].Items, item)
+	}
+
+	nonEmpty := make([]shoppingListAisle, 0, len(groups))
+	for _, group := range groups {
+		if len(group.Items) > 0 {
+			nonEmpty = append(nonEmpty, group)
+		}
+	}
+
+	return nonEmpty
+}
+
+type aisleForm struct {
+	Name string
+	validation.Validator
+}
+
+func (form *aisleForm) Validate() {
+	form.CheckField(validation.NotBlank(form.Name), "name", "This field is required.")
+	form.CheckField(validation.MaxLength(form.Name, 50), "name", "This field may not contain more than 50 characters.")
+}
+
+func (app *application) storeLayout(w http.ResponseWriter, r *http.Request) {
+	app.renderStoreLayout(w, r, http.StatusOK, reqUser(r), &aisleForm{})
+}
+
+func (app *application) storeLayoutPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	form := aisleForm{
+		Name: r.PostFormValue("name"),
+	}
+	form.Validate()
+
+	if !form.IsValid() {
+		app.renderStoreLayout(w, r, http.StatusUnprocessableEntity, userID, &form)
+		return
+	}
+
+	aisle := models.StoreAisle{
+		ID:    uuid.New(),
+		Owner: userID,
+		Name:  form.Name,
+	}
+	if err := app.aisleModel.Create(r.Context(), aisle); err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/store-layout", http.StatusSeeOther)
+}
+
+func (app *application) moveAislePost(w http.ResponseWriter, r *http.Request) {
+	aisleID, ok := app.uuidPathValue(w, r, "aisleID")
+	if !ok {
+		return
+	}
+
+	offset := 1
+	if r.PostFormValue("direction") == "up" {
+		offset = -1
+	}
+
+	if err := app.aisleModel.Move(r.Context(), reqUser(r), aisleID, offset); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/store-layout", http.StatusSeeOther)
+}
+
+func (app *application) deleteAislePost(w http.ResponseWriter, r *http.Request) {
+	aisleID, ok := app.uuidPathValue(w, r, "aisleID")
+	if !ok {
+		return
+	}
+
+	if err := app.aisleModel.Delete(r.Context(), reqUser(r), aisleID); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/store-layout", http.StatusSeeOther)
+}
+
+// shoppingListItemAislePost remembers the aisle of a shopping list item's ingredient so that it and
+// any future items with the same name are grouped under that aisle.
+func (app *application) shoppingListItemAislePost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	itemID, ok := app.uuidPathValue(w, r, "itemID")
+	if !ok {
+		return
+	}
+
+	aisle := r.PostFormValue("aisle")
+	if !validation.UUIDOrBlank(aisle) {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	item, err := app.shoppingListModel.Get(r.Context(), userID, itemID)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	if err := app.aisleModel.Assign(r.Context(), userID, item.Name, optionalUUID(aisle)); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/shopping-list", http.StatusSeeOther)
+}
+
+func (app *application) renderStoreLayout(w http.ResponseWriter, r *http.Request, status int, userID string, form *aisleForm) {
+	aisles, err := app.aisleModel.List(r.Context(), userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Aisles = aisles
+	data.Form = form
+
+	app.render(w, r, status, "store-layout", data)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_groupByAisle(t *testing.T) {
+	produce := models.StoreAisle{ID: uuid.New(), Name: "Produce"}
+	dairy := models.StoreAisle{ID: uuid.New(), Name: "Dairy"}
+	bakery := models.StoreAisle{ID: uuid.New(), Name: "Bakery"}
+
+	milk := models.ShoppingListItem{Name: "Milk"}
+	apples := models.ShoppingListItem{Name: "apples"}
+	towels := models.ShoppingListItem{Name: "paper towels"}
+	cheese := models.ShoppingListItem{Name: "cheese"}
+
+	assignments := map[string]uuid.UUID{
+		"milk":   dairy.ID,
+		"apple":  produce.ID,
+		"cheese": dairy.ID,
+		// Assignments to aisles that no longer exist are ignored.
+		"paper towel": uuid.New(),
+	}
+
+	groups := groupByAisle(
+		[]models.ShoppingListItem{milk, apples, towels, cheese},
+		[]models.StoreAisle{produce, dairy, bakery},
+		assignments,
+	)
+
+	want := []shoppingListAisle{
+		{Aisle: produce, Items: []models.ShoppingListItem{apples}},
+		{Aisle: dairy, Items: []models.ShoppingListItem{milk, cheese}},
+		{Items: []models.ShoppingListItem{towels}},
+	}
+
+	assert.Equal(t, len(want), len(groups))
+	for i := range want {
+		assert.Equal(t, want[i].Aisle, groups[i].Aisle)
+		assert.Equal(t, len(want[i].Items), len(groups[i].Items))
+		for j := range want[i].Items {
+			assert.Equal(t, want[i].Items[j].Name, groups[i].Items[j].Name)
+		}
+	}
+}
+
+func Test_application_storeLayout(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, "/store-layout")
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, "/store-layout")
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, body := server.get(t, "/store-layout")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, mock.Aisle.Name)
+	})
+}
+
+func Test_application_storeLayoutPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/store-layout")
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name                  string
+		aisle                 string
+		wantStatus            int
+		wantValidationMessage string
+	}{
+		{
+			name:       "valid",
+			aisle:      "Produce",
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:                  "blank",
+			aisle:                 " ",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field is required.",
+		},
+		{
+			name:                  "too long",
+			aisle:                 strings.Repeat("a", 51),
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field may not contain more than 50 characters.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("name", tt.aisle)
+
+			status, headers, body := server.postForm(t, "/store-layout", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+			} else {
+				assertRedirects(t, headers, "/store-layout")
+
+				created := app.aisleModel.(*mock.AisleModel).LastCreatedAisle
+				assert.Equal(t, tt.aisle, created.Name)
+				assert.Equal(t, mock.TestUserNormal, created.Owner)
+			}
+		})
+	}
+}
+
+func Test_application_moveAislePost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/store-layout")
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		direction  string
+		wantOffset int
+	}{
+		{direction: "up", wantOffset: -1},
+		{direction: "down", wantOffset: 1},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.direction, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("direction", tt.direction)
+
+			status, headers, _ := server.postForm(t, "/store-layout/"+mock.Aisle.ID.String()+"/move", form)
+
+			assert.Equal(t, http.StatusSeeOther, status)
+			assertRedirects(t, headers, "/store-layout")
+
+			model := app.aisleModel.(*mock.AisleModel)
+			assert.Equal(t, mock.Aisle.ID, model.LastMovedAisle)
+			assert.Equal(t, tt.wantOffset, model.LastMoveOffset)
+		})
+	}
+}
+
+func Test_application_deleteAislePost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/store-layout")
+	csrfToken := extractCSRFToken(t, page)
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+
+	status, headers, _ := server.postForm(t, "/store-layout/"+mock.Aisle.ID.String()+"/delete", form)
+
+	assert.Equal(t, http.StatusSeeOther, status)
+	assertRedirects(t, headers, "/store-layout")
+	assert.Equal(t, mock.Aisle.ID, app.aisleModel.(*mock.AisleModel).LastDeletedAisle)
+}
+
+func Test_application_shoppingListItemAislePost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/shopping-list")
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name       string
+		item       uuid.UUID
+		aisle      string
+		wantStatus int
+		wantAisle  string
+	}{
+		{
+			name:       "assign",
+			item:       mock.ShoppingListItem.ID,
+			aisle:      mock.Aisle.ID.String(),
+			wantStatus: http.StatusSeeOther,
+			wantAisle:  mock.Aisle.ID.String(),
+		},
+		{
+			name:       "unassign",
+			item:       mock.ShoppingListItem.ID,
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:       "unknown aisle",
+			item:       mock.ShoppingListItem.ID,
+			aisle:      uuid.NewString(),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid aisle",
+			item:       mock.ShoppingListItem.ID,
+			aisle:      "dairy",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown item",
+			item:       uuid.New(),
+			aisle:      mock.Aisle.ID.String(),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			app.aisleModel = &mock.AisleModel{}
+
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("aisle", tt.aisle)
+
+			status, headers, _ := server.postForm(t, "/shopping-list/items/"+tt.item.String()+"/aisle", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantStatus == http.StatusSeeOther {
+				assertRedirects(t, headers, "/shopping-list")
+
+				model := app.aisleModel.(*mock.AisleModel)
+				assert.Equal(t, mock.ShoppingListItem.Name, model.LastAssignedIngredient)
+				assert.Equal(t, tt.wantAisle, optionalUUIDString(model.LastAssignedAisle))
+			}
+		})
+	}
+}
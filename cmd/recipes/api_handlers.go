@@ -0,0 +1,507 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cdriehuys/recipes"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+)
+
+// apiRecipe is the representation of a recipe in the API and in export files.
+type apiRecipe struct {
+	ID            uuid.UUID  `json:"id"`
+	Title         string     `json:"title"`
+	CategoryID    *uuid.UUID `json:"category_id"`
+	CategoryName  *string    `json:"category_name"`
+	HouseholdID   *uuid.UUID `json:"household_id"`
+	HouseholdName *string    `json:"household_name"`
+	Servings      *int16     `json:"servings"`
+	Ingredients   string     `json:"ingredients"`
+	Instructions  string     `json:"instructions"`
+	PrepMinutes   *int       `json:"prep_minutes"`
+	CookMinutes   *int       `json:"cook_minutes"`
+	TotalMinutes  *int       `json:"total_minutes"`
+	SourceName    string     `json:"source_name"`
+	SourceURL     string     `json:"source_url"`
+	Tags          []string   `json:"tags"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func newAPIRecipe(recipe models.Recipe) apiRecipe {
+	converted := apiRecipe{
+		ID:           recipe.ID,
+		Title:        recipe.Title,
+		CategoryID:   recipe.Category,
+		HouseholdID:  recipe.Household,
+		Ingredients:  recipe.Ingredients,
+		Instructions: recipe.Instructions,
+		PrepMinutes:  optionalMinutesPtr(recipe.PrepTime),
+		CookMinutes:  optionalMinutesPtr(recipe.CookTime),
+		TotalMinutes: optionalMinutesPtr(recipe.TotalTime),
+		SourceName:   recipe.SourceName,
+		SourceURL:    recipe.SourceURL,
+		Tags:         recipe.Tags,
+		CreatedAt:    recipe.CreatedAt,
+		UpdatedAt:    recipe.UpdatedAt,
+	}
+
+	if recipe.CategoryName.Valid {
+		converted.CategoryName = &recipe.CategoryName.String
+	}
+
+	if recipe.HouseholdName.Valid {
+		converted.HouseholdName = &recipe.HouseholdName.String
+	}
+
+	if recipe.Servings.Valid {
+		converted.Servings = &recipe.Servings.Int16
+	}
+
+	if converted.Tags == nil {
+		converted.Tags = []string{}
+	}
+
+	return converted
+}
+
+// optionalMinutesPtr returns the number of minutes in a possibly null duration, or nil if it is
+// null.
+func optionalMinutesPtr(d models.Duration) *int {
+	if !d.Valid {
+		return nil
+	}
+
+	minutes := d.Minutes()
+	return &minutes
+}
+
+// apiRecipeInput is the body of a request to create or update a recipe.
+type apiRecipeInput struct {
+	Title        string     `json:"title"`
+	CategoryID   *uuid.UUID `json:"category_id"`
+	HouseholdID  *uuid.UUID `json:"household_id"`
+	Servings     *int       `json:"servings"`
+	Ingredients  string     `json:"ingredients"`
+	Instructions string     `json:"instructions"`
+	PrepMinutes  *int       `json:"prep_minutes"`
+	CookMinutes  *int       `json:"cook_minutes"`
+	TotalMinutes *int       `json:"total_minutes"`
+	SourceName   string     `json:"source_name"`
+	SourceURL    string     `json:"source_url"`
+	// UpdatedAt is the version of the recipe the update was made to. If it is provided and the
+	// recipe has been modified since, the update is rejected with a conflict.
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// recipeAPIFields maps the names of recipe form fields to the names of the equivalent API fields.
+var recipeAPIFields = map[string]string{
+	"category":   "category_id",
+	"household":  "household_id",
+	"prepTime":   "prep_minutes",
+	"cookTime":   "cook_minutes",
+	"totalTime":  "total_minutes",
+	"sourceName": "source_name",
+	"sourceURL":  "source_url",
+	"version":    "updated_at",
+}
+
+// form converts the input into a recipe form so that it is validated the same way as a submitted
+// form.
+func (input apiRecipeInput) form() RecipeForm {
+	form := RecipeForm{
+		Category:     optionalUUIDString(input.CategoryID),
+		Household:    optionalUUIDString(input.HouseholdID),
+		Title:        input.Title,
+		Servings:     optionalIntString(input.Servings),
+		Ingredients:  input.Ingredients,
+		Instructions: input.Instructions,
+		PrepTime:     optionalIntString(input.PrepMinutes),
+		CookTime:     optionalIntString(input.CookMinutes),
+		TotalTime:    optionalIntString(input.TotalMinutes),
+		SourceName:   input.SourceName,
+		SourceURL:    input.SourceURL,
+	}
+
+	if input.UpdatedAt != nil {
+		form.Version = input.UpdatedAt.Format(time.RFC3339Nano)
+	}
+
+	return form
+}
+
+// optionalIntString returns the string representation of a possibly nil number.
+func optionalIntString(i *int) string {
+	if i == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*i)
+}
+
+// validRecipeInput decodes and validates the body of a request to create or update a recipe. If
+// the body is invalid, an error response is sent and false is returned.
+func (app *application) validRecipeInput(w http.ResponseWriter, r *http.Request, userID string, message string) (RecipeForm, bool) {
+	var input apiRecipeInput
+	if !app.readJSON(w, r, &input) {
+		return RecipeForm{}, false
+	}
+
+	form := input.form()
+	form.Validate()
+
+	households, err := app.editableHouseholds(r, userID)
+	if err != nil {
+		app.apiServerError(w, r, err)
+		return RecipeForm{}, false
+	}
+
+	form.CheckField(canAddToHousehold(households, form.Household), "household", message)
+
+	if !form.IsValid() {
+		app.apiValidationError(w, r, form.FieldErrors, recipeAPIFields)
+		return RecipeForm{}, false
+	}
+
+	return form, true
+}
+
+func (app *application) apiListRecipes(w http.ResponseWriter, r *http.Request) {
+	page, ok := app.apiPage(w, r)
+	if !ok {
+		return
+	}
+
+	// The same filters and sorts as the recipe list page are supported. One extra recipe is
+	// requested to determine if there is a next page.
+	opts := recipeListOptions(r.URL.Query())
+	opts.Limit = page.PerPage + 1
+	opts.Offset = page.offset()
+
+	recipes, err := app.recipeModel.List(r.Context(), reqUser(r), opts)
+	if err != nil {
+		app.apiServerError(w, r, err)
+		return
+	}
+
+	data := make([]apiRecipe, 0, len(recipes))
+	for _, recipe := range recipes {
+		data = append(data, newAPIRecipe(recipe))
+	}
+
+	app.writeJSON(w, r, http.StatusOK, newAPIList(r, page, data))
+}
+
+func (app *application) apiGetRecipe(w http.ResponseWriter, r *http.Request) {
+	id, ok := app.apiUUIDPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	recipe, err := app.recipeModel.GetByID(r.Context(), reqUser(r), id)
+	if err != nil {
+		app.apiModelError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, r, http.StatusOK, newAPIRecipe(recipe))
+}
+
+func (app *application) apiCreateRecipe(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	form, ok := app.validRecipeInput(w, r, userID, "You may not add recipes to this household.")
+	if !ok {
+		return
+	}
+
+	recipe := form.recipe(uuid.New())
+	recipe.Owner = userID
+
+	if err := app.recipeModel.Add(r.Context(), recipe); err != nil {
+		app.apiModelError(w, r, err)
+		return
+	}
+
+	app.sendRecipeEvents(r, userID, models.EventRecipeCreated, recipe.ID)
+	app.writeRecipe(w, r, http.StatusCreated, userID, recipe.ID)
+}
+
+func (app *application) apiUpdateRecipe(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	id, ok := app.apiUUIDPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	form, ok := app.validRecipeInput(w, r, userID, "You may not move recipes to this household.")
+	if !ok {
+		return
+	}
+
+	if err := app.recipeModel.Update(r.Context(), userID, form.recipe(id)); err != nil {
+		app.apiModelError(w, r, err)
+		return
+	}
+
+	app.sendRecipeEvents(r, userID, models.EventRecipeUpdated, id)
+	app.writeRecipe(w, r, http.StatusOK, userID, id)
+}
+
+func (app *application) apiDeleteRecipe(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	id, ok := app.apiUUIDPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	recipe, err := app.recipeModel.GetByID(r.Context(), userID, id)
+	if err != nil {
+		app.apiModelError(w, r, err)
+		return
+	}
+
+	// As in the web interface, deleting a recipe other recipes use must be confirmed.
+	if r.URL.Query().Get("confirm") != "true" {
+		referencedBy, err := app.recipeModel.ReferencedBy(r.Context(), userID, recipe)
+		if err != nil {
+			app.apiServerError(w, r, err)
+			return
+		}
+
+		if len(referencedBy) > 0 {
+			titles := make([]string, 0, len(referencedBy))
+			for _, other := range referencedBy {
+				titles = append(titles, other.Title)
+			}
+
+			app.apiErrorResponse(w, r, http.StatusConflict, fmt.Sprintf(
+				"The recipe is used as an ingredient by %s. Send confirm=true to delete it anyway.",
+				strings.Join(titles, ", "),
+			))
+			return
+		}
+	}
+
+	if err := app.recipeModel.Delete(r.Context(), userID, id); err != nil {
+		app.apiModelError(w, r, err)
+		return
+	}
+
+	app.sendRecipesDeleted(r, recipe)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeRecipe responds with the saved state of a recipe that was just created or updated.
+func (app *application) writeRecipe(w http.ResponseWriter, r *http.Request, status int, userID string, id uuid.UUID) {
+	recipe, err := app.recipeModel.GetByID(r.Context(), userID, id)
+	if err != nil {
+		app.apiServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/recipes/"+id.String())
+	app.writeJSON(w, r, status, newAPIRecipe(recipe))
+}
+
+// apiCategory is the representation of a category in the API.
+type apiCategory struct {
+	ID            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	HouseholdID   *uuid.UUID `json:"household_id"`
+	HouseholdName *string    `json:"household_name"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func newAPICategory(category models.Category) apiCategory {
+	converted := apiCategory{
+		ID:          category.ID,
+		Name:        category.Name,
+		HouseholdID: category.Household,
+		CreatedAt:   category.CreatedAt,
+		UpdatedAt:   category.UpdatedAt,
+	}
+
+	if category.HouseholdName.Valid {
+		converted.HouseholdName = &category.HouseholdName.String
+	}
+
+	return converted
+}
+
+// apiCategoryInput is the body of a request to create a category.
+type apiCategoryInput struct {
+	Name        string     `json:"name"`
+	HouseholdID *uuid.UUID `json:"household_id"`
+}
+
+// apiCategoryUpdate is the body of a request to update a category. A category's household may not
+// be changed.
+type apiCategoryUpdate struct {
+	Name string `json:"name"`
+}
+
+// categoryAPIFields maps the names of category form fields to the names of the equivalent API
+// fields.
+var categoryAPIFields = map[string]string{
+	"household": "household_id",
+}
+
+func (app *application) apiListCategories(w http.ResponseWriter, r *http.Request) {
+	page, ok := app.apiPage(w, r)
+	if !ok {
+		return
+	}
+
+	categories, err := app.categoryModel.ListPage(r.Context(), reqUser(r), page.PerPage+1, page.offset())
+	if err != nil {
+		app.apiServerError(w, r, err)
+		return
+	}
+
+	data := make([]apiCategory, 0, len(categories))
+	for _, category := range categories {
+		data = append(data, newAPICategory(category))
+	}
+
+	app.writeJSON(w, r, http.StatusOK, newAPIList(r, page, data))
+}
+
+func (app *application) apiGetCategory(w http.ResponseWriter, r *http.Request) {
+	id, ok := app.apiUUIDPathValue(w, r, "categoryID")
+	if !ok {
+		return
+	}
+
+	category, err := app.categoryModel.Get(r.Context(), reqUser(r), id)
+	if err != nil {
+		app.apiModelError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, r, http.StatusOK, newAPICategory(category))
+}
+
+func (app *application) apiCreateCategory(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	var input apiCategoryInput
+	if !app.readJSON(w, r, &input) {
+		return
+	}
+
+	form := categoryForm{Household: optionalUUIDString(input.HouseholdID), Name: input.Name}
+	form.Validate()
+
+	households, err := app.editableHouseholds(r, userID)
+	if err != nil {
+		app.apiServerError(w, r, err)
+		return
+	}
+
+	form.CheckField(
+		canAddToHousehold(households, form.Household),
+		"household",
+		"You may not add categories to this household.",
+	)
+
+	if !form.IsValid() {
+		app.apiValidationError(w, r, form.FieldErrors, categoryAPIFields)
+		return
+	}
+
+	category := models.Category{
+		ID:        uuid.New(),
+		Owner:     userID,
+		Household: input.HouseholdID,
+		Name:      form.Name,
+	}
+	if err := app.categoryModel.Create(r.Context(), category); err != nil {
+		app.apiModelError(w, r, err)
+		return
+	}
+
+	app.sendCategoryEvent(r, userID, models.EventCategoryCreated, category.ID)
+	app.writeCategory(w, r, http.StatusCreated, userID, category.ID)
+}
+
+func (app *application) apiUpdateCategory(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	id, ok := app.apiUUIDPathValue(w, r, "categoryID")
+	if !ok {
+		return
+	}
+
+	var input apiCategoryUpdate
+	if !app.readJSON(w, r, &input) {
+		return
+	}
+
+	form := categoryForm{Name: input.Name}
+	form.Validate()
+
+	if !form.IsValid() {
+		app.apiValidationError(w, r, form.FieldErrors, categoryAPIFields)
+		return
+	}
+
+	if err := app.categoryModel.Update(r.Context(), userID, models.Category{ID: id, Name: form.Name}); err != nil {
+		app.apiModelError(w, r, err)
+		return
+	}
+
+	app.sendCategoryEvent(r, userID, models.EventCategoryUpdated, id)
+	app.writeCategory(w, r, http.StatusOK, userID, id)
+}
+
+func (app *application) apiDeleteCategory(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	id, ok := app.apiUUIDPathValue(w, r, "categoryID")
+	if !ok {
+		return
+	}
+
+	category, err := app.categoryModel.Get(r.Context(), userID, id)
+	if err != nil {
+		app.apiModelError(w, r, err)
+		return
+	}
+
+	if err := app.categoryModel.Delete(r.Context(), userID, id); err != nil {
+		app.apiModelError(w, r, err)
+		return
+	}
+
+	app.sendCategoryDeleted(r, category)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeCategory responds with the saved state of a category that was just created or updated.
+func (app *application) writeCategory(w http.ResponseWriter, r *http.Request, status int, userID string, id uuid.UUID) {
+	category, err := app.categoryModel.Get(r.Context(), userID, id)
+	if err != nil {
+		app.apiServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/categories/"+id.String())
+	app.writeJSON(w, r, status, newAPICategory(category))
+}
+
+// apiDocument serves the OpenAPI description of the API.
+func (app *application) apiDocument(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(recipes.OpenAPIDocument)
+}
@@ -0,0 +1,550 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cdriehuys/recipes"
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+// decodeJSON decodes a response body, failing the test if it is not valid JSON.
+func decodeJSON[T any](t *testing.T, body string) T {
+	var value T
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		t.Fatalf("Expected JSON body; got %q: %v", body, err)
+	}
+
+	return value
+}
+
+// newAPITestServer returns an authenticated test server and a CSRF token for making requests to
+// it.
+func newAPITestServer(t *testing.T, app *application) (*testServer, string) {
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/recipes")
+
+	return server, extractCSRFToken(t, page)
+}
+
+func Test_application_api_unauthenticated(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	for _, path := range []string{"/api/v1/recipes", "/api/v1/categories"} {
+		t.Run(path, func(t *testing.T) {
+			status, headers, body := server.get(t, path)
+
+			assert.Equal(t, http.StatusUnauthorized, status)
+			assert.Equal(t, "application/json", headers.Get("Content-Type"))
+
+			response := decodeJSON[apiError](t, body)
+			assert.Equal(t, http.StatusUnauthorized, response.Error.Status)
+			assert.Equal(t, "Unauthorized", response.Error.Message)
+		})
+	}
+}
+
+func Test_application_apiListRecipes(t *testing.T) {
+	app := newTestApp(t)
+	server, _ := newAPITestServer(t, app)
+
+	testCases := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "defaults", query: "", wantStatus: http.StatusOK},
+		{name: "page size", query: "?page=2&per_page=10", wantStatus: http.StatusOK},
+		{name: "invalid page", query: "?page=0", wantStatus: http.StatusBadRequest},
+		{name: "page size too large", query: "?per_page=51", wantStatus: http.StatusBadRequest},
+		{name: "non-numeric page size", query: "?per_page=all", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, body := server.get(t, "/api/v1/recipes"+tt.query)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantStatus != http.StatusOK {
+				response := decodeJSON[apiError](t, body)
+				assert.Equal(t, tt.wantStatus, response.Error.Status)
+				return
+			}
+
+			response := decodeJSON[apiList[apiRecipe]](t, body)
+			assert.Equal(t, 1, len(response.Data))
+			assert.Equal(t, mock.Recipe.Title, response.Data[0].Title)
+			assert.Equal(t, (*string)(nil), response.Pagination.Next)
+
+			opts := app.recipeModel.(*mock.RecipeModel).LastListOptions
+			assert.Equal(t, response.Pagination.PerPage+1, opts.Limit)
+			assert.Equal(t, (response.Pagination.Page-1)*response.Pagination.PerPage, opts.Offset)
+		})
+	}
+}
+
+func Test_application_apiGetRecipe(t *testing.T) {
+	app := newTestApp(t)
+	server, _ := newAPITestServer(t, app)
+
+	t.Run("valid", func(t *testing.T) {
+		id := uuid.New()
+
+		status, _, body := server.get(t, "/api/v1/recipes/"+id.String())
+
+		assert.Equal(t, http.StatusOK, status)
+
+		recipe := decodeJSON[apiRecipe](t, body)
+		assert.Equal(t, id, recipe.ID)
+		assert.Equal(t, mock.Recipe.Title, recipe.Title)
+		assert.Equal(t, mock.Recipe.TotalTime.Minutes(), *recipe.TotalMinutes)
+		assert.Equal(t, mock.Recipe.SourceURL, recipe.SourceURL)
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		status, _, body := server.get(t, "/api/v1/recipes/foo")
+
+		assert.Equal(t, http.StatusNotFound, status)
+		assert.Equal(t, http.StatusNotFound, decodeJSON[apiError](t, body).Error.Status)
+	})
+}
+
+func Test_application_apiCreateRecipe(t *testing.T) {
+	app := newTestApp(t)
+	server, csrfToken := newAPITestServer(t, app)
+
+	testCases := []struct {
+		name       string
+		body       string
+		csrfToken  string
+		wantStatus int
+		wantFields []string
+	}{
+		{
+			name: "valid",
+			body: `{
+				"title": "Pancakes",
+				"ingredients": "1 cup flour",
+				"instructions": "Mix and fry.",
+				"servings": 4,
+				"prep_minutes": 10,
+				"cook_minutes": 15,
+				"source_name": "Grandma"
+			}`,
+			csrfToken:  csrfToken,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "invalid fields",
+			body:       `{"title": " ", "instructions": "Mix.", "prep_minutes": -1, "source_url": "ftp://example.com"}`,
+			csrfToken:  csrfToken,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: []string{"title", "prep_minutes", "source_url"},
+		},
+		{
+			name:       "unknown household",
+			body:       `{"title": "Pancakes", "instructions": "Mix.", "household_id": "` + uuid.NewString() + `"}`,
+			csrfToken:  csrfToken,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: []string{"household_id"},
+		},
+		{
+			name:       "unknown field",
+			body:       `{"title": "Pancakes", "instructions": "Mix.", "rating": 5}`,
+			csrfToken:  csrfToken,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "malformed JSON",
+			body:       `{"title": `,
+			csrfToken:  csrfToken,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "wrong type",
+			body:       `{"title": "Pancakes", "instructions": "Mix.", "servings": "four"}`,
+			csrfToken:  csrfToken,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing CSRF token",
+			body:       `{"title": "Pancakes", "instructions": "Mix."}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			status, headers, body := server.sendJSON(t, http.MethodPost, "/api/v1/recipes", tt.csrfToken, tt.body)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantStatus != http.StatusCreated {
+				if tt.csrfToken == "" {
+					return
+				}
+
+				response := decodeJSON[apiError](t, body)
+				assert.Equal(t, tt.wantStatus, response.Error.Status)
+				assert.Equal(t, len(tt.wantFields), len(response.Error.Fields))
+				for _, field := range tt.wantFields {
+					if response.Error.Fields[field] == "" {
+						t.Errorf("Expected error for field %q; got %v", field, response.Error.Fields)
+					}
+				}
+				return
+			}
+
+			created := app.recipeModel.(*mock.RecipeModel).LastCreatedRecipe
+			assert.Equal(t, "Pancakes", created.Title)
+			assert.Equal(t, mock.TestUserNormal, created.Owner)
+			assert.Equal(t, int16(4), created.Servings.Int16)
+			assert.Equal(t, 25, created.TotalTime.Minutes())
+			assert.Equal(t, "Grandma", created.SourceName)
+			assertRedirects(t, headers, "/api/v1/recipes/"+created.ID.String())
+			assert.Equal(t, created.ID, decodeJSON[apiRecipe](t, body).ID)
+		})
+	}
+}
+
+func Test_application_apiUpdateRecipe(t *testing.T) {
+	app := newTestApp(t)
+	server, csrfToken := newAPITestServer(t, app)
+
+	id := uuid.NewString()
+
+	testCases := []struct {
+		name       string
+		path       string
+		updatedAt  time.Time
+		wantStatus int
+	}{
+		{name: "without version", path: "/api/v1/recipes/" + id, wantStatus: http.StatusOK},
+		{name: "current version", path: "/api/v1/recipes/" + id, updatedAt: mock.Recipe.UpdatedAt, wantStatus: http.StatusOK},
+		{
+			name:       "stale version",
+			path:       "/api/v1/recipes/" + id,
+			updatedAt:  mock.Recipe.UpdatedAt.Add(-time.Hour),
+			wantStatus: http.StatusConflict,
+		},
+		{name: "invalid ID", path: "/api/v1/recipes/foo", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recipeModel := app.recipeModel.(*mock.RecipeModel)
+			recipeModel.LastUpdatedRecipe.Title = ""
+
+			input := map[string]any{"title": "Waffles", "instructions": "Mix and press."}
+			if !tt.updatedAt.IsZero() {
+				input["updated_at"] = tt.updatedAt
+			}
+
+			body, err := json.Marshal(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			status, _, respBody := server.sendJSON(t, http.MethodPut, tt.path, csrfToken, string(body))
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantStatus != http.StatusOK {
+				assert.Equal(t, tt.wantStatus, decodeJSON[apiError](t, respBody).Error.Status)
+				assert.Equal(t, "", recipeModel.LastUpdatedRecipe.Title)
+				return
+			}
+
+			assert.Equal(t, "Waffles", recipeModel.LastUpdatedRecipe.Title)
+			assert.Equal(t, id, recipeModel.LastUpdatedRecipe.ID.String())
+		})
+	}
+}
+
+func Test_application_apiDeleteRecipe(t *testing.T) {
+	app := newTestApp(t)
+	server, csrfToken := newAPITestServer(t, app)
+
+	status, _, body := server.sendJSON(t, http.MethodDelete, "/api/v1/recipes/"+uuid.NewString(), csrfToken, "")
+
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, "", body)
+
+	t.Run("used by other recipes", func(t *testing.T) {
+		path := "/api/v1/recipes/" + mock.SubRecipe.ID.String()
+
+		status, _, body := server.sendJSON(t, http.MethodDelete, path, csrfToken, "")
+
+		assert.Equal(t, http.StatusConflict, status)
+		response := decodeJSON[apiError](t, body)
+		assert.StringContains(t, response.Error.Message, mock.ParentRecipe.Title)
+
+		status, _, _ = server.sendJSON(t, http.MethodDelete, path+"?confirm=true", csrfToken, "")
+
+		assert.Equal(t, http.StatusNoContent, status)
+	})
+}
+
+func Test_application_apiListCategories(t *testing.T) {
+	app := newTestApp(t)
+	server, _ := newAPITestServer(t, app)
+
+	status, _, body := server.get(t, "/api/v1/categories?per_page=1")
+
+	assert.Equal(t, http.StatusOK, status)
+
+	first := decodeJSON[apiList[apiCategory]](t, body)
+	assert.Equal(t, 1, len(first.Data))
+	assert.Equal(t, mock.ListedCategories[0].Name, first.Data[0].Name)
+	if first.Pagination.Next == nil {
+		t.Fatal("Expected a next page")
+	}
+	assert.Equal(t, "/api/v1/categories?page=2&per_page=1", *first.Pagination.Next)
+
+	_, _, body = server.get(t, *first.Pagination.Next)
+
+	second := decodeJSON[apiList[apiCategory]](t, body)
+	assert.Equal(t, 1, len(second.Data))
+	assert.Equal(t, mock.ListedCategories[1].Name, second.Data[0].Name)
+	assert.Equal(t, (*string)(nil), second.Pagination.Next)
+}
+
+func Test_application_apiCategories(t *testing.T) {
+	app := newTestApp(t)
+	server, csrfToken := newAPITestServer(t, app)
+
+	category := mock.ListedCategories[0]
+	categoryPath := "/api/v1/categories/" + category.ID.String()
+	unknownPath := "/api/v1/categories/" + uuid.NewString()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantName   string
+	}{
+		{name: "get", method: http.MethodGet, path: categoryPath, wantStatus: http.StatusOK, wantName: category.Name},
+		{name: "get unknown", method: http.MethodGet, path: unknownPath, wantStatus: http.StatusNotFound},
+		{
+			name:       "create",
+			method:     http.MethodPost,
+			path:       "/api/v1/categories",
+			body:       `{"name": "Desserts"}`,
+			wantStatus: http.StatusCreated,
+			wantName:   "Desserts",
+		},
+		{
+			name:       "create duplicate",
+			method:     http.MethodPost,
+			path:       "/api/v1/categories",
+			body:       `{"name": "` + category.Name + `"}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "create blank",
+			method:     http.MethodPost,
+			path:       "/api/v1/categories",
+			body:       `{"name": ""}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "update",
+			method:     http.MethodPut,
+			path:       categoryPath,
+			body:       `{"name": "Mains"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "update duplicate",
+			method:     http.MethodPut,
+			path:       categoryPath,
+			body:       `{"name": "` + mock.ListedCategories[1].Name + `"}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "update household",
+			method:     http.MethodPut,
+			path:       categoryPath,
+			body:       `{"name": "Mains", "household_id": null}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{name: "update unknown", method: http.MethodPut, path: unknownPath, body: `{"name": "Mains"}`, wantStatus: http.StatusNotFound},
+		{name: "delete", method: http.MethodDelete, path: categoryPath, wantStatus: http.StatusNoContent},
+		{name: "delete unknown", method: http.MethodDelete, path: unknownPath, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, body := server.sendJSON(t, tt.method, tt.path, csrfToken, tt.body)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			switch {
+			case tt.wantName != "":
+				assert.Equal(t, tt.wantName, decodeJSON[apiCategory](t, body).Name)
+			case tt.wantStatus >= 400:
+				assert.Equal(t, tt.wantStatus, decodeJSON[apiError](t, body).Error.Status)
+			}
+		})
+	}
+
+	assert.Equal(t, "Mains", app.categoryModel.(*mock.CategoryModel).LastUpdatedCategory.Name)
+}
+
+func Test_application_apiDocument(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	status, headers, body := server.get(t, "/api/openapi.json")
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "application/json", headers.Get("Content-Type"))
+
+	document := decodeJSON[map[string]any](t, body)
+	version, _ := document["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		t.Errorf("Expected OpenAPI 3 document; got version %q", version)
+	}
+
+	// Every reference must point to a component defined in the document.
+	var checkRefs func(value any)
+	checkRefs = func(value any) {
+		switch value := value.(type) {
+		case map[string]any:
+			for key, child := range value {
+				if ref, ok := child.(string); key == "$ref" && ok {
+					if _, found := lookupJSONPointer(document, ref); !found {
+						t.Errorf("Reference %q does not resolve", ref)
+					}
+				}
+
+				checkRefs(child)
+			}
+		case []any:
+			for _, child := range value {
+				checkRefs(child)
+			}
+		}
+	}
+	checkRefs(document)
+}
+
+// Test_apiDocument_matchesRoutes fails if the API routes registered in `routes()` and the
+// operations described by the OpenAPI document differ.
+func Test_apiDocument_matchesRoutes(t *testing.T) {
+	routes := apiRoutePatterns(t)
+
+	var document struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(recipes.OpenAPIDocument, &document); err != nil {
+		t.Fatalf("Failed to parse OpenAPI document: %v", err)
+	}
+
+	var documented []string
+	for path, item := range document.Paths {
+		for method := range item {
+			switch method {
+			case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+				documented = append(documented, strings.ToUpper(method)+" "+path)
+			}
+		}
+	}
+
+	for _, route := range routes {
+		if !slices.Contains(documented, route) {
+			t.Errorf("Route %q is not described by the OpenAPI document", route)
+		}
+	}
+
+	for _, operation := range documented {
+		if !slices.Contains(routes, operation) {
+			t.Errorf("Operation %q in the OpenAPI document has no route", operation)
+		}
+	}
+}
+
+// apiRoutePatterns returns the patterns of the API routes registered in routes.go, excluding the
+// route serving the OpenAPI document itself.
+func apiRoutePatterns(t *testing.T) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "routes.go", nil, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse routes: %v", err)
+	}
+
+	var patterns []string
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || selector.Sel.Name != "Handle" {
+			return true
+		}
+
+		literal, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || literal.Kind != token.STRING {
+			return true
+		}
+
+		pattern, err := strconv.Unquote(literal.Value)
+		if err != nil {
+			t.Fatalf("Failed to read route pattern %s: %v", literal.Value, err)
+		}
+
+		_, path, _ := strings.Cut(pattern, " ")
+		if strings.HasPrefix(path, "/api/") && path != "/api/openapi.json" {
+			patterns = append(patterns, pattern)
+		}
+
+		return true
+	})
+
+	if len(patterns) == 0 {
+		t.Fatal("Expected to find API routes in routes.go")
+	}
+
+	return patterns
+}
+
+// lookupJSONPointer returns the value referenced by a local JSON pointer such as
+// "#/components/schemas/Recipe".
+func lookupJSONPointer(document map[string]any, pointer string) (any, bool) {
+	rest, ok := strings.CutPrefix(pointer, "#/")
+	if !ok {
+		return nil, false
+	}
+
+	var value any = document
+	for _, key := range strings.Split(rest, "/") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		key = strings.ReplaceAll(strings.ReplaceAll(key, "~1", "/"), "~0", "~")
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+)
+
+// maxAPIBodyBytes limits the size of request bodies accepted by the API.
+const maxAPIBodyBytes = 1024 * 1024
+
+// defaultAPIPageSize and maxAPIPageSize are the default and largest number of objects returned in
+// one page of an API list.
+const (
+	defaultAPIPageSize = 25
+	maxAPIPageSize     = 50
+)
+
+// apiError is the body of every unsuccessful API response.
+type apiError struct {
+	Error apiErrorDetail `json:"error"`
+}
+
+type apiErrorDetail struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	// Fields holds the validation errors of individual fields, keyed by field name.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// apiList is the body of a successful API response listing objects.
+type apiList[T any] struct {
+	Data       []T           `json:"data"`
+	Pagination apiPagination `json:"pagination"`
+}
+
+// apiRecipeList is the JSON form of the recipe list page. Unlike the API's list endpoint, every
+// matching recipe is included.
+type apiRecipeList struct {
+	Data []apiRecipe `json:"data"`
+}
+
+type apiPagination struct {
+	Page    int `json:"page"`
+	PerPage int `json:"per_page"`
+	// Next is the URL of the next page, or nil if this is the last page.
+	Next *string `json:"next"`
+}
+
+// writeJSON writes a value as the JSON body of the response.
+func (app *application) writeJSON(w http.ResponseWriter, r *http.Request, status int, value any) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		app.apiServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// apiErrorResponse sends an error response with the given status code and message.
+func (app *application) apiErrorResponse(w http.ResponseWriter, r *http.Request, status int, message string) {
+	app.writeJSON(w, r, status, apiError{Error: apiErrorDetail{Status: status, Message: message}})
+}
+
+// apiClientError sends an error response with the standard message for the given status code.
+func (app *application) apiClientError(w http.ResponseWriter, r *http.Request, status int) {
+	app.apiErrorResponse(w, r, status, http.StatusText(status))
+}
+
+// apiServerError logs an error-level message including the details of the request that caused the
+// error, and sends a generic 500 response to the client.
+func (app *application) apiServerError(w http.ResponseWriter, r *http.Request, err error) {
+	app.logger.ErrorContext(r.Context(), err.Error(), "method", r.Method, "uri", r.URL.RequestURI())
+
+	status := http.StatusInternalServerError
+	body, _ := json.Marshal(apiError{Error: apiErrorDetail{Status: status, Message: http.StatusText(status)}})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// apiModelError sends the response appropriate for an error returned from a model.
+func (app *application) apiModelError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		app.apiClientError(w, r, http.StatusNotFound)
+	case errors.Is(err, models.ErrConflict):
+		app.apiErrorResponse(w, r, http.StatusConflict, "The object has been modified since the provided version.")
+	case errors.Is(err, models.ErrDuplicate):
+		app.apiErrorResponse(w, r, http.StatusConflict, "An object with the same name already exists.")
+	default:
+		app.apiServerError(w, r, err)
+	}
+}
+
+// apiValidationError sends the field errors of a form. Field names are translated to their API
+// names using the provided map, and names without a translation are used as-is.
+func (app *application) apiValidationError(w http.ResponseWriter, r *http.Request, fieldErrors map[string]string, names map[string]string) {
+	fields := make(map[string]string, len(fieldErrors))
+	for field, message := range fieldErrors {
+		if name, ok := names[field]; ok {
+			field = name
+		}
+
+		fields[field] = message
+	}
+
+	status := http.StatusUnprocessableEntity
+	app.writeJSON(w, r, status, apiError{
+		Error: apiErrorDetail{Status: status, Message: "The request contains invalid fields.", Fields: fields},
+	})
+}
+
+// readJSON decodes the JSON request body into dst. Unknown fields and trailing data are rejected.
+// If the body cannot be decoded, a 400 response describing the problem is sent and false is
+// returned.
+func (app *application) readJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(dst)
+	if err == nil && decoder.Decode(&struct{}{}) != io.EOF {
+		err = errors.New("body must contain a single JSON object")
+	}
+
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			app.apiClientError(w, r, http.StatusRequestEntityTooLarge)
+		} else {
+			app.apiErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid JSON body: %v", err))
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// apiUUIDPathValue parses the named path value of the request as a UUID. If the value is not a
+// valid UUID, a 404 response is sent and the returned boolean is false.
+func (app *application) apiUUIDPathValue(w http.ResponseWriter, r *http.Request, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue(name))
+	if err != nil {
+		app.apiClientError(w, r, http.StatusNotFound)
+		return uuid.UUID{}, false
+	}
+
+	return id, true
+}
+
+// apiPage parses the "page" and "per_page" query parameters of a list request. If either is
+// invalid, a 400 response is sent and the returned boolean is false.
+func (app *application) apiPage(w http.ResponseWriter, r *http.Request) (apiPagination, bool) {
+	page := apiPagination{Page: 1, PerPage: defaultAPIPageSize}
+
+	query := r.URL.Query()
+	if raw := query.Get("page"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			app.apiErrorResponse(w, r, http.StatusBadRequest, "The page parameter must be a whole number of at least 1.")
+			return apiPagination{}, false
+		}
+
+		page.Page = n
+	}
+
+	if raw := query.Get("per_page"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxAPIPageSize {
+			app.apiErrorResponse(
+				w,
+				r,
+				http.StatusBadRequest,
+				fmt.Sprintf("The per_page parameter must be a whole number from 1 to %d.", maxAPIPageSize),
+			)
+			return apiPagination{}, false
+		}
+
+		page.PerPage = n
+	}
+
+	return page, true
+}
+
+// offset returns the number of objects before the page.
+func (p apiPagination) offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// newAPIList builds a page of a list from up to one more object than fits on the page. The extra
+// object indicates that there is a next page, whose URL is built from the request's URL.
+func newAPIList[T any](r *http.Request, page apiPagination, data []T) apiList[T] {
+	if len(data) > page.PerPage {
+		data = data[:page.PerPage]
+
+		query := r.URL.Query()
+		query.Set("page", strconv.Itoa(page.Page+1))
+		query.Set("per_page", strconv.Itoa(page.PerPage))
+
+		next := (&url.URL{Path: r.URL.Path, RawQuery: query.Encode()}).String()
+		page.Next = &next
+	}
+
+	if data == nil {
+		data = []T{}
+	}
+
+	return apiList[T]{Data: data, Pagination: page}
+}
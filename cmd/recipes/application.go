@@ -7,16 +7,21 @@ import (
 	"io/fs"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/alexedwards/scs/pgxstore"
 	"github.com/alexedwards/scs/v2"
 	"github.com/cdriehuys/recipes/internal/config"
+	"github.com/cdriehuys/recipes/internal/dietary"
 	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/ratelimit"
 	"github.com/cdriehuys/recipes/internal/staticfiles"
 	"github.com/cdriehuys/recipes/internal/templates"
 	"github.com/cdriehuys/recipes/internal/tracing"
+	"github.com/cdriehuys/recipes/internal/webhooks"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/tern/v2/migrate"
 	"golang.org/x/oauth2"
@@ -29,25 +34,153 @@ type oauthConfig interface {
 	Exchange(context.Context, string, ...oauth2.AuthCodeOption) (*oauth2.Token, error)
 }
 
+type aisleModel interface {
+	Assign(context.Context, string, string, *uuid.UUID) error
+	Assignments(context.Context, string) (map[string]uuid.UUID, error)
+	Create(context.Context, models.StoreAisle) error
+	Delete(context.Context, string, uuid.UUID) error
+	List(context.Context, string) ([]models.StoreAisle, error)
+	Move(context.Context, string, uuid.UUID, int) error
+}
+
 type categoryModel interface {
 	Create(context.Context, models.Category) error
+	Delete(context.Context, string, uuid.UUID) error
+	Get(context.Context, string, uuid.UUID) (models.Category, error)
 	List(context.Context, string) ([]models.Category, error)
+	ListPage(context.Context, string, int, int) ([]models.Category, error)
+	Update(context.Context, string, models.Category) error
+}
+
+type collectionModel interface {
+	AddRecipe(context.Context, string, uuid.UUID, uuid.UUID) error
+	Create(context.Context, models.Collection) error
+	Delete(context.Context, string, uuid.UUID) error
+	Get(context.Context, string, uuid.UUID) (models.Collection, error)
+	GetShared(context.Context, string) (models.Collection, error)
+	List(context.Context, string) ([]models.Collection, error)
+	ListForRecipe(context.Context, string, uuid.UUID) ([]models.Collection, error)
+	Recipes(context.Context, string, uuid.UUID) ([]models.Recipe, error)
+	RemoveRecipe(context.Context, string, uuid.UUID, uuid.UUID) error
+	Reorder(context.Context, string, uuid.UUID, []uuid.UUID) error
+	Share(context.Context, string, uuid.UUID, string) error
+	Unshare(context.Context, string, uuid.UUID) error
+	Update(context.Context, string, models.Collection) error
+}
+
+type cookLogModel interface {
+	Add(context.Context, models.CookLogEntry) error
+	Delete(context.Context, string, uuid.UUID) error
+	ListForRecipe(context.Context, string, uuid.UUID) ([]models.CookLogEntry, error)
+}
+
+type draftModel interface {
+	Delete(context.Context, string, uuid.UUID) error
+	Get(context.Context, string, uuid.UUID) (models.RecipeDraft, error)
+	List(context.Context, string) ([]models.RecipeDraft, error)
+	Save(context.Context, models.RecipeDraft) error
+}
+
+type householdModel interface {
+	AcceptInvitation(context.Context, string, string) (uuid.UUID, error)
+	Create(context.Context, string, models.Household) error
+	CreateInvitation(context.Context, string, models.HouseholdInvitation) error
+	Get(context.Context, string, uuid.UUID) (models.Household, error)
+	GetInvitation(context.Context, string) (models.HouseholdInvitation, error)
+	ListForUser(context.Context, string) ([]models.Household, error)
+	ListInvitations(context.Context, string, uuid.UUID) ([]models.HouseholdInvitation, error)
+	ListMembers(context.Context, string, uuid.UUID) ([]models.HouseholdMember, error)
+	RemoveMember(context.Context, string, uuid.UUID, string) error
+	SetMemberRole(context.Context, string, uuid.UUID, string, models.HouseholdRole) error
+}
+
+type labelModel interface {
+	SetOverrides(context.Context, string, uuid.UUID, dietary.Overrides) error
+}
+
+type mealPlanModel interface {
+	Add(context.Context, models.MealPlanItem) error
+	CopyWeek(context.Context, string, time.Time, time.Time) (int64, error)
+	Delete(context.Context, string, uuid.UUID) error
+	ListRange(context.Context, string, time.Time, time.Time) ([]models.MealPlanItem, error)
+}
+
+type ratingModel interface {
+	Rate(context.Context, string, uuid.UUID, pgtype.Int2, pgtype.Bool) error
+	SetFavorite(context.Context, string, uuid.UUID, bool) error
+}
+
+type nutritionModel interface {
+	Matches(context.Context, string, uuid.UUID) (map[string]string, error)
+	SetMatches(context.Context, string, uuid.UUID, map[string]string) error
+}
+
+type pantryModel interface {
+	Add(context.Context, models.PantryItem) error
+	Delete(context.Context, string, uuid.UUID) error
+	List(context.Context, string) ([]models.PantryItem, error)
 }
 
 type recipeModel interface {
 	Add(context.Context, models.Recipe) error
+	BulkDelete(context.Context, string, []uuid.UUID) error
+	BulkMove(context.Context, string, []uuid.UUID, *uuid.UUID) error
+	BulkTag(context.Context, string, []uuid.UUID, string) error
+	BulkUntag(context.Context, string, []uuid.UUID, string) error
+	Components(context.Context, string, uuid.UUID) (map[string]models.RecipeComponent, error)
 	Delete(context.Context, string, uuid.UUID) error
+	Duplicate(context.Context, string, uuid.UUID) (uuid.UUID, error)
+	FindByTitles(context.Context, string, []string) ([]models.RecipeComponent, error)
 	GetByID(context.Context, string, uuid.UUID) (models.Recipe, error)
-	List(context.Context, string) ([]models.Recipe, error)
-	Update(context.Context, models.Recipe) error
+	List(context.Context, string, models.RecipeListOptions) ([]models.Recipe, error)
+	ListByIDs(context.Context, string, []uuid.UUID) ([]models.Recipe, error)
+	ReferencedBy(context.Context, string, models.Recipe) ([]models.RecipeComponent, error)
+	SetComponents(context.Context, string, uuid.UUID, map[string]uuid.UUID) error
+	Update(context.Context, string, models.Recipe) error
+}
+
+type shareModel interface {
+	Create(context.Context, string, models.RecipeShare) error
+	GetRecipe(context.Context, string) (models.Recipe, error)
+	ListForRecipe(context.Context, string, uuid.UUID) ([]models.RecipeShare, error)
+	Revoke(context.Context, string, uuid.UUID, uuid.UUID) error
+}
+
+type shoppingListModel interface {
+	Add(context.Context, string, []models.ShoppingListItem) error
+	Delete(context.Context, string, uuid.UUID) error
+	DeleteChecked(context.Context, string) error
+	Get(context.Context, string, uuid.UUID) (models.ShoppingListItem, error)
+	List(context.Context, string) ([]models.ShoppingListItem, error)
+	SetChecked(context.Context, string, uuid.UUID, bool) error
+	Update(context.Context, string, models.ShoppingListItem) error
+}
+
+type tokenModel interface {
+	Authenticate(context.Context, string) (models.AccessToken, error)
+	Create(context.Context, models.AccessToken, string) error
+	List(context.Context, string) ([]models.AccessToken, error)
+	Revoke(context.Context, string, uuid.UUID) error
 }
 
 type userModel interface {
 	Exists(context.Context, string) (bool, error)
+	FeedEnabled(context.Context, string) (bool, error)
+	FeedOwner(context.Context, string) (string, error)
 	RecordLogIn(context.Context, string) (bool, error)
+	SetFeedToken(context.Context, string, string) error
 	UpdateName(context.Context, string, string) error
 }
 
+type webhookModel interface {
+	Create(context.Context, models.Webhook) error
+	Delete(context.Context, string, uuid.UUID) error
+	Deliveries(context.Context, string, uuid.UUID, int) ([]models.WebhookDelivery, error)
+	Enqueue(context.Context, string, *uuid.UUID, string, []byte) error
+	Get(context.Context, string, uuid.UUID) (models.Webhook, error)
+	List(context.Context, string) ([]models.Webhook, error)
+}
+
 type sessionManager interface {
 	GetString(context.Context, string) string
 	LoadAndSave(http.Handler) http.Handler
@@ -67,15 +200,42 @@ type staticServer interface {
 }
 
 type application struct {
-	logger         *slog.Logger
-	config         config.Config
-	oauthConfig    oauthConfig
-	categoryModel  categoryModel
-	recipeModel    recipeModel
-	userModel      userModel
-	templates      templateWriter
-	sessionManager sessionManager
-	staticServer   staticServer
+	logger            *slog.Logger
+	config            config.Config
+	oauthConfig       oauthConfig
+	aisleModel        aisleModel
+	categoryModel     categoryModel
+	collectionModel   collectionModel
+	cookLogModel      cookLogModel
+	draftModel        draftModel
+	householdModel    householdModel
+	labelModel        labelModel
+	mealPlanModel     mealPlanModel
+	nutritionModel    nutritionModel
+	pantryModel       pantryModel
+	ratingModel       ratingModel
+	recipeModel       recipeModel
+	shareModel        shareModel
+	shoppingListModel shoppingListModel
+	tokenModel        tokenModel
+	userModel         userModel
+	webhookModel      webhookModel
+	templates         templateWriter
+	sessionManager    sessionManager
+	staticServer      staticServer
+
+	// webhookDispatcher sends queued webhook deliveries in the background while the server runs.
+	webhookDispatcher *webhooks.Dispatcher
+
+	// Request rate limiters for the login routes, the API, and all other pages. A nil limiter
+	// allows every request.
+	authLimiter *ratelimit.Limiter
+	apiLimiter  *ratelimit.Limiter
+	pageLimiter *ratelimit.Limiter
+
+	// Limiters for failed attempts to use access tokens and feed tokens from each client address.
+	tokenLimiter *ratelimit.Limiter
+	feedLimiter  *ratelimit.Limiter
 }
 
 func newApplication(
@@ -122,9 +282,29 @@ func newApplication(
 	sessionManager := scs.New()
 	sessionManager.Store = pgxstore.New(dbpool)
 
+	aisleModel := models.AisleModel{DB: dbpool, Logger: logger}
 	categoryModel := models.CategoryModel{DB: dbpool, Logger: logger}
+	collectionModel := models.CollectionModel{DB: dbpool, Logger: logger}
+	cookLogModel := models.CookLogModel{DB: dbpool, Logger: logger}
+	draftModel := models.DraftModel{DB: dbpool, Logger: logger}
+	householdModel := models.HouseholdModel{DB: dbpool, Logger: logger}
+	labelModel := models.LabelModel{DB: dbpool, Logger: logger}
+	mealPlanModel := models.MealPlanModel{DB: dbpool, Logger: logger}
+	nutritionModel := models.NutritionModel{DB: dbpool, Logger: logger}
+	pantryModel := models.PantryModel{DB: dbpool, Logger: logger}
+	ratingModel := models.RatingModel{DB: dbpool, Logger: logger}
 	recipeModel := models.RecipeModel{DB: dbpool, Logger: logger}
+	shareModel := models.ShareModel{DB: dbpool, Logger: logger}
+	shoppingListModel := models.ShoppingListModel{DB: dbpool, Logger: logger}
+	tokenModel := models.TokenModel{DB: dbpool, Logger: logger}
 	userModel := models.UserModel{DB: dbpool, Logger: logger}
+	webhookModel := models.WebhookModel{DB: dbpool, Logger: logger}
+
+	webhookDispatcher := webhooks.Dispatcher{
+		Store:  &webhookModel,
+		Client: webhooks.NewClient(10*time.Second, config.AllowPrivateWebhooks),
+		Logger: logger,
+	}
 
 	var staticServer staticServer
 	if config.DevMode {
@@ -162,20 +342,44 @@ func newApplication(
 	}
 
 	app := &application{
-		logger:         logger,
-		config:         config,
-		oauthConfig:    &oauthConfig,
-		categoryModel:  &categoryModel,
-		recipeModel:    &recipeModel,
-		userModel:      &userModel,
-		templates:      templateEngine,
-		sessionManager: sessionManager,
-		staticServer:   staticServer,
+		logger:            logger,
+		config:            config,
+		oauthConfig:       &oauthConfig,
+		aisleModel:        &aisleModel,
+		categoryModel:     &categoryModel,
+		collectionModel:   &collectionModel,
+		cookLogModel:      &cookLogModel,
+		draftModel:        &draftModel,
+		householdModel:    &householdModel,
+		labelModel:        &labelModel,
+		mealPlanModel:     &mealPlanModel,
+		nutritionModel:    &nutritionModel,
+		pantryModel:       &pantryModel,
+		ratingModel:       &ratingModel,
+		recipeModel:       &recipeModel,
+		shareModel:        &shareModel,
+		shoppingListModel: &shoppingListModel,
+		tokenModel:        &tokenModel,
+		userModel:         &userModel,
+		webhookModel:      &webhookModel,
+		templates:         templateEngine,
+		sessionManager:    sessionManager,
+		staticServer:      staticServer,
+		webhookDispatcher: &webhookDispatcher,
+		authLimiter:       newRateLimiter(config.RateLimits.Auth),
+		apiLimiter:        newRateLimiter(config.RateLimits.API),
+		pageLimiter:       newRateLimiter(config.RateLimits.Pages),
+		tokenLimiter:      newRateLimiter(config.RateLimits.Tokens),
+		feedLimiter:       newRateLimiter(config.RateLimits.Feeds),
 	}
 
 	return app, nil
 }
 
+func newRateLimiter(limit config.RateLimit) *ratelimit.Limiter {
+	return &ratelimit.Limiter{Rate: limit.PerMinute / 60, Burst: limit.Burst}
+}
+
 func runMigrations(ctx context.Context, conn *pgx.Conn, migrations fs.FS) error {
 	migrator, err := migrate.NewMigrator(ctx, conn, "public.schema_version")
 	if err != nil {
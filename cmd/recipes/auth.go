@@ -1,6 +1,11 @@
 package main
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cdriehuys/recipes/internal/models"
+)
 
 const sessionKeyUserID = "authenticatedUserID"
 
@@ -26,6 +31,25 @@ func reqUser(r *http.Request) string {
 	return userID
 }
 
+// reqAccessToken returns the access token that authenticated the request. The returned boolean is
+// false if the request was authenticated by a session instead of an access token.
+func reqAccessToken(r *http.Request) (models.AccessToken, bool) {
+	token, ok := r.Context().Value(contextKeyAccessToken).(models.AccessToken)
+
+	return token, ok
+}
+
+// bearerToken returns the token from the request's "Authorization: Bearer" header. The returned
+// boolean is false if the request does not have such a header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
+
 // setAuthenticatedUser stores the provided user ID in the session associated with the request as
 // the currently authenticated user.
 func (app *application) setAuthenticatedUser(r *http.Request, id string) error {
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/validation"
+	"github.com/google/uuid"
+)
+
+// bulkAction is an action that may be applied to several recipes at once from the recipe list.
+type bulkAction string
+
+const (
+	bulkMove   bulkAction = "move"
+	bulkTag    bulkAction = "tag"
+	bulkUntag  bulkAction = "untag"
+	bulkExport bulkAction = "export"
+	bulkDelete bulkAction = "delete"
+)
+
+// bulkActions lists every bulk action in the order they are offered.
+var bulkActions = []bulkAction{bulkMove, bulkTag, bulkUntag, bulkExport, bulkDelete}
+
+// Label returns the name of the action for display.
+func (a bulkAction) Label() string {
+	switch a {
+	case bulkMove:
+		return "Move to category"
+	case bulkTag:
+		return "Add tag"
+	case bulkUntag:
+		return "Remove tag"
+	case bulkExport:
+		return "Export"
+	case bulkDelete:
+		return "Delete"
+	default:
+		return string(a)
+	}
+}
+
+type bulkRecipeForm struct {
+	Action   bulkAction
+	Category string
+	Tag      string
+	// Recipes are the IDs of the selected recipes.
+	Recipes []uuid.UUID
+	// Confirmed is true once the user has reviewed the action and the recipes it applies to.
+	Confirmed bool
+	validation.Validator
+}
+
+// newBulkRecipeForm populates a bulk action form from a submitted request. The returned boolean is
+// false if any of the selected recipe IDs are invalid.
+func newBulkRecipeForm(r *http.Request) (bulkRecipeForm, bool) {
+	form := bulkRecipeForm{
+		Action:    bulkAction(r.PostFormValue("action")),
+		Category:  r.PostFormValue("category"),
+		Tag:       models.NormalizeTag(r.PostFormValue("tag")),
+		Confirmed: r.PostFormValue("confirm") == "true",
+	}
+
+	for _, raw := range r.PostForm["recipe"] {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return bulkRecipeForm{}, false
+		}
+
+		form.Recipes = append(form.Recipes, id)
+	}
+
+	return form, true
+}
+
+// Validate checks the selected recipes and action. The action's options are only checked once the
+// action is confirmed, since they are chosen on the confirmation page.
+func (form *bulkRecipeForm) Validate() {
+	if len(form.Recipes) == 0 {
+		form.AddNonFieldError("Select at least one recipe.")
+	}
+
+	if len(form.Recipes) > models.MaxBulkRecipes {
+		form.AddNonFieldError(fmt.Sprintf("Select no more than %d recipes.", models.MaxBulkRecipes))
+	}
+
+	form.CheckField(validation.PermittedValue(form.Action, bulkActions...), "action", "Choose an action.")
+
+	if !form.Confirmed {
+		return
+	}
+
+	switch form.Action {
+	case bulkMove:
+		form.CheckField(validation.UUIDOrBlank(form.Category), "category", "Invalid category.")
+	case bulkTag, bulkUntag:
+		form.CheckField(validation.NotBlank(form.Tag), "tag", "This field is required.")
+		form.CheckField(validation.MaxLength(form.Tag, 50), "tag", "This field may not contain more than 50 characters.")
+	}
+}
+
+// bulkRecipesPost applies a bulk action to the recipes selected in the recipe list. The first
+// submission shows the selected recipes and the action to be applied to them, and the action is
+// only applied once it is submitted again with confirmation.
+func (app *application) bulkRecipesPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form, ok := newBulkRecipeForm(r)
+	if !ok {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.Validate()
+
+	var recipes []models.Recipe
+	if len(form.Recipes) > 0 && len(form.Recipes) <= models.MaxBulkRecipes {
+		var err error
+		recipes, err = app.recipeModel.ListByIDs(r.Context(), userID, form.Recipes)
+		if err != nil {
+			app.modelError(w, r, err)
+			return
+		}
+	}
+
+	if !form.IsValid() {
+		app.renderBulkRecipes(w, r, http.StatusUnprocessableEntity, userID, recipes, &form)
+		return
+	}
+
+	if !form.Confirmed {
+		app.renderBulkRecipes(w, r, http.StatusOK, userID, recipes, &form)
+		return
+	}
+
+	var err error
+	switch form.Action {
+	case bulkMove:
+		err = app.recipeModel.BulkMove(r.Context(), userID, form.Recipes, optionalUUID(form.Category))
+	case bulkTag:
+		err = app.recipeModel.BulkTag(r.Context(), userID, form.Recipes, form.Tag)
+	case bulkUntag:
+		err = app.recipeModel.BulkUntag(r.Context(), userID, form.Recipes, form.Tag)
+	case bulkDelete:
+		err = app.recipeModel.BulkDelete(r.Context(), userID, form.Recipes)
+	case bulkExport:
+		app.exportRecipes(w, r, recipes)
+		return
+	}
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	if form.Action == bulkDelete {
+		app.sendRecipesDeleted(r, recipes...)
+	} else {
+		app.sendRecipeEvents(r, userID, models.EventRecipeUpdated, form.Recipes...)
+	}
+
+	http.Redirect(w, r, "/recipes", http.StatusSeeOther)
+}
+
+func (app *application) renderBulkRecipes(w http.ResponseWriter, r *http.Request, status int, userID string, recipes []models.Recipe, form *bulkRecipeForm) {
+	categories, err := app.categoryModel.List(r.Context(), userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.BulkActions = bulkActions
+	data.Categories = categories
+	data.Form = form
+	data.Recipes = recipes
+
+	// As when deleting a single recipe, the user is warned about recipes that use the ones being
+	// deleted.
+	if form.Action == bulkDelete {
+		data.ReferencedBy, err = app.referencingRecipes(r.Context(), userID, recipes)
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	app.render(w, r, status, "bulk-recipes", data)
+}
+
+// exportRecipes writes the recipes as a downloadable JSON file.
+func (app *application) exportRecipes(w http.ResponseWriter, r *http.Request, recipes []models.Recipe) {
+	exported := make([]apiRecipe, 0, len(recipes))
+	for _, recipe := range recipes {
+		exported = append(exported, newAPIRecipe(recipe))
+	}
+
+	body, err := json.MarshalIndent(exported, "", "  ")
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="recipes.json"`)
+	w.Write(body)
+}
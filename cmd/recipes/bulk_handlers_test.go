@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_application_bulkRecipesPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/recipes")
+	csrfToken := extractCSRFToken(t, page)
+
+	categoryID := uuid.New()
+	recipes := []string{mock.SubRecipe.ID.String(), mock.ParentRecipe.ID.String()}
+
+	testCases := []struct {
+		name                  string
+		action                string
+		category              string
+		tag                   string
+		recipes               []string
+		confirm               bool
+		wantStatus            int
+		wantBody              []string
+		wantValidationMessage string
+		wantBulkAction        string
+	}{
+		{
+			name:       "confirmation",
+			action:     "delete",
+			recipes:    recipes,
+			wantStatus: http.StatusOK,
+			wantBody:   []string{mock.SubRecipe.Title, mock.ParentRecipe.Title, `name="confirm" value="true"`},
+		},
+		{
+			name:       "delete confirmation lists recipes using them",
+			action:     "delete",
+			recipes:    []string{mock.SubRecipe.ID.String()},
+			wantStatus: http.StatusOK,
+			wantBody:   []string{"These recipes use them as ingredients", `href="/recipes/` + mock.ParentRecipe.ID.String() + `"`},
+		},
+		{
+			name:       "tag confirmation does not require tag yet",
+			action:     "tag",
+			recipes:    recipes,
+			wantStatus: http.StatusOK,
+			wantBody:   []string{`name="tag"`},
+		},
+		{
+			name:           "move",
+			action:         "move",
+			category:       categoryID.String(),
+			recipes:        recipes,
+			confirm:        true,
+			wantStatus:     http.StatusSeeOther,
+			wantBulkAction: "BulkMove",
+		},
+		{
+			name:           "tag",
+			action:         "tag",
+			tag:            "  Weeknight   Dinner ",
+			recipes:        recipes,
+			confirm:        true,
+			wantStatus:     http.StatusSeeOther,
+			wantBulkAction: "BulkTag",
+		},
+		{
+			name:           "untag",
+			action:         "untag",
+			tag:            "weeknight dinner",
+			recipes:        recipes,
+			confirm:        true,
+			wantStatus:     http.StatusSeeOther,
+			wantBulkAction: "BulkUntag",
+		},
+		{
+			name:           "delete",
+			action:         "delete",
+			recipes:        recipes,
+			confirm:        true,
+			wantStatus:     http.StatusSeeOther,
+			wantBulkAction: "BulkDelete",
+		},
+		{
+			name:                  "no recipes",
+			action:                "delete",
+			confirm:               true,
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "Select at least one recipe.",
+		},
+		{
+			name:                  "unknown action",
+			action:                "publish",
+			recipes:               recipes,
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "Choose an action.",
+		},
+		{
+			name:                  "blank tag",
+			action:                "tag",
+			tag:                   " ",
+			recipes:               recipes,
+			confirm:               true,
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field is required.",
+		},
+		{
+			name:                  "tag too long",
+			action:                "tag",
+			tag:                   strings.Repeat("a", 51),
+			recipes:               recipes,
+			confirm:               true,
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field may not contain more than 50 characters.",
+		},
+		{
+			name:                  "invalid category",
+			action:                "move",
+			category:              "foo",
+			recipes:               recipes,
+			confirm:               true,
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "Invalid category.",
+		},
+		{
+			name:       "invalid recipe ID",
+			action:     "delete",
+			recipes:    []string{"foo"},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recipeModel := app.recipeModel.(*mock.RecipeModel)
+			recipeModel.LastBulkAction = ""
+
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("action", tt.action)
+			form.Add("category", tt.category)
+			form.Add("tag", tt.tag)
+			for _, recipe := range tt.recipes {
+				form.Add("recipe", recipe)
+			}
+			if tt.confirm {
+				form.Add("confirm", "true")
+			}
+
+			status, headers, body := server.postForm(t, "/recipes/bulk", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantBulkAction, recipeModel.LastBulkAction)
+
+			for _, want := range tt.wantBody {
+				assert.StringContains(t, body, want)
+			}
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+			}
+
+			if tt.wantBulkAction == "" {
+				return
+			}
+
+			assertRedirects(t, headers, "/recipes")
+			assert.Equal(t, len(tt.recipes), len(recipeModel.LastBulkRecipes))
+			for i := range tt.recipes {
+				assert.Equal(t, tt.recipes[i], recipeModel.LastBulkRecipes[i].String())
+			}
+
+			switch tt.wantBulkAction {
+			case "BulkMove":
+				assert.Equal(t, tt.category, optionalUUIDString(recipeModel.LastBulkCategory))
+			case "BulkTag", "BulkUntag":
+				assert.Equal(t, "weeknight dinner", recipeModel.LastBulkTag)
+			}
+		})
+	}
+}
+
+func Test_application_bulkRecipesPost_export(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/recipes")
+	csrfToken := extractCSRFToken(t, page)
+
+	recipeID := uuid.New()
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+	form.Add("action", "export")
+	form.Add("recipe", recipeID.String())
+	form.Add("confirm", "true")
+
+	status, headers, body := server.postForm(t, "/recipes/bulk", form)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "application/json", headers.Get("Content-Type"))
+	assert.StringContains(t, headers.Get("Content-Disposition"), "attachment")
+
+	var exported []apiRecipe
+	if err := json.Unmarshal([]byte(body), &exported); err != nil {
+		t.Fatalf("Expected JSON export; got %q: %v", body, err)
+	}
+
+	assert.Equal(t, 1, len(exported))
+	assert.Equal(t, recipeID, exported[0].ID)
+	assert.Equal(t, mock.Recipe.Title, exported[0].Title)
+	assert.Equal(t, mock.Recipe.PrepTime.Minutes(), *exported[0].PrepMinutes)
+	assert.Equal(t, mock.Recipe.CookTime.Minutes(), *exported[0].CookMinutes)
+	assert.Equal(t, mock.Recipe.TotalTime.Minutes(), *exported[0].TotalMinutes)
+	assert.Equal(t, mock.Recipe.SourceName, exported[0].SourceName)
+	assert.Equal(t, mock.Recipe.SourceURL, exported[0].SourceURL)
+	assert.Equal(t, strings.Join(mock.Recipe.Tags, ","), strings.Join(exported[0].Tags, ","))
+}
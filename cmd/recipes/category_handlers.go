@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/cdriehuys/recipes/internal/models"
@@ -9,20 +10,29 @@ import (
 )
 
 type categoryForm struct {
-	Name string
+	Household string
+	Name      string
 	validation.Validator
 }
 
 func (form *categoryForm) Validate() {
+	form.CheckField(validation.UUIDOrBlank(form.Household), "household", "This field must be a valid household ID.")
 	form.CheckField(validation.NotBlank(form.Name), "name", "This field is required.")
 	form.CheckField(validation.MaxLength(form.Name, 50), "name", "This field may not contain more than 50 characters.")
 }
 
 func (app *application) newCategory(w http.ResponseWriter, r *http.Request) {
-	_ = reqUser(r)
+	userID := reqUser(r)
+
+	households, err := app.editableHouseholds(r, userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
 
 	data := app.newTemplateData(r)
 	data.Form = &categoryForm{}
+	data.Households = households
 
 	app.render(w, r, http.StatusOK, "new-category", data)
 }
@@ -31,26 +41,52 @@ func (app *application) newCategoryPost(w http.ResponseWriter, r *http.Request)
 	userID := reqUser(r)
 
 	form := categoryForm{
-		Name: r.PostFormValue("name"),
+		Household: r.PostFormValue("household"),
+		Name:      r.PostFormValue("name"),
 	}
 	form.Validate()
 
+	households, err := app.editableHouseholds(r, userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	form.CheckField(
+		canAddToHousehold(households, form.Household),
+		"household",
+		"You may not add categories to this household.",
+	)
+
 	if !form.IsValid() {
 		data := app.newTemplateData(r)
 		data.Form = &form
+		data.Households = households
 		app.render(w, r, http.StatusUnprocessableEntity, "new-category", data)
 		return
 	}
 
 	category := models.Category{
-		ID:    uuid.New(),
-		Owner: userID,
-		Name:  form.Name,
+		ID:        uuid.New(),
+		Owner:     userID,
+		Household: optionalUUID(form.Household),
+		Name:      form.Name,
 	}
 	if err := app.categoryModel.Create(r.Context(), category); err != nil {
-		app.serverError(w, r, err)
+		if errors.Is(err, models.ErrDuplicate) {
+			form.AddFieldError("name", "You already have a category with this name.")
+
+			data := app.newTemplateData(r)
+			data.Form = &form
+			data.Households = households
+			app.render(w, r, http.StatusUnprocessableEntity, "new-category", data)
+		} else {
+			app.modelError(w, r, err)
+		}
 		return
 	}
 
+	app.sendCategoryEvent(r, userID, models.EventCategoryCreated, category.ID)
+
 	http.Redirect(w, r, "/recipes", http.StatusSeeOther)
 }
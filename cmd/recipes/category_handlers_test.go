@@ -63,6 +63,12 @@ func Test_application_newCategoryPost(t *testing.T) {
 			wantStatus:            http.StatusUnprocessableEntity,
 			wantValidationMessage: "This field may not contain more than 50 characters.",
 		},
+		{
+			name:                  "duplicate name",
+			category:              mock.ListedCategories[0].Name,
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "You already have a category with this name.",
+		},
 	}
 
 	for _, tt := range testCases {
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/validation"
+	"github.com/google/uuid"
+)
+
+type collectionForm struct {
+	Name        string
+	Description string
+	validation.Validator
+}
+
+func newCollectionForm(r *http.Request) collectionForm {
+	return collectionForm{
+		Name:        r.PostFormValue("name"),
+		Description: r.PostFormValue("description"),
+	}
+}
+
+func (form *collectionForm) Validate() {
+	form.CheckField(validation.NotBlank(form.Name), "name", "This field is required.")
+	form.CheckField(validation.MaxLength(form.Name, 100), "name", "This field may not contain more than 100 characters.")
+	form.CheckField(
+		validation.MaxLength(form.Description, 2000),
+		"description",
+		"This field may not contain more than 2000 characters.",
+	)
+}
+
+func (app *application) listCollections(w http.ResponseWriter, r *http.Request) {
+	app.renderCollections(w, r, http.StatusOK, reqUser(r), &collectionForm{})
+}
+
+func (app *application) listCollectionsPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	form := newCollectionForm(r)
+	form.Validate()
+
+	if !form.IsValid() {
+		app.renderCollections(w, r, http.StatusUnprocessableEntity, userID, &form)
+		return
+	}
+
+	collection := models.Collection{
+		ID:          uuid.New(),
+		Owner:       userID,
+		Name:        form.Name,
+		Description: form.Description,
+	}
+	if err := app.collectionModel.Create(r.Context(), collection); err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, collection.URL(), http.StatusSeeOther)
+}
+
+func (app *application) getCollection(w http.ResponseWriter, r *http.Request) {
+	app.renderCollection(w, r, "collection")
+}
+
+// printCollection renders every recipe of a collection in full on a single page so that the
+// collection can be printed as a booklet.
+func (app *application) printCollection(w http.ResponseWriter, r *http.Request) {
+	app.renderCollection(w, r, "collection-print")
+}
+
+func (app *application) editCollection(w http.ResponseWriter, r *http.Request) {
+	collectionID, ok := app.uuidPathValue(w, r, "collectionID")
+	if !ok {
+		return
+	}
+
+	collection, err := app.collectionModel.Get(r.Context(), reqUser(r), collectionID)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Collection = collection
+	data.Form = &collectionForm{Name: collection.Name, Description: collection.Description}
+
+	app.render(w, r, http.StatusOK, "edit-collection", data)
+}
+
+func (app *application) editCollectionPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	collectionID, ok := app.uuidPathValue(w, r, "collectionID")
+	if !ok {
+		return
+	}
+
+	collection, err := app.collectionModel.Get(r.Context(), userID, collectionID)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	form := newCollectionForm(r)
+	form.Validate()
+
+	if !form.IsValid() {
+		data := app.newTemplateData(r)
+		data.Collection = collection
+		data.Form = &form
+
+		app.render(w, r, http.StatusUnprocessableEntity, "edit-collection", data)
+		return
+	}
+
+	collection.Name = form.Name
+	collection.Description = form.Description
+	if err := app.collectionModel.Update(r.Context(), userID, collection); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, collection.URL(), http.StatusSeeOther)
+}
+
+func (app *application) deleteCollectionPost(w http.ResponseWriter, r *http.Request) {
+	collectionID, ok := app.uuidPathValue(w, r, "collectionID")
+	if !ok {
+		return
+	}
+
+	if err := app.collectionModel.Delete(r.Context(), reqUser(r), collectionID); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/collections", http.StatusSeeOther)
+}
+
+// collectionOrderPost saves the order of a collection's recipes. The recipe IDs are submitted in
+// their new order, which is how the page's drag and drop list submits them.
+func (app *application) collectionOrderPost(w http.ResponseWriter, r *http.Request) {
+	collectionID, ok := app.uuidPathValue(w, r, "collectionID")
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	recipeIDs := make([]uuid.UUID, 0, len(r.PostForm["recipe"]))
+	for _, raw := range r.PostForm["recipe"] {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			app.clientError(w, http.StatusBadRequest)
+			return
+		}
+
+		recipeIDs = append(recipeIDs, id)
+	}
+
+	if err := app.collectionModel.Reorder(r.Context(), reqUser(r), collectionID, recipeIDs); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/collections/"+collectionID.String(), http.StatusSeeOther)
+}
+
+func (app *application) removeCollectionRecipePost(w http.ResponseWriter, r *http.Request) {
+	collectionID, ok := app.uuidPathValue(w, r, "collectionID")
+	if !ok {
+		return
+	}
+
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	if err := app.collectionModel.RemoveRecipe(r.Context(), reqUser(r), collectionID, recipeID); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/collections/"+collectionID.String(), http.StatusSeeOther)
+}
+
+func (app *application) shareCollectionPost(w http.ResponseWriter, r *http.Request) {
+	collectionID, ok := app.uuidPathValue(w, r, "collectionID")
+	if !ok {
+		return
+	}
+
+	token, err := generateToken(32)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	if err := app.collectionModel.Share(r.Context(), reqUser(r), collectionID, token); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/collections/"+collectionID.String(), http.StatusSeeOther)
+}
+
+func (app *application) unshareCollectionPost(w http.ResponseWriter, r *http.Request) {
+	collectionID, ok := app.uuidPathValue(w, r, "collectionID")
+	if !ok {
+		return
+	}
+
+	if err := app.collectionModel.Unshare(r.Context(), reqUser(r), collectionID); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/collections/"+collectionID.String(), http.StatusSeeOther)
+}
+
+// recipeCollectionsPost adds a recipe to the collection chosen on the recipe's page.
+func (app *application) recipeCollectionsPost(w http.ResponseWriter, r *http.Request) {
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	collectionID, err := uuid.Parse(r.PostFormValue("collection"))
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	if err := app.collectionModel.AddRecipe(r.Context(), reqUser(r), collectionID, recipeID); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/recipes/"+recipeID.String(), http.StatusSeeOther)
+}
+
+// sharedCollection renders the public, read-only view of a collection referenced by its share
+// token, including every recipe in full.
+func (app *application) sharedCollection(w http.ResponseWriter, r *http.Request) {
+	collection, err := app.collectionModel.GetShared(r.Context(), r.PathValue("token"))
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	// The shared collection shows the recipes its owner can see.
+	recipes, err := app.collectionModel.Recipes(r.Context(), collection.Owner, collection.ID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Collection = collection
+	data.Recipes = recipes
+
+	app.render(w, r, http.StatusOK, "shared-collection", data)
+}
+
+func (app *application) renderCollections(w http.ResponseWriter, r *http.Request, status int, userID string, form *collectionForm) {
+	collections, err := app.collectionModel.List(r.Context(), userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Collections = collections
+	data.Form = form
+
+	app.render(w, r, status, "collections", data)
+}
+
+// renderCollection renders a page about one of the user's collections and its recipes.
+func (app *application) renderCollection(w http.ResponseWriter, r *http.Request, page string) {
+	userID := reqUser(r)
+
+	collectionID, ok := app.uuidPathValue(w, r, "collectionID")
+	if !ok {
+		return
+	}
+
+	collection, err := app.collectionModel.Get(r.Context(), userID, collectionID)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	recipes, err := app.collectionModel.Recipes(r.Context(), userID, collectionID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Collection = collection
+	data.Recipes = recipes
+
+	app.render(w, r, http.StatusOK, page, data)
+}
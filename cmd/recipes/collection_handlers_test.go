@@ -0,0 +1,286 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_application_listCollections(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, "/collections")
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, "/collections")
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, body := server.get(t, "/collections")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, mock.Collection.Name)
+		assert.StringContains(t, body, "2 recipes")
+	})
+}
+
+func Test_application_listCollectionsPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/collections")
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name                  string
+		collectionName        string
+		description           string
+		wantStatus            int
+		wantValidationMessage string
+	}{
+		{
+			name:           "valid",
+			collectionName: "Thanksgiving",
+			description:    "The whole menu.",
+			wantStatus:     http.StatusSeeOther,
+		},
+		{
+			name:                  "blank name",
+			collectionName:        " ",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field is required.",
+		},
+		{
+			name:                  "name too long",
+			collectionName:        strings.Repeat("a", 101),
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field may not contain more than 100 characters.",
+		},
+		{
+			name:                  "description too long",
+			collectionName:        "Thanksgiving",
+			description:           strings.Repeat("a", 2001),
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field may not contain more than 2000 characters.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("name", tt.collectionName)
+			form.Add("description", tt.description)
+
+			status, headers, body := server.postForm(t, "/collections", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+				return
+			}
+
+			created := app.collectionModel.(*mock.CollectionModel).LastCreatedCollection
+			assert.Equal(t, tt.collectionName, created.Name)
+			assert.Equal(t, tt.description, created.Description)
+			assert.Equal(t, mock.TestUserNormal, created.Owner)
+			assertRedirects(t, headers, created.URL())
+		})
+	}
+}
+
+func Test_application_getCollection(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	testCases := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "collection", path: mock.Collection.URL(), wantStatus: http.StatusOK},
+		{name: "print", path: mock.Collection.URL() + "/print", wantStatus: http.StatusOK},
+		{name: "edit", path: mock.Collection.URL() + "/edit", wantStatus: http.StatusOK},
+		{name: "unknown collection", path: "/collections/" + uuid.NewString(), wantStatus: http.StatusNotFound},
+		{name: "invalid ID", path: "/collections/foo", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, body := server.get(t, tt.path)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantStatus == http.StatusOK {
+				assert.StringContains(t, body, mock.Collection.Name)
+			}
+		})
+	}
+
+	t.Run("recipes in order", func(t *testing.T) {
+		_, _, body := server.get(t, mock.Collection.URL()+"/print")
+
+		first := strings.Index(body, mock.SubRecipe.Instructions)
+		second := strings.Index(body, mock.ParentRecipe.Instructions)
+		if first < 0 || second < first {
+			t.Errorf("Expected both recipes in full, in collection order; got %q", body)
+		}
+	})
+}
+
+func Test_application_collectionOrderPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, mock.Collection.URL())
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name       string
+		collection string
+		recipes    []string
+		wantStatus int
+	}{
+		{
+			name:       "valid",
+			collection: mock.Collection.ID.String(),
+			recipes:    []string{mock.ParentRecipe.ID.String(), mock.SubRecipe.ID.String()},
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:       "invalid recipe ID",
+			collection: mock.Collection.ID.String(),
+			recipes:    []string{"foo"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown collection",
+			collection: uuid.NewString(),
+			recipes:    []string{mock.SubRecipe.ID.String()},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			collectionModel := app.collectionModel.(*mock.CollectionModel)
+			collectionModel.LastReorderedRecipes = nil
+
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			for _, recipe := range tt.recipes {
+				form.Add("recipe", recipe)
+			}
+
+			status, headers, _ := server.postForm(t, "/collections/"+tt.collection+"/order", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantStatus == http.StatusSeeOther {
+				assertRedirects(t, headers, mock.Collection.URL())
+
+				reordered := collectionModel.LastReorderedRecipes
+				assert.Equal(t, len(tt.recipes), len(reordered))
+				for i := range tt.recipes {
+					assert.Equal(t, tt.recipes[i], reordered[i].String())
+				}
+			}
+		})
+	}
+}
+
+func Test_application_recipeCollectionsPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	recipePath := "/recipes/" + mock.SubRecipe.ID.String()
+
+	_, _, page := server.get(t, recipePath)
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name       string
+		collection string
+		wantStatus int
+	}{
+		{name: "valid", collection: mock.Collection.ID.String(), wantStatus: http.StatusSeeOther},
+		{name: "invalid collection ID", collection: "foo", wantStatus: http.StatusBadRequest},
+		{name: "unknown collection", collection: uuid.NewString(), wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("collection", tt.collection)
+
+			status, headers, _ := server.postForm(t, recipePath+"/collections", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantStatus == http.StatusSeeOther {
+				assertRedirects(t, headers, recipePath)
+				assert.Equal(t, mock.SubRecipe.ID, app.collectionModel.(*mock.CollectionModel).LastAddedRecipe)
+			}
+		})
+	}
+}
+
+func Test_application_shareCollectionPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, mock.Collection.URL())
+	csrfToken := extractCSRFToken(t, page)
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+
+	status, headers, _ := server.postForm(t, mock.Collection.URL()+"/share", form)
+
+	assert.Equal(t, http.StatusSeeOther, status)
+	assertRedirects(t, headers, mock.Collection.URL())
+
+	if token := app.collectionModel.(*mock.CollectionModel).LastShareToken; len(token) < 32 {
+		t.Errorf("Expected an unguessable token; got %q", token)
+	}
+}
+
+func Test_application_sharedCollection(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	t.Run("valid token", func(t *testing.T) {
+		status, _, body := server.get(t, "/c/"+mock.ValidCollectionShareToken)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, mock.Collection.Name)
+		assert.StringContains(t, body, mock.SubRecipe.Instructions)
+		assert.StringContains(t, body, mock.ParentRecipe.Instructions)
+
+		if strings.Contains(body, "/edit") || strings.Contains(body, "/remove") {
+			t.Errorf("Expected shared collection to contain no edit or remove actions; got %q", body)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		status, _, _ := server.get(t, "/c/foo")
+
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
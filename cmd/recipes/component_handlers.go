@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cdriehuys/recipes/internal/ingredients"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/validation"
+	"github.com/google/uuid"
+)
+
+// maxRecipeScale is the largest factor a recipe may be scaled by.
+const maxRecipeScale = 100
+
+// recipeScales are the factors offered for scaling a recipe.
+var recipeScales = []float64{0.5, 1, 2, 3}
+
+// maxComponentDepth limits how deeply sub-recipes are expanded, in case recipes refer to each other
+// in a loop.
+const maxComponentDepth = 5
+
+// parseScale returns the factor to scale a recipe by from a query string. Missing or invalid values
+// leave the recipe unscaled.
+func parseScale(query url.Values) float64 {
+	scale, err := strconv.ParseFloat(query.Get("scale"), 64)
+	if err != nil || scale <= 0 || scale > maxRecipeScale {
+		return 1
+	}
+
+	return scale
+}
+
+// formatScale formats a scale factor for use in a query string.
+func formatScale(scale float64) string {
+	return strconv.FormatFloat(scale, 'f', -1, 64)
+}
+
+// recipeIngredient is a line of a recipe's ingredient list as presented on the recipe's page.
+type recipeIngredient struct {
+	// Text is the ingredient line, scaled if necessary.
+	Text string
+	// Component is the recipe the ingredient refers to, if any.
+	Component *models.RecipeComponent
+	// ComponentScale is the factor the component recipe must be scaled by to make the amount the
+	// ingredient calls for.
+	ComponentScale float64
+}
+
+// ComponentURL returns the URL of the scaled recipe the ingredient refers to.
+func (i recipeIngredient) ComponentURL() string {
+	if i.ComponentScale == 1 {
+		return i.Component.URL()
+	}
+
+	return i.Component.URL() + "?scale=" + formatScale(i.ComponentScale)
+}
+
+// componentFactor returns the number of batches of a sub-recipe an ingredient calls for.
+func componentFactor(ingredient ingredients.Ingredient) float64 {
+	if ingredient.HasQuantity() {
+		return ingredient.Quantity
+	}
+
+	return 1
+}
+
+// newRecipeIngredients returns the recipe's ingredient list scaled by the provided factor, with
+// references to other recipes resolved.
+func newRecipeIngredients(recipe models.Recipe, components map[string]models.RecipeComponent, scale float64) []recipeIngredient {
+	list := recipe.IngredientList()
+
+	rows := make([]recipeIngredient, 0, len(list))
+	for _, ingredient := range list {
+		row := recipeIngredient{Text: ingredient.Raw}
+		if scale != 1 && ingredient.HasQuantity() {
+			row.Text = ingredient.Scale(scale).String()
+		}
+
+		if component, ok := components[models.ComponentKey(ingredient.Name)]; ok && models.IsComponentReference(ingredient) {
+			row.Component = &component
+			row.ComponentScale = scale * componentFactor(ingredient)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// textSegment is a piece of text that may link to another page.
+type textSegment struct {
+	Text string
+	URL  string
+}
+
+// linkComponents splits text around any mentions of the component recipes, linking each mention
+// to the component.
+func linkComponents(text string, components map[string]models.RecipeComponent) []textSegment {
+	if len(components) == 0 {
+		return []textSegment{{Text: text}}
+	}
+
+	// Components are keyed by the ingredients linked to them, whose names may differ from the
+	// components' titles.
+	byTitle := make(map[string]models.RecipeComponent, len(components))
+	titles := make([]string, 0, len(components))
+	for _, component := range components {
+		key := models.ComponentKey(component.Title)
+		if _, ok := byTitle[key]; !ok {
+			byTitle[key] = component
+			titles = append(titles, regexp.QuoteMeta(component.Title))
+		}
+	}
+
+	// Prefer the longest title where one contains another.
+	slices.SortFunc(titles, func(a, b string) int { return len(b) - len(a) })
+	rx := regexp.MustCompile(`(?i)\b(?:` + strings.Join(titles, "|") + `)\b`)
+
+	var segments []textSegment
+	offset := 0
+	for _, loc := range rx.FindAllStringIndex(text, -1) {
+		if loc[0] > offset {
+			segments = append(segments, textSegment{Text: text[offset:loc[0]]})
+		}
+
+		mention := text[loc[0]:loc[1]]
+		segments = append(segments, textSegment{Text: mention, URL: byTitle[models.ComponentKey(mention)].URL()})
+		offset = loc[1]
+	}
+
+	if offset < len(text) {
+		segments = append(segments, textSegment{Text: text[offset:]})
+	}
+
+	return segments
+}
+
+// referencingRecipes returns the recipes, other than those being deleted, that use any of the
+// recipes being deleted as an ingredient.
+func (app *application) referencingRecipes(ctx context.Context, userID string, deleted []models.Recipe) ([]models.RecipeComponent, error) {
+	seen := make(map[uuid.UUID]bool, len(deleted))
+	for _, recipe := range deleted {
+		seen[recipe.ID] = true
+	}
+
+	var referencing []models.RecipeComponent
+	for _, recipe := range deleted {
+		referencedBy, err := app.recipeModel.ReferencedBy(ctx, userID, recipe)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, other := range referencedBy {
+			if !seen[other.ID] {
+				seen[other.ID] = true
+				referencing = append(referencing, other)
+			}
+		}
+	}
+
+	return referencing, nil
+}
+
+// expandedIngredients returns the recipe's ingredients scaled by the provided factor, with any
+// references to other recipes replaced by the scaled ingredients of those recipes.
+func (app *application) expandedIngredients(
+	ctx context.Context,
+	userID string,
+	recipe models.Recipe,
+	scale float64,
+	path []uuid.UUID,
+) ([]ingredients.Ingredient, error) {
+	path = append(slices.Clip(path), recipe.ID)
+
+	var components map[string]models.RecipeComponent
+	if len(path) <= maxComponentDepth {
+		var err error
+		components, err = app.recipeModel.Components(ctx, userID, recipe.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var list []ingredients.Ingredient
+	for _, ingredient := range recipe.IngredientList() {
+		component, ok := components[models.ComponentKey(ingredient.Name)]
+		if !ok || !models.IsComponentReference(ingredient) || slices.Contains(path, component.ID) {
+			list = append(list, ingredient.Scale(scale))
+			continue
+		}
+
+		child, err := app.recipeModel.GetByID(ctx, userID, component.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		expanded, err := app.expandedIngredients(ctx, userID, child, scale*componentFactor(ingredient), path)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, expanded...)
+	}
+
+	return list, nil
+}
+
+// componentLink is a row of the form used to link a recipe's ingredients to other recipes.
+type componentLink struct {
+	Ingredient ingredients.Ingredient
+	Key        string
+	// Suggested is the recipe whose title matches the ingredient, if any.
+	Suggested *models.RecipeComponent
+	// Component is the ID of the recipe the ingredient is linked to, if any.
+	Component string
+}
+
+// newComponentLinks lists each distinct ingredient of a recipe that may refer to another recipe,
+// along with the recipe it is linked to and any suggested recipe.
+func newComponentLinks(recipe models.Recipe, suggestions []models.RecipeComponent, links map[string]string) []componentLink {
+	suggested := make(map[string]models.RecipeComponent, len(suggestions))
+	for _, component := range suggestions {
+		if component.ID != recipe.ID {
+			suggested[models.ComponentKey(component.Title)] = component
+		}
+	}
+
+	var rows []componentLink
+	seen := make(map[string]bool)
+
+	for _, ingredient := range recipe.IngredientList() {
+		key := models.ComponentKey(ingredient.Name)
+		if !models.IsComponentReference(ingredient) || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		row := componentLink{Ingredient: ingredient, Key: key, Component: links[key]}
+		if component, ok := suggested[key]; ok {
+			row.Suggested = &component
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+type componentLinksForm struct {
+	// Components maps the normalized names of ingredients to the IDs of the recipes they are made
+	// from.
+	Components map[string]string
+	validation.Validator
+}
+
+func (form *componentLinksForm) Validate() {
+	for _, component := range form.Components {
+		if !validation.UUIDOrBlank(component) {
+			form.AddNonFieldError("Choose each recipe from the list.")
+			break
+		}
+	}
+}
+
+func (app *application) recipeComponentLinks(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	components, err := app.recipeModel.Components(r.Context(), userID, recipeID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	form := componentLinksForm{Components: make(map[string]string, len(components))}
+	for key, component := range components {
+		form.Components[key] = component.ID.String()
+	}
+
+	app.renderRecipeComponentLinks(w, r, http.StatusOK, userID, recipeID, &form)
+}
+
+func (app *application) recipeComponentLinksPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	keys, components := r.PostForm["ingredient"], r.PostForm["component"]
+	if len(keys) != len(components) {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form := componentLinksForm{Components: make(map[string]string)}
+	for i, key := range keys {
+		// A blank component leaves the ingredient unlinked.
+		if key != "" && components[i] != "" {
+			form.Components[key] = components[i]
+		}
+	}
+	form.Validate()
+
+	if !form.IsValid() {
+		app.renderRecipeComponentLinks(w, r, http.StatusUnprocessableEntity, userID, recipeID, &form)
+		return
+	}
+
+	links := make(map[string]uuid.UUID, len(form.Components))
+	for key, component := range form.Components {
+		links[key] = uuid.MustParse(component)
+	}
+
+	if err := app.recipeModel.SetComponents(r.Context(), userID, recipeID, links); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/recipes/"+recipeID.String(), http.StatusSeeOther)
+}
+
+func (app *application) renderRecipeComponentLinks(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	userID string,
+	recipeID uuid.UUID,
+	form *componentLinksForm,
+) {
+	recipe, err := app.recipeModel.GetByID(r.Context(), userID, recipeID)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	suggestions, err := app.recipeModel.FindByTitles(r.Context(), userID, recipe.ComponentTitles())
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	// Recipes beyond the first page of the user's recipes can still be chosen if they're suggested
+	// or already linked.
+	recipes, err := app.recipeModel.List(r.Context(), userID, models.RecipeListOptions{Sort: models.SortTitle})
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	linked, err := app.recipeModel.Components(r.Context(), userID, recipeID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	extra := suggestions
+	for _, component := range linked {
+		extra = append(extra, component)
+	}
+
+	for _, component := range extra {
+		if !slices.ContainsFunc(recipes, func(recipe models.Recipe) bool { return recipe.ID == component.ID }) {
+			recipes = append(recipes, models.Recipe{ID: component.ID, Title: component.Title})
+		}
+	}
+
+	recipes = slices.DeleteFunc(recipes, func(candidate models.Recipe) bool { return candidate.ID == recipe.ID })
+	slices.SortStableFunc(recipes, func(a, b models.Recipe) int {
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	})
+
+	data := app.newTemplateData(r)
+	data.ComponentLinks = newComponentLinks(recipe, suggestions, form.Components)
+	data.Form = form
+	data.Recipe = recipe
+	data.Recipes = recipes
+
+	app.render(w, r, status, "recipe-components", data)
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_parseScale(t *testing.T) {
+	testCases := []struct {
+		query string
+		want  float64
+	}{
+		{query: "", want: 1},
+		{query: "scale=2", want: 2},
+		{query: "scale=0.5", want: 0.5},
+		{query: "scale=0", want: 1},
+		{query: "scale=-2", want: 1},
+		{query: "scale=101", want: 1},
+		{query: "scale=lots", want: 1},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.query, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+
+			assert.Equal(t, tt.want, parseScale(query))
+		})
+	}
+}
+
+func Test_linkComponents(t *testing.T) {
+	dough := models.RecipeComponent{ID: uuid.New(), Title: "Pizza Dough"}
+	// Components are keyed by the ingredient they're linked to, which may not be their title.
+	components := map[string]models.RecipeComponent{"dough": dough}
+
+	segments := linkComponents("Stretch the pizza dough. More PIZZA DOUGH!", components)
+
+	var rendered []string
+	for _, segment := range segments {
+		if segment.URL != "" {
+			rendered = append(rendered, "["+segment.Text+"]("+segment.URL+")")
+		} else {
+			rendered = append(rendered, segment.Text)
+		}
+	}
+
+	want := "Stretch the [pizza dough](" + dough.URL() + "). More [PIZZA DOUGH](" + dough.URL() + ")!"
+	assert.Equal(t, want, strings.Join(rendered, ""))
+}
+
+func Test_application_getRecipe_components(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	t.Run("parent", func(t *testing.T) {
+		status, _, body := server.get(t, "/recipes/"+mock.ParentRecipe.ID.String())
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, `<a class="underline" href="/recipes/`+mock.SubRecipe.ID.String()+`?scale=2">2 batches pizza dough</a>`)
+		assert.StringContains(t, body, `Stretch the <a class="underline" href="/recipes/`+mock.SubRecipe.ID.String()+`">pizza dough</a>`)
+	})
+
+	t.Run("scaled parent scales child", func(t *testing.T) {
+		status, _, body := server.get(t, "/recipes/"+mock.ParentRecipe.ID.String()+"?scale=1.5")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, `href="/recipes/`+mock.SubRecipe.ID.String()+`?scale=3">3 batches pizza dough</a>`)
+		assert.StringContains(t, body, "1 1/2 cups mozzarella")
+	})
+
+	t.Run("child", func(t *testing.T) {
+		status, _, body := server.get(t, "/recipes/"+mock.SubRecipe.ID.String()+"?scale=2")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, "6 cups flour")
+		assert.StringContains(t, body, "Used In")
+		assert.StringContains(t, body, `<a class="underline" href="/recipes/`+mock.ParentRecipe.ID.String()+`">Pizza</a>`)
+	})
+}
+
+func Test_application_deleteRecipePost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/new-recipe")
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name        string
+		recipeID    uuid.UUID
+		confirm     string
+		wantStatus  int
+		wantWarning bool
+	}{
+		{
+			name:       "unreferenced",
+			recipeID:   uuid.New(),
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:        "referenced",
+			recipeID:    mock.SubRecipe.ID,
+			wantStatus:  http.StatusConflict,
+			wantWarning: true,
+		},
+		{
+			name:       "referenced and confirmed",
+			recipeID:   mock.SubRecipe.ID,
+			confirm:    "true",
+			wantStatus: http.StatusSeeOther,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("confirm", tt.confirm)
+
+			status, headers, body := server.postForm(t, "/recipes/"+tt.recipeID.String()+"/delete", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantWarning {
+				assert.StringContains(t, body, "This recipe is used as an ingredient by other recipes.")
+				assert.StringContains(t, body, mock.ParentRecipe.Title)
+				assert.StringContains(t, body, `name="confirm" value="true"`)
+			} else {
+				assertRedirects(t, headers, "/recipes")
+			}
+		})
+	}
+}
+
+func Test_newComponentLinks(t *testing.T) {
+	recipe := models.Recipe{
+		ID:          uuid.New(),
+		Ingredients: "1 batch pizza dough\n2 cups marinara sauce\nPesto\n1/2 batch Pizza  Dough",
+	}
+	dough := models.RecipeComponent{ID: uuid.New(), Title: "Pizza Dough"}
+	pesto := models.RecipeComponent{ID: uuid.New(), Title: "Pesto"}
+
+	rows := newComponentLinks(recipe, []models.RecipeComponent{dough, pesto}, map[string]string{"pesto": pesto.ID.String()})
+
+	assert.Equal(t, 2, len(rows))
+
+	// A suggestion is offered, but the ingredient isn't linked until the user chooses it.
+	assert.Equal(t, "pizza dough", rows[0].Key)
+	assert.Equal(t, dough.ID, rows[0].Suggested.ID)
+	assert.Equal(t, "", rows[0].Component)
+
+	assert.Equal(t, "pesto", rows[1].Key)
+	assert.Equal(t, pesto.ID.String(), rows[1].Component)
+}
+
+func Test_application_recipeComponentLinks(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	componentsURL := "/recipes/" + mock.ParentRecipe.ID.String() + "/components"
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, componentsURL)
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, componentsURL)
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, body := server.get(t, componentsURL)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, `<input type="hidden" name="ingredient" value="pizza dough">`)
+		assert.StringContains(t, body, `<option value="`+mock.SubRecipe.ID.String()+`" selected>Pizza Dough (suggested)</option>`)
+		assert.StringContains(t, body, mock.Recipe.Title)
+	})
+
+}
+
+func Test_application_recipeComponentLinksPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	componentsURL := "/recipes/" + mock.ParentRecipe.ID.String() + "/components"
+
+	_, _, page := server.get(t, componentsURL)
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name                  string
+		ingredients           []string
+		components            []string
+		wantStatus            int
+		wantValidationMessage string
+		wantComponents        map[string]uuid.UUID
+	}{
+		{
+			name:           "linked",
+			ingredients:    []string{"pizza dough"},
+			components:     []string{mock.SubRecipe.ID.String()},
+			wantStatus:     http.StatusSeeOther,
+			wantComponents: map[string]uuid.UUID{"pizza dough": mock.SubRecipe.ID},
+		},
+		{
+			name:           "unlinked",
+			ingredients:    []string{"pizza dough"},
+			components:     []string{""},
+			wantStatus:     http.StatusSeeOther,
+			wantComponents: map[string]uuid.UUID{},
+		},
+		{
+			name:                  "invalid recipe",
+			ingredients:           []string{"pizza dough"},
+			components:            []string{"pizza-dough"},
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "Choose each recipe from the list.",
+		},
+		{
+			name:        "mismatched fields",
+			ingredients: []string{"pizza dough", "mozzarella"},
+			components:  []string{mock.SubRecipe.ID.String()},
+			wantStatus:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			for _, ingredient := range tt.ingredients {
+				form.Add("ingredient", ingredient)
+			}
+			for _, component := range tt.components {
+				form.Add("component", component)
+			}
+
+			status, headers, body := server.postForm(t, componentsURL, form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+			}
+
+			if tt.wantStatus == http.StatusSeeOther {
+				assertRedirects(t, headers, "/recipes/"+mock.ParentRecipe.ID.String())
+
+				components := app.recipeModel.(*mock.RecipeModel).LastComponents
+				assert.Equal(t, len(tt.wantComponents), len(components))
+				for ingredient, component := range tt.wantComponents {
+					assert.Equal(t, component, components[ingredient])
+				}
+			}
+		})
+	}
+}
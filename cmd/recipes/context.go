@@ -2,4 +2,7 @@ package main
 
 type contextKey string
 
-const contextKeyUserID = contextKey("userID")
+const (
+	contextKeyAccessToken = contextKey("accessToken")
+	contextKeyUserID      = contextKey("userID")
+)
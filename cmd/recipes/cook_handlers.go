@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/cdriehuys/recipes/internal/ingredients"
+	"github.com/cdriehuys/recipes/internal/timers"
+)
+
+// cookStep is a single step of a recipe's instructions as presented in cook mode.
+type cookStep struct {
+	Number int
+	// Segments holds the text of the step split around any detected timers.
+	Segments    []timers.Segment
+	Ingredients []ingredients.Ingredient
+}
+
+// Previous returns the number of the step before this one.
+func (s cookStep) Previous() int {
+	return s.Number - 1
+}
+
+// Next returns the number of the step after this one.
+func (s cookStep) Next() int {
+	return s.Number + 1
+}
+
+// stepNumberRX matches the numbering commonly left at the start of each step, such as "1.",
+// "2)", or "Step 3:".
+var stepNumberRX = regexp.MustCompile(`(?i)^(?:step\s*)?\d+\s*[.):-]\s*`)
+
+// splitSteps splits instructions into steps, one per non-blank line, with any leading numbering
+// or bullets removed.
+func splitSteps(instructions string) []string {
+	var steps []string
+	for _, line := range strings.Split(instructions, "\n") {
+		line = strings.TrimLeft(strings.TrimSpace(line), "-*• \t")
+		line = stepNumberRX.ReplaceAllString(line, "")
+		if line != "" {
+			steps = append(steps, line)
+		}
+	}
+
+	return steps
+}
+
+// newCookSteps splits instructions into steps along with the ingredients each step mentions.
+func newCookSteps(instructions string, list []ingredients.Ingredient) []cookStep {
+	texts := splitSteps(instructions)
+
+	steps := make([]cookStep, 0, len(texts))
+	for i, text := range texts {
+		steps = append(steps, cookStep{
+			Number:      i + 1,
+			Segments:    timers.Segments(text),
+			Ingredients: ingredients.MentionedIn(text, list),
+		})
+	}
+
+	return steps
+}
+
+func (app *application) cookMode(w http.ResponseWriter, r *http.Request) {
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	recipe, err := app.recipeModel.GetByID(r.Context(), reqUser(r), recipeID)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.CookSteps = newCookSteps(recipe.Instructions, recipe.IngredientList())
+	data.Recipe = recipe
+
+	app.render(w, r, http.StatusOK, "cook-mode", data)
+}
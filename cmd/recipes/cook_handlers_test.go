@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_splitSteps(t *testing.T) {
+	testCases := []struct {
+		name         string
+		instructions string
+		want         string
+	}{
+		{name: "single line", instructions: "Mix it all together.", want: "Mix it all together."},
+		{name: "lines", instructions: "Preheat.\nMix.\r\n\nBake.", want: "Preheat.|Mix.|Bake."},
+		{name: "numbered", instructions: "1. Preheat.\n2) Mix.\nStep 3: Bake.", want: "Preheat.|Mix.|Bake."},
+		{name: "bullets", instructions: "- Preheat.\n* Mix.\n• Bake.", want: "Preheat.|Mix.|Bake."},
+		{name: "leading quantity", instructions: "350 degrees is hot.", want: "350 degrees is hot."},
+		{name: "blank", instructions: " \n\n", want: ""},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, strings.Join(splitSteps(tt.instructions), "|"))
+		})
+	}
+}
+
+func Test_application_cookMode(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	cookURL := "/recipes/" + uuid.NewString() + "/cook"
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, cookURL)
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, cookURL)
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, _ := server.get(t, "/recipes/not-a-uuid/cook")
+
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, body := server.get(t, cookURL)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, "Step 1 of 1")
+		assert.StringContains(t, body, "Mix it all together.")
+	})
+}
+
+func Test_newCookSteps(t *testing.T) {
+	recipe := mock.Recipe
+	recipe.Ingredients = "2 cups flour\n2 eggs\n1 cup milk"
+	recipe.Instructions = "1. Whisk the flour and milk.\n2. Beat in the eggs, then bake 25-30 minutes."
+
+	steps := newCookSteps(recipe.Instructions, recipe.IngredientList())
+
+	assert.Equal(t, 2, len(steps))
+
+	var firstIngredients []string
+	for _, ingredient := range steps[0].Ingredients {
+		firstIngredients = append(firstIngredients, ingredient.Raw)
+	}
+	assert.Equal(t, "2 cups flour|1 cup milk", strings.Join(firstIngredients, "|"))
+
+	second := steps[1]
+	assert.Equal(t, 2, second.Number)
+	assert.Equal(t, 1, len(second.Ingredients))
+	assert.Equal(t, 3, len(second.Segments))
+	assert.Equal(t, "25-30 minutes", second.Segments[1].Text)
+	assert.Equal(t, "25 min – 30 min", second.Segments[1].Timer.Label())
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/validation"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type cookLogForm struct {
+	CookedOn string
+	Notes    string
+	Rating   string
+	validation.Validator
+}
+
+func (form *cookLogForm) Validate() {
+	form.CheckField(validation.ValidDate(form.CookedOn), "cookedOn", "This field must be a valid date.")
+	form.CheckField(validation.MaxLength(form.Notes, 2000), "notes", "This field may not contain more than 2000 characters.")
+	form.CheckField(
+		validation.PermittedValue(form.Rating, "", "1", "2", "3", "4", "5"),
+		"rating",
+		"This field must be a whole number from 1 to 5.",
+	)
+}
+
+// cookedOn returns the validated date the recipe was cooked on.
+func (form *cookLogForm) cookedOn() time.Time {
+	cookedOn, _ := time.Parse(time.DateOnly, form.CookedOn)
+
+	return cookedOn
+}
+
+// rating returns the validated rating, which is null if no rating was provided.
+func (form *cookLogForm) rating() pgtype.Int2 {
+	rating, err := strconv.ParseInt(form.Rating, 10, 16)
+	if err != nil {
+		return pgtype.Int2{}
+	}
+
+	return pgtype.Int2{Int16: int16(rating), Valid: true}
+}
+
+func (app *application) cookedRecipe(w http.ResponseWriter, r *http.Request) {
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	form := cookLogForm{
+		CookedOn: time.Now().Format(time.DateOnly),
+	}
+
+	app.renderCookLogForm(w, r, http.StatusOK, reqUser(r), recipeID, &form)
+}
+
+func (app *application) cookedRecipePost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	form := cookLogForm{
+		CookedOn: r.PostFormValue("cookedOn"),
+		Notes:    r.PostFormValue("notes"),
+		Rating:   r.PostFormValue("rating"),
+	}
+	form.Validate()
+
+	if !form.IsValid() {
+		app.renderCookLogForm(w, r, http.StatusUnprocessableEntity, userID, recipeID, &form)
+		return
+	}
+
+	entry := models.CookLogEntry{
+		ID:       uuid.New(),
+		Recipe:   recipeID,
+		Owner:    userID,
+		CookedOn: form.cookedOn(),
+		Notes:    form.Notes,
+		Rating:   form.rating(),
+	}
+	if err := app.cookLogModel.Add(r.Context(), entry); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/recipes/"+recipeID.String(), http.StatusSeeOther)
+}
+
+func (app *application) deleteCookLogEntryPost(w http.ResponseWriter, r *http.Request) {
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	entryID, ok := app.uuidPathValue(w, r, "entryID")
+	if !ok {
+		return
+	}
+
+	if err := app.cookLogModel.Delete(r.Context(), reqUser(r), entryID); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/recipes/"+recipeID.String(), http.StatusSeeOther)
+}
+
+func (app *application) renderCookLogForm(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	userID string,
+	recipeID uuid.UUID,
+	form *cookLogForm,
+) {
+	recipe, err := app.recipeModel.GetByID(r.Context(), userID, recipeID)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = form
+	data.Recipe = recipe
+
+	app.render(w, r, status, "cook-log-entry", data)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_application_getRecipe_cookLog(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	status, _, body := server.get(t, "/recipes/"+uuid.NewString())
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.StringContains(t, body, "I cooked this")
+	assert.StringContains(t, body, mock.CookLogEntry.Notes)
+	assert.StringContains(t, body, mock.CookLogEntry.CookedOn.Format("1/2/2006"))
+}
+
+func Test_application_cookedRecipe(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	cookedURL := "/recipes/" + uuid.NewString() + "/cooked"
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, cookedURL)
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, cookedURL)
+	})
+
+	t.Run("defaults to today", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, body := server.get(t, cookedURL)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, time.Now().Format(time.DateOnly))
+	})
+}
+
+func Test_application_cookedRecipePost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	recipeID := uuid.New()
+	recipeURL := "/recipes/" + recipeID.String()
+
+	_, _, formResponse := server.get(t, recipeURL+"/cooked")
+	csrfToken := extractCSRFToken(t, formResponse)
+
+	testCases := []struct {
+		name                  string
+		cookedOn              string
+		notes                 string
+		rating                string
+		wantStatus            int
+		wantValidationMessage string
+	}{
+		{
+			name:       "date only",
+			cookedOn:   "2024-05-04",
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:       "notes and rating",
+			cookedOn:   "2024-05-04",
+			notes:      "Needed more salt.",
+			rating:     "3",
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:                  "missing date",
+			cookedOn:              "",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be a valid date.",
+		},
+		{
+			name:                  "notes too long",
+			cookedOn:              "2024-05-04",
+			notes:                 strings.Repeat("a", 2001),
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field may not contain more than 2000 characters.",
+		},
+		{
+			name:                  "invalid rating",
+			cookedOn:              "2024-05-04",
+			rating:                "0",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be a whole number from 1 to 5.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("cookedOn", tt.cookedOn)
+			form.Add("notes", tt.notes)
+			form.Add("rating", tt.rating)
+
+			status, headers, body := server.postForm(t, recipeURL+"/cooked", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+				return
+			}
+
+			created := app.cookLogModel.(*mock.CookLogModel).LastAddedEntry
+			assert.Equal(t, recipeID, created.Recipe)
+			assert.Equal(t, mock.TestUserNormal, created.Owner)
+			assert.Equal(t, tt.cookedOn, created.CookedOn.Format(time.DateOnly))
+			assert.Equal(t, tt.notes, created.Notes)
+			assert.Equal(t, tt.rating != "", created.Rating.Valid)
+			assertRedirects(t, headers, recipeURL)
+		})
+	}
+}
+
+func Test_application_deleteCookLogEntryPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	recipeURL := "/recipes/" + uuid.NewString()
+	entryID := uuid.New()
+
+	_, _, page := server.get(t, recipeURL)
+
+	form := url.Values{}
+	form.Add("csrf_token", extractCSRFToken(t, page))
+
+	status, headers, _ := server.postForm(t, recipeURL+"/cooked/"+entryID.String()+"/delete", form)
+
+	assert.Equal(t, http.StatusSeeOther, status)
+	assert.Equal(t, entryID, app.cookLogModel.(*mock.CookLogModel).LastDeletedEntry)
+	assertRedirects(t, headers, recipeURL)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/validation"
+	"github.com/google/uuid"
+)
+
+// maxDraftBytes limits the combined size of the values in an automatically saved draft.
+const maxDraftBytes = 256 * 1024
+
+// resumeDraft returns the recipe form to present to the user. If the request names one of the
+// user's drafts of the same recipe, the form is populated from the draft. Otherwise the provided
+// form is used and a new draft ID is assigned for it to be saved to.
+func (app *application) resumeDraft(r *http.Request, userID string, recipeID *uuid.UUID, form RecipeForm) (RecipeForm, error) {
+	rawID := r.URL.Query().Get("draft")
+	if rawID == "" {
+		form.Draft = uuid.NewString()
+		return form, nil
+	}
+
+	draftID, err := uuid.Parse(rawID)
+	if err != nil {
+		return RecipeForm{}, models.ErrNotFound
+	}
+
+	draft, err := app.draftModel.Get(r.Context(), userID, draftID)
+	if err != nil {
+		return RecipeForm{}, err
+	}
+
+	if optionalUUIDString(draft.Recipe) != optionalUUIDString(recipeID) {
+		return RecipeForm{}, models.ErrNotFound
+	}
+
+	resumed := recipeFormFromValues(func(name string) string { return draft.Values[name] })
+	resumed.Draft = draft.ID.String()
+
+	return resumed, nil
+}
+
+// discardDraft deletes the draft a recipe form was saved to once the recipe itself has been saved.
+// Failures are logged rather than reported since the recipe has already been saved.
+func (app *application) discardDraft(r *http.Request, userID string, form *RecipeForm) {
+	draftID := optionalUUID(form.Draft)
+	if draftID == nil {
+		return
+	}
+
+	// Forms that were submitted before their first automatic save have no draft to delete.
+	err := app.draftModel.Delete(r.Context(), userID, *draftID)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		app.logger.ErrorContext(r.Context(), "Failed to discard recipe draft.", "id", draftID, "error", err)
+	}
+}
+
+func (app *application) listDrafts(w http.ResponseWriter, r *http.Request) {
+	drafts, err := app.draftModel.List(r.Context(), reqUser(r))
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Drafts = drafts
+
+	app.render(w, r, http.StatusOK, "drafts", data)
+}
+
+// draftsPost saves the current contents of a recipe form to its draft. It is called in the
+// background by the add and edit recipe forms, so the contents are saved as-is without being
+// validated.
+func (app *application) draftsPost(w http.ResponseWriter, r *http.Request) {
+	form := newRecipeForm(r)
+
+	draftID, err := uuid.Parse(form.Draft)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	recipe := r.PostFormValue("recipe")
+	if !validation.UUIDOrBlank(recipe) {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	values := form.values()
+
+	size := 0
+	for _, value := range values {
+		size += len(value)
+	}
+
+	if size > maxDraftBytes {
+		app.clientError(w, http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	draft := models.RecipeDraft{
+		ID:     draftID,
+		Owner:  reqUser(r),
+		Recipe: optionalUUID(recipe),
+		Values: values,
+	}
+	if err := app.draftModel.Save(r.Context(), draft); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *application) deleteDraftPost(w http.ResponseWriter, r *http.Request) {
+	draftID, ok := app.uuidPathValue(w, r, "draftID")
+	if !ok {
+		return
+	}
+
+	if err := app.draftModel.Delete(r.Context(), reqUser(r), draftID); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/drafts", http.StatusSeeOther)
+}
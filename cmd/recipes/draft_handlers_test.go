@@ -0,0 +1,227 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_application_listDrafts(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, "/drafts")
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, "/drafts")
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, body := server.get(t, "/drafts")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, mock.Draft.Values["title"])
+		assert.StringContains(t, body, mock.Draft.URL())
+	})
+}
+
+func Test_application_addRecipe_draft(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	testCases := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   []string
+	}{
+		{
+			name:       "new draft",
+			path:       "/new-recipe",
+			wantStatus: http.StatusOK,
+			wantBody:   []string{`name="draft"`, "data-autosave"},
+		},
+		{
+			name:       "resume draft",
+			path:       mock.Draft.URL(),
+			wantStatus: http.StatusOK,
+			wantBody: []string{
+				`name="draft" value="` + mock.Draft.ID.String() + `"`,
+				mock.Draft.Values["title"],
+				mock.Draft.Values["instructions"],
+			},
+		},
+		{
+			name:       "unknown draft",
+			path:       "/new-recipe?draft=" + uuid.NewString(),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid draft ID",
+			path:       "/new-recipe?draft=foo",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "draft of another recipe",
+			path:       "/recipes/" + uuid.NewString() + "/edit?draft=" + mock.Draft.ID.String(),
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, body := server.get(t, tt.path)
+
+			assert.Equal(t, tt.wantStatus, status)
+			for _, want := range tt.wantBody {
+				assert.StringContains(t, body, want)
+			}
+		})
+	}
+}
+
+func Test_application_draftsPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/new-recipe")
+	csrfToken := extractCSRFToken(t, page)
+
+	draftID := uuid.New()
+	recipeID := uuid.New()
+
+	testCases := []struct {
+		name         string
+		draft        string
+		recipe       string
+		title        string
+		instructions string
+		wantStatus   int
+		wantRecipe   *uuid.UUID
+	}{
+		{
+			name:       "new recipe",
+			draft:      draftID.String(),
+			title:      "Unfinished",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "existing recipe",
+			draft:      draftID.String(),
+			recipe:     recipeID.String(),
+			title:      "Unfinished",
+			wantStatus: http.StatusNoContent,
+			wantRecipe: &recipeID,
+		},
+		{
+			name:       "missing draft ID",
+			title:      "Unfinished",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid recipe ID",
+			draft:      draftID.String(),
+			recipe:     "foo",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "too large",
+			draft:        draftID.String(),
+			instructions: strings.Repeat("a", maxDraftBytes+1),
+			wantStatus:   http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			draftModel := app.draftModel.(*mock.DraftModel)
+			draftModel.LastSavedDraft = models.RecipeDraft{}
+
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("draft", tt.draft)
+			form.Add("recipe", tt.recipe)
+			form.Add("title", tt.title)
+			form.Add("instructions", tt.instructions)
+
+			status, _, _ := server.postForm(t, "/drafts", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			saved := draftModel.LastSavedDraft
+			if tt.wantStatus != http.StatusNoContent {
+				assert.Equal(t, uuid.UUID{}, saved.ID)
+				return
+			}
+
+			assert.Equal(t, draftID, saved.ID)
+			assert.Equal(t, mock.TestUserNormal, saved.Owner)
+			assert.Equal(t, tt.title, saved.Values["title"])
+			assert.Equal(t, optionalUUIDString(tt.wantRecipe), optionalUUIDString(saved.Recipe))
+		})
+	}
+}
+
+func Test_application_addRecipePost_discardsDraft(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, mock.Draft.URL())
+	csrfToken := extractCSRFToken(t, page)
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+	form.Add("draft", mock.Draft.ID.String())
+	form.Add("title", mock.Draft.Values["title"])
+	form.Add("instructions", mock.Draft.Values["instructions"])
+
+	status, _, _ := server.postForm(t, "/new-recipe", form)
+
+	assert.Equal(t, http.StatusSeeOther, status)
+	assert.Equal(t, mock.Draft.ID, app.draftModel.(*mock.DraftModel).LastDeletedDraft)
+}
+
+func Test_application_deleteDraftPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/drafts")
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name       string
+		draft      string
+		wantStatus int
+	}{
+		{name: "existing draft", draft: mock.Draft.ID.String(), wantStatus: http.StatusSeeOther},
+		{name: "unknown draft", draft: uuid.NewString(), wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+
+			status, headers, _ := server.postForm(t, "/drafts/"+tt.draft+"/delete", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantStatus == http.StatusSeeOther {
+				assertRedirects(t, headers, "/drafts")
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cdriehuys/recipes/internal/atom"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+)
+
+// feedSize is the most recipes listed in a feed.
+const feedSize = 50
+
+// feedAuthor is the author of feeds, which readers require but which have no single author.
+var feedAuthor = &atom.Person{Name: "My Food Stash"}
+
+// userFeedNamespace is used to derive a permanent ID for each user's feed that doesn't change when
+// the feed's token does.
+var userFeedNamespace = uuid.MustParse("0b3f5c0e-8d52-4c5e-9a3e-5f6f3a6c2e11")
+
+// feedEntryContent renders the body of a recipe's feed entry.
+var feedEntryContent = template.Must(template.New("feed-entry").Parse(
+	`{{ with .Ingredients }}<h2>Ingredients</h2><ul>{{ range . }}<li>{{ . }}</li>{{ end }}</ul>{{ end }}` +
+		`{{ with .Instructions }}<h2>Instructions</h2>{{ range . }}<p>{{ . }}</p>{{ end }}{{ end }}` +
+		`{{ with .Recipe.SourceName }}<p>Source: {{ . }}</p>{{ end }}` +
+		`{{ with .Recipe.SourceURL }}<p><a href="{{ . }}">{{ . }}</a></p>{{ end }}`,
+))
+
+// userFeedURL returns the URL of the recipe feed with the token.
+func userFeedURL(token string) string {
+	return "/feeds/" + token
+}
+
+// absoluteURL returns the absolute form of a path on the site the request was made to.
+func (app *application) absoluteURL(r *http.Request, path string) string {
+	scheme := "https"
+	if app.config.Insecure {
+		scheme = "http"
+	}
+
+	return scheme + "://" + r.Host + path
+}
+
+// recipeFeedEntry returns a feed entry with the content of a recipe, linking to the given path.
+func (app *application) recipeFeedEntry(r *http.Request, recipe models.Recipe, path string) (atom.Entry, error) {
+	content := struct {
+		Recipe       models.Recipe
+		Ingredients  []string
+		Instructions []string
+	}{
+		Recipe:       recipe,
+		Ingredients:  nonBlankLines(recipe.Ingredients),
+		Instructions: nonBlankLines(recipe.Instructions),
+	}
+
+	var body bytes.Buffer
+	if err := feedEntryContent.Execute(&body, content); err != nil {
+		return atom.Entry{}, err
+	}
+
+	var categories []atom.Category
+	if recipe.CategoryName.Valid {
+		categories = append(categories, atom.Category{Term: recipe.CategoryName.String})
+	}
+
+	for _, tag := range recipe.Tags {
+		categories = append(categories, atom.Category{Term: tag})
+	}
+
+	entry := atom.Entry{
+		ID:         recipe.ID.URN(),
+		Title:      recipe.Title,
+		Updated:    recipe.UpdatedAt,
+		Links:      []atom.Link{{Href: app.absoluteURL(r, path), Rel: "alternate", Type: "text/html"}},
+		Categories: categories,
+		Content:    atom.HTML(body.String()),
+	}
+
+	if !recipe.CreatedAt.IsZero() {
+		published := recipe.CreatedAt
+		entry.Published = &published
+	}
+
+	return entry, nil
+}
+
+// writeFeed writes a feed of the recipes. As with JSON responses, a 304 response is sent instead
+// if the client already has the current version of the feed.
+func (app *application) writeFeed(w http.ResponseWriter, r *http.Request, feed atom.Feed, recipes []models.Recipe, entryPath func(models.Recipe) string) {
+	feed.Author = feedAuthor
+	feed.Links = append(feed.Links, atom.Link{Href: app.absoluteURL(r, r.URL.Path), Rel: "self", Type: atom.ContentType})
+
+	lastModified := recipesLastModified(recipes...)
+	if lastModified.After(feed.Updated) {
+		feed.Updated = lastModified
+	}
+
+	for _, recipe := range recipes {
+		entry, err := app.recipeFeedEntry(r, recipe, entryPath(recipe))
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	var body bytes.Buffer
+	if err := feed.Write(&body); err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	// Entries include details, such as tags, whose changes don't bump a recipe's update time, so
+	// the feed is versioned by its content. There is no Last-Modified header for the same reason.
+	etag := contentETag(body.Bytes())
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("ETag", etag)
+
+	if notModified(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", atom.ContentType+"; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	body.WriteTo(w)
+}
+
+// userFeed is the feed of a user's most recently added and updated recipes. The token in the URL
+// is the only authentication, so that feed readers can fetch it.
+func (app *application) userFeed(w http.ResponseWriter, r *http.Request) {
+	// Unknown tokens are limited per address so that feeds can't be found by guessing.
+	if !app.allowAttempt(w, r, app.feedLimiter) {
+		return
+	}
+
+	userID, err := app.userModel.FeedOwner(r.Context(), r.PathValue("token"))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			app.recordFailedAttempt(r, app.feedLimiter)
+		}
+
+		app.modelError(w, r, err)
+		return
+	}
+
+	opts := models.RecipeListOptions{Sort: models.SortRecent, Limit: feedSize}
+	recipes, err := app.recipeModel.List(r.Context(), userID, opts)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	feed := atom.Feed{
+		ID:       uuid.NewSHA1(userFeedNamespace, []byte(userID)).URN(),
+		Title:    "My Food Stash Recipes",
+		Subtitle: "Recently added and updated recipes.",
+		Links:    []atom.Link{{Href: app.absoluteURL(r, "/recipes"), Rel: "alternate", Type: "text/html"}},
+	}
+
+	app.writeFeed(w, r, feed, recipes, func(recipe models.Recipe) string {
+		return "/recipes/" + recipe.ID.String()
+	})
+}
+
+// collectionFeed is the public feed of a shared collection's most recently added and updated
+// recipes.
+func (app *application) collectionFeed(w http.ResponseWriter, r *http.Request) {
+	collection, err := app.collectionModel.GetShared(r.Context(), r.PathValue("token"))
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	// As on the shared collection's page, the feed shows the recipes its owner can see.
+	recipes, err := app.collectionModel.Recipes(r.Context(), collection.Owner, collection.ID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	slices.SortStableFunc(recipes, func(a, b models.Recipe) int {
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	})
+	recipes = recipes[:min(len(recipes), feedSize)]
+
+	feed := atom.Feed{
+		ID:       collection.ID.URN(),
+		Title:    collection.Name,
+		Subtitle: collection.Description,
+		Updated:  collection.UpdatedAt,
+		Links:    []atom.Link{{Href: app.absoluteURL(r, collection.ShareURL()), Rel: "alternate", Type: "text/html"}},
+	}
+
+	app.writeFeed(w, r, feed, recipes, func(recipe models.Recipe) string {
+		return collection.ShareURL() + "#recipe-" + recipe.ID.String()
+	})
+}
+
+// feedTokenPost creates a new link to the user's recipe feed, replacing any existing one.
+func (app *application) feedTokenPost(w http.ResponseWriter, r *http.Request) {
+	token, err := generateToken(32)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	if err := app.userModel.SetFeedToken(r.Context(), reqUser(r), token); err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), sessionKeyNewFeedURL, userFeedURL(token))
+
+	http.Redirect(w, r, "/tokens", http.StatusSeeOther)
+}
+
+// disableFeedPost removes the link to the user's recipe feed.
+func (app *application) disableFeedPost(w http.ResponseWriter, r *http.Request) {
+	if err := app.userModel.SetFeedToken(r.Context(), reqUser(r), ""); err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/tokens", http.StatusSeeOther)
+}
+
+// nonBlankLines returns the trimmed lines of the text that are not blank.
+func nonBlankLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
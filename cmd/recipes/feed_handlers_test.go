@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/atom"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+)
+
+func decodeFeed(t *testing.T, body string) atom.Feed {
+	t.Helper()
+
+	var feed atom.Feed
+	if err := xml.Unmarshal([]byte(body), &feed); err != nil {
+		t.Fatalf("Failed to decode feed %q: %v", body, err)
+	}
+
+	return feed
+}
+
+func Test_application_userFeed(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	serverURL, err := url.Parse(server.URL)
+	assert.NilError(t, err)
+
+	t.Run("valid token", func(t *testing.T) {
+		status, headers, body := server.get(t, "/feeds/"+mock.ValidFeedToken)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "application/atom+xml; charset=utf-8", headers.Get("Content-Type"))
+
+		opts := app.recipeModel.(*mock.RecipeModel).LastListOptions
+		assert.Equal(t, models.SortRecent, opts.Sort)
+		assert.Equal(t, feedSize, opts.Limit)
+
+		feed := decodeFeed(t, body)
+		assert.Equal(t, 1, len(feed.Entries))
+		assert.Equal(t, mock.Recipe.UpdatedAt, feed.Updated)
+
+		entry := feed.Entries[0]
+		assert.Equal(t, mock.Recipe.Title, entry.Title)
+		assert.Equal(t, "urn:uuid:"+mock.Recipe.ID.String(), entry.ID)
+		assert.Equal(t, "https://"+serverURL.Host+"/recipes/"+mock.Recipe.ID.String(), entry.Links[0].Href)
+		assert.StringContains(t, entry.Content.Body, "<li>2 cups milk</li>")
+		assert.StringContains(t, entry.Content.Body, "<p>Mix it all together.</p>")
+		assert.Equal(t, "breakfast", entry.Categories[0].Term)
+
+		status, _, _ = server.getAccepting(t, "/feeds/"+mock.ValidFeedToken, atom.ContentType, headers.Get("ETag"))
+		assert.Equal(t, http.StatusNotModified, status)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		status, _, _ := server.get(t, "/feeds/invalid-token")
+
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func Test_application_collectionFeed(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	t.Run("shared", func(t *testing.T) {
+		status, headers, body := server.get(t, "/c/"+mock.ValidCollectionShareToken+"/feed")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "application/atom+xml; charset=utf-8", headers.Get("Content-Type"))
+
+		feed := decodeFeed(t, body)
+		assert.Equal(t, mock.Collection.Name, feed.Title)
+		assert.Equal(t, mock.Collection.Description, feed.Subtitle)
+		assert.Equal(t, "urn:uuid:"+mock.Collection.ID.String(), feed.ID)
+		assert.Equal(t, 2, len(feed.Entries))
+		assert.StringContains(t, feed.Entries[0].Links[0].Href, "/c/"+mock.ValidCollectionShareToken+"#recipe-")
+	})
+
+	t.Run("not shared", func(t *testing.T) {
+		status, _, _ := server.get(t, "/c/invalid-token/feed")
+
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("linked from shared page", func(t *testing.T) {
+		_, _, body := server.get(t, "/c/"+mock.ValidCollectionShareToken)
+
+		assert.StringContains(t, body, `type="application/atom+xml"`)
+		assert.StringContains(t, body, `href="/c/`+mock.ValidCollectionShareToken+`/feed"`)
+	})
+}
+
+func Test_application_feedTokenPost(t *testing.T) {
+	testCases := []struct {
+		name      string
+		path      string
+		wantToken bool
+	}{
+		{name: "create", path: "/tokens/feed", wantToken: true},
+		{name: "disable", path: "/tokens/feed/disable"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			server := newTestServer(t, app)
+			server.authenticate(t, mock.TestUserNormal)
+
+			_, _, page := server.get(t, "/tokens")
+			assert.StringContains(t, page, "Your feed is enabled.")
+
+			form := url.Values{}
+			form.Add("csrf_token", extractCSRFToken(t, page))
+
+			status, headers, _ := server.postForm(t, tt.path, form)
+
+			assert.Equal(t, http.StatusSeeOther, status)
+			assertRedirects(t, headers, "/tokens")
+
+			token := app.userModel.(*mock.UserModel).LastFeedToken
+			if token == nil {
+				t.Fatal("Expected the feed token to be set")
+			}
+
+			if tt.wantToken && len(*token) < 32 {
+				t.Errorf("Expected a random token; got %q", *token)
+			}
+
+			// The new feed's URL is shown once, since only a hash of its token is kept.
+			if tt.wantToken {
+				_, _, page = server.get(t, "/tokens")
+				assert.StringContains(t, page, "/feeds/"+*token)
+
+				_, _, page = server.get(t, "/tokens")
+				if strings.Contains(page, "/feeds/"+*token) {
+					t.Error("Expected the feed URL to be shown only once")
+				}
+			}
+
+			if !tt.wantToken {
+				assert.Equal(t, "", *token)
+			}
+		})
+	}
+}
+
+func Test_application_userFeed_etagFollowsContent(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	_, headers, _ := server.get(t, "/feeds/"+mock.ValidFeedToken)
+	etag := headers.Get("ETag")
+
+	// Tagging a recipe doesn't change its update time, but changes the feed.
+	original := mock.Recipe.Tags
+	mock.Recipe.Tags = append([]string{"soup"}, original...)
+	t.Cleanup(func() { mock.Recipe.Tags = original })
+
+	status, headers, body := server.getAccepting(t, "/feeds/"+mock.ValidFeedToken, atom.ContentType, etag)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.StringContains(t, body, `term="soup"`)
+	if headers.Get("ETag") == etag {
+		t.Errorf("Expected a new ETag; got %q", etag)
+	}
+}
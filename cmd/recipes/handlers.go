@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 
+	"github.com/cdriehuys/recipes/internal/dietary"
 	"github.com/cdriehuys/recipes/internal/models"
 	"github.com/cdriehuys/recipes/internal/validation"
 	"github.com/google/uuid"
@@ -174,14 +175,39 @@ func (app *application) logout(w http.ResponseWriter, r *http.Request) {
 
 func (app *application) listRecipes(w http.ResponseWriter, r *http.Request) {
 	userID := reqUser(r)
+	asJSON := wantsJSON(r)
 
-	recipes, err := app.recipeModel.List(r.Context(), userID)
+	w.Header().Add("Vary", "Accept")
+
+	opts := recipeListOptions(r.URL.Query())
+
+	recipes, err := app.recipeModel.List(r.Context(), userID, opts)
 	if err != nil {
-		app.serverError(w, r, err)
+		if asJSON {
+			app.apiServerError(w, r, err)
+		} else {
+			app.serverError(w, r, err)
+		}
+
+		return
+	}
+
+	if asJSON {
+		data := make([]apiRecipe, 0, len(recipes))
+		for _, recipe := range recipes {
+			data = append(data, newAPIRecipe(recipe))
+		}
+
+		app.writeCachableJSON(w, r, apiRecipeList{Data: data})
 		return
 	}
 
 	data := app.newTemplateData(r)
+	data.Allergens = dietary.Allergens
+	data.BulkActions = bulkActions
+	data.Diets = dietary.Diets
+	data.MaxTotalMinutes = models.MaxTotalMinutes
+	data.ListOptions = opts
 	data.Recipes = recipes
 
 	app.render(w, r, http.StatusOK, "recipe-list", data)
@@ -189,25 +215,40 @@ func (app *application) listRecipes(w http.ResponseWriter, r *http.Request) {
 
 func (app *application) getRecipe(w http.ResponseWriter, r *http.Request) {
 	userID := reqUser(r)
+	asJSON := wantsJSON(r)
+
+	w.Header().Add("Vary", "Accept")
 
 	rawID := r.PathValue("recipeID")
 	id, err := uuid.Parse(rawID)
 	if err != nil {
 		app.logger.DebugContext(r.Context(), "Received invalid recipe ID", "id", rawID, "error", err)
-		w.WriteHeader(http.StatusNotFound)
+		if asJSON {
+			app.apiClientError(w, r, http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+
 		return
 	}
 
 	recipe, err := app.recipeModel.GetByID(r.Context(), userID, id)
 	if err != nil {
-		app.serverError(w, r, err)
+		if asJSON {
+			app.apiModelError(w, r, err)
+		} else {
+			app.modelError(w, r, err)
+		}
+
 		return
 	}
 
-	data := app.newTemplateData(r)
-	data.Recipe = recipe
+	if asJSON {
+		app.writeCachableJSON(w, r, newAPIRecipe(recipe))
+		return
+	}
 
-	app.render(w, r, http.StatusOK, "recipe", data)
+	app.renderRecipe(w, r, http.StatusOK, userID, recipe, newRatingForm(recipe))
 }
 
 func (app *application) editRecipe(w http.ResponseWriter, r *http.Request) {
@@ -223,17 +264,22 @@ func (app *application) editRecipe(w http.ResponseWriter, r *http.Request) {
 
 	recipe, err := app.recipeModel.GetByID(r.Context(), userID, id)
 	if err != nil {
-		app.serverError(w, r, err)
+		app.modelError(w, r, err)
 		return
 	}
 
-	form := RecipeForm{
-		Title:        recipe.Title,
-		Instructions: recipe.Instructions,
+	form, err := app.resumeDraft(r, userID, &recipe.ID, recipeFormFor(recipe))
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	data, err := app.recipeFormData(r, userID, &form)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
 	}
 
-	data := app.newTemplateData(r)
-	data.Form = &form
 	data.Recipe = recipe
 
 	app.render(w, r, http.StatusOK, "edit-recipe", data)
@@ -250,35 +296,39 @@ func (app *application) editRecipePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	form := RecipeForm{
-		Title:        r.PostFormValue("title"),
-		Instructions: r.PostFormValue("instructions"),
-	}
+	form := newRecipeForm(r)
 	form.Validate()
 
+	data, err := app.recipeFormData(r, userID, &form)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	form.CheckField(
+		canAddToHousehold(data.Households, form.Household),
+		"household",
+		"You may not move recipes to this household.",
+	)
+
 	if !form.IsValid() {
 		recipe, err := app.recipeModel.GetByID(r.Context(), userID, id)
 		if err != nil {
-			app.serverError(w, r, err)
+			app.modelError(w, r, err)
 			return
 		}
 
-		data := app.newTemplateData(r)
-		data.Form = &form
 		data.Recipe = recipe
 
 		app.render(w, r, http.StatusOK, "edit-recipe", data)
 		return
 	}
 
-	recipe := models.Recipe{
-		ID:           id,
-		Owner:        userID,
-		Title:        form.Title,
-		Instructions: form.Instructions,
-	}
-	if err := app.recipeModel.Update(r.Context(), recipe); err != nil {
-		if errors.Is(err, models.ErrNotFound) {
+	recipe := form.recipe(id)
+	if err := app.recipeModel.Update(r.Context(), userID, recipe); err != nil {
+		if errors.Is(err, models.ErrConflict) {
+			app.renderRecipeConflict(w, r, userID, id, data, &form)
+		} else if errors.Is(err, models.ErrNotFound) {
 			app.clientError(w, http.StatusNotFound)
 		} else {
 			app.serverError(w, r, err)
@@ -286,6 +336,9 @@ func (app *application) editRecipePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	app.discardDraft(r, userID, &form)
+	app.sendRecipeEvents(r, userID, models.EventRecipeUpdated, id)
+
 	recipeURL, err := url.JoinPath("/recipes", id.String())
 	if err != nil {
 		app.serverError(w, r, err)
@@ -306,10 +359,37 @@ func (app *application) deleteRecipePost(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	recipe, err := app.recipeModel.GetByID(r.Context(), userID, id)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	// Recipes used by other recipes are only deleted once the user confirms they understand the
+	// other recipes will lose their ingredients.
+	if r.PostFormValue("confirm") != "true" {
+		referencedBy, err := app.recipeModel.ReferencedBy(r.Context(), userID, recipe)
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+
+		if len(referencedBy) > 0 {
+			data := app.newTemplateData(r)
+			data.Recipe = recipe
+			data.ReferencedBy = referencedBy
+
+			app.render(w, r, http.StatusConflict, "delete-recipe", data)
+			return
+		}
+	}
+
 	if err := app.recipeModel.Delete(r.Context(), userID, id); err != nil {
-		app.serverError(w, r, err)
+		app.modelError(w, r, err)
 		return
 	}
 
+	app.sendRecipesDeleted(r, recipe)
+
 	http.Redirect(w, r, "/recipes", http.StatusSeeOther)
 }
@@ -2,9 +2,17 @@ package main
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
 	"runtime/debug"
+	"strconv"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // serverError logs an error-level message including the details of the request that caused the
@@ -31,6 +39,17 @@ func (app *application) clientError(w http.ResponseWriter, status int) {
 	http.Error(w, http.StatusText(status), status)
 }
 
+// modelError sends a 404 response if the error indicates the requested object does not exist, and
+// treats any other error as a server error.
+func (app *application) modelError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, models.ErrNotFound) {
+		app.clientError(w, http.StatusNotFound)
+		return
+	}
+
+	app.serverError(w, r, err)
+}
+
 // render executes a template and writes it as the response.
 func (app *application) render(w http.ResponseWriter, r *http.Request, status int, page string, data templateData) {
 	buf := new(bytes.Buffer)
@@ -45,3 +64,92 @@ func (app *application) render(w http.ResponseWriter, r *http.Request, status in
 	w.WriteHeader(status)
 	buf.WriteTo(w)
 }
+
+// generateToken returns a random, URL-safe token containing the provided number of bytes of
+// entropy.
+func generateToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// uuidPathValue parses the named path value of the request as a UUID. If the value is not a valid
+// UUID, a 404 response is written and the returned boolean is false.
+func (app *application) uuidPathValue(w http.ResponseWriter, r *http.Request, name string) (uuid.UUID, bool) {
+	raw := r.PathValue(name)
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		app.logger.DebugContext(r.Context(), "Received invalid UUID path value.", "name", name, "value", raw, "error", err)
+		app.clientError(w, http.StatusNotFound)
+		return uuid.UUID{}, false
+	}
+
+	return id, true
+}
+
+// optionalUUID parses a possibly blank UUID. Blank or invalid values produce a nil result, so the
+// value should be validated before it is parsed.
+func optionalUUID(value string) *uuid.UUID {
+	if value == "" {
+		return nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil
+	}
+
+	return &id
+}
+
+// optionalUUIDString returns the string representation of a possibly nil UUID.
+func optionalUUIDString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+
+	return id.String()
+}
+
+// optionalInt2 parses a possibly blank whole number. Blank or invalid values produce a null result,
+// so the value should be validated before it is parsed.
+func optionalInt2(value string) pgtype.Int2 {
+	i, err := strconv.ParseInt(value, 10, 16)
+	if err != nil {
+		return pgtype.Int2{}
+	}
+
+	return pgtype.Int2{Int16: int16(i), Valid: true}
+}
+
+// optionalInt2String returns the string representation of a possibly null whole number.
+func optionalInt2String(value pgtype.Int2) string {
+	if !value.Valid {
+		return ""
+	}
+
+	return strconv.Itoa(int(value.Int16))
+}
+
+// optionalMinutes parses a possibly blank whole number of minutes. Blank or invalid values produce
+// a null result, so the value should be validated before it is parsed.
+func optionalMinutes(value string) models.Duration {
+	minutes, err := strconv.Atoi(value)
+	if err != nil {
+		return models.Duration{}
+	}
+
+	return models.DurationFromMinutes(minutes)
+}
+
+// optionalMinutesString returns the number of minutes in a possibly null duration.
+func optionalMinutesString(value models.Duration) string {
+	if !value.Valid {
+		return ""
+	}
+
+	return strconv.Itoa(value.Minutes())
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/validation"
+	"github.com/google/uuid"
+)
+
+// invitationLifetime is the duration an invitation to a household remains usable.
+const invitationLifetime = 7 * 24 * time.Hour
+
+type householdForm struct {
+	Name string
+	validation.Validator
+}
+
+func (form *householdForm) Validate() {
+	form.CheckField(validation.NotBlank(form.Name), "name", "This field is required.")
+	form.CheckField(validation.MaxLength(form.Name, 50), "name", "This field may not contain more than 50 characters.")
+}
+
+// householdRoleForm is used both to invite new members and to change the role of existing ones.
+type householdRoleForm struct {
+	Role string
+	validation.Validator
+}
+
+func (form *householdRoleForm) Validate() {
+	form.CheckField(
+		validation.PermittedValue(models.HouseholdRole(form.Role), models.HouseholdRoles...),
+		"role",
+		"This field must be one of the provided options.",
+	)
+}
+
+// editableHouseholds returns the households the user may add recipes and categories to.
+func (app *application) editableHouseholds(r *http.Request, userID string) ([]models.Household, error) {
+	households, err := app.householdModel.ListForUser(r.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	editable := make([]models.Household, 0, len(households))
+	for _, household := range households {
+		if household.Role.CanEdit() {
+			editable = append(editable, household)
+		}
+	}
+
+	return editable, nil
+}
+
+// canAddToHousehold reports if the household with the provided ID is one of the given households.
+// A blank ID indicates the object belongs to the user alone, which is always allowed.
+func canAddToHousehold(households []models.Household, id string) bool {
+	if id == "" {
+		return true
+	}
+
+	for _, household := range households {
+		if household.ID.String() == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (app *application) listHouseholds(w http.ResponseWriter, r *http.Request) {
+	app.renderHouseholds(w, r, http.StatusOK, reqUser(r), &householdForm{})
+}
+
+func (app *application) listHouseholdsPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	form := householdForm{
+		Name: r.PostFormValue("name"),
+	}
+	form.Validate()
+
+	if !form.IsValid() {
+		app.renderHouseholds(w, r, http.StatusUnprocessableEntity, userID, &form)
+		return
+	}
+
+	household := models.Household{
+		ID:   uuid.New(),
+		Name: form.Name,
+	}
+	if err := app.householdModel.Create(r.Context(), userID, household); err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, household.URL(), http.StatusSeeOther)
+}
+
+func (app *application) getHousehold(w http.ResponseWriter, r *http.Request) {
+	householdID, ok := app.uuidPathValue(w, r, "householdID")
+	if !ok {
+		return
+	}
+
+	app.renderHousehold(w, r, http.StatusOK, reqUser(r), householdID, &householdRoleForm{})
+}
+
+func (app *application) householdInvitationPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	householdID, ok := app.uuidPathValue(w, r, "householdID")
+	if !ok {
+		return
+	}
+
+	form := householdRoleForm{
+		Role: r.PostFormValue("role"),
+	}
+	form.Validate()
+
+	if !form.IsValid() {
+		app.renderHousehold(w, r, http.StatusUnprocessableEntity, userID, householdID, &form)
+		return
+	}
+
+	token, err := generateToken(32)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	invitation := models.HouseholdInvitation{
+		ID:        uuid.New(),
+		Household: householdID,
+		Token:     token,
+		Role:      models.HouseholdRole(form.Role),
+		ExpiresAt: time.Now().Add(invitationLifetime),
+	}
+	if err := app.householdModel.CreateInvitation(r.Context(), userID, invitation); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/households/"+householdID.String(), http.StatusSeeOther)
+}
+
+func (app *application) householdMemberRolePost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	householdID, ok := app.uuidPathValue(w, r, "householdID")
+	if !ok {
+		return
+	}
+
+	form := householdRoleForm{
+		Role: r.PostFormValue("role"),
+	}
+	form.Validate()
+
+	if !form.IsValid() {
+		app.renderHousehold(w, r, http.StatusUnprocessableEntity, userID, householdID, &form)
+		return
+	}
+
+	member := r.PathValue("memberID")
+	err := app.householdModel.SetMemberRole(r.Context(), userID, householdID, member, models.HouseholdRole(form.Role))
+	if err != nil {
+		if errors.Is(err, models.ErrLastAdmin) {
+			form.AddNonFieldError("A household must have at least one admin.")
+			app.renderHousehold(w, r, http.StatusConflict, userID, householdID, &form)
+		} else {
+			app.modelError(w, r, err)
+		}
+		return
+	}
+
+	http.Redirect(w, r, "/households/"+householdID.String(), http.StatusSeeOther)
+}
+
+func (app *application) householdMemberRemovePost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	householdID, ok := app.uuidPathValue(w, r, "householdID")
+	if !ok {
+		return
+	}
+
+	member := r.PathValue("memberID")
+	if err := app.householdModel.RemoveMember(r.Context(), userID, householdID, member); err != nil {
+		if errors.Is(err, models.ErrLastAdmin) {
+			form := householdRoleForm{}
+			form.AddNonFieldError("A household must have at least one admin.")
+			app.renderHousehold(w, r, http.StatusConflict, userID, householdID, &form)
+		} else {
+			app.modelError(w, r, err)
+		}
+		return
+	}
+
+	// Members who leave a household can no longer view it.
+	if member == userID {
+		http.Redirect(w, r, "/households", http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, "/households/"+householdID.String(), http.StatusSeeOther)
+}
+
+func (app *application) householdInvitation(w http.ResponseWriter, r *http.Request) {
+	invitation, err := app.householdModel.GetInvitation(r.Context(), r.PathValue("token"))
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Invitation = invitation
+
+	app.render(w, r, http.StatusOK, "household-invitation", data)
+}
+
+func (app *application) acceptHouseholdInvitationPost(w http.ResponseWriter, r *http.Request) {
+	householdID, err := app.householdModel.AcceptInvitation(r.Context(), reqUser(r), r.PathValue("token"))
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/households/"+householdID.String(), http.StatusSeeOther)
+}
+
+func (app *application) renderHouseholds(w http.ResponseWriter, r *http.Request, status int, userID string, form *householdForm) {
+	households, err := app.householdModel.ListForUser(r.Context(), userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = form
+	data.Households = households
+
+	app.render(w, r, status, "households", data)
+}
+
+func (app *application) renderHousehold(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	userID string,
+	householdID uuid.UUID,
+	form *householdRoleForm,
+) {
+	household, err := app.householdModel.Get(r.Context(), userID, householdID)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	members, err := app.householdModel.ListMembers(r.Context(), userID, householdID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = form
+	data.Household = household
+	data.HouseholdMembers = members
+
+	if household.Role.CanManage() {
+		data.Invitations, err = app.householdModel.ListInvitations(r.Context(), userID, householdID)
+		if err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	app.render(w, r, status, "household", data)
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_application_listHouseholds(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, "/households")
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, "/households")
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, body := server.get(t, "/households")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, mock.Household.Name)
+		assert.StringContains(t, body, mock.ViewableHousehold.Name)
+	})
+}
+
+func Test_application_listHouseholdsPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, formResponse := server.get(t, "/households")
+	csrfToken := extractCSRFToken(t, formResponse)
+
+	testCases := []struct {
+		name                  string
+		householdName         string
+		wantStatus            int
+		wantValidationMessage string
+	}{
+		{
+			name:          "valid",
+			householdName: "Smiths",
+			wantStatus:    http.StatusSeeOther,
+		},
+		{
+			name:                  "missing name",
+			householdName:         "",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field is required.",
+		},
+		{
+			name:                  "name too long",
+			householdName:         strings.Repeat("a", 51),
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field may not contain more than 50 characters.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("name", tt.householdName)
+
+			status, headers, body := server.postForm(t, "/households", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+				return
+			}
+
+			created := app.householdModel.(*mock.HouseholdModel).LastCreatedHousehold
+			assert.Equal(t, tt.householdName, created.Name)
+			assertRedirects(t, headers, created.URL())
+		})
+	}
+}
+
+func Test_application_getHousehold(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	testCases := []struct {
+		name             string
+		id               string
+		wantStatus       int
+		wantInviteOption bool
+	}{
+		{
+			name:             "admin",
+			id:               mock.Household.ID.String(),
+			wantStatus:       http.StatusOK,
+			wantInviteOption: true,
+		},
+		{
+			name:       "viewer",
+			id:         mock.ViewableHousehold.ID.String(),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "not a member",
+			id:         uuid.NewString(),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "invalid ID",
+			id:         "foo",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, body := server.get(t, "/households/"+tt.id)
+
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantInviteOption, strings.Contains(body, "Create Invitation"))
+		})
+	}
+}
+
+func Test_application_householdInvitationPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	householdURL := mock.Household.URL()
+
+	_, _, formResponse := server.get(t, householdURL)
+	csrfToken := extractCSRFToken(t, formResponse)
+
+	testCases := []struct {
+		name                  string
+		role                  string
+		wantStatus            int
+		wantValidationMessage string
+	}{
+		{
+			name:       "viewer",
+			role:       "viewer",
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:       "admin",
+			role:       "admin",
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:                  "invalid role",
+			role:                  "owner",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be one of the provided options.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("role", tt.role)
+
+			status, headers, body := server.postForm(t, householdURL+"/invitations", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+				return
+			}
+
+			created := app.householdModel.(*mock.HouseholdModel).LastInvitation
+			assert.Equal(t, mock.Household.ID, created.Household)
+			assert.Equal(t, models.HouseholdRole(tt.role), created.Role)
+			assertRedirects(t, headers, householdURL)
+		})
+	}
+}
+
+func Test_application_householdMemberRolePost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	householdURL := mock.Household.URL()
+
+	_, _, formResponse := server.get(t, householdURL)
+	csrfToken := extractCSRFToken(t, formResponse)
+
+	testCases := []struct {
+		name        string
+		member      string
+		role        string
+		wantStatus  int
+		wantMessage string
+	}{
+		{
+			name:       "change other member",
+			member:     "other-user",
+			role:       "editor",
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:        "demote last admin",
+			member:      mock.TestUserNormal,
+			role:        "viewer",
+			wantStatus:  http.StatusConflict,
+			wantMessage: "A household must have at least one admin.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("role", tt.role)
+
+			status, _, body := server.postForm(t, householdURL+"/members/"+tt.member+"/role", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantMessage != "" {
+				assert.StringContains(t, body, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func Test_application_householdMemberRemovePost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	householdURL := mock.Household.URL()
+
+	_, _, formResponse := server.get(t, householdURL)
+	csrfToken := extractCSRFToken(t, formResponse)
+
+	testCases := []struct {
+		name         string
+		member       string
+		wantRedirect string
+	}{
+		{
+			name:         "remove other member",
+			member:       "other-user",
+			wantRedirect: householdURL,
+		},
+		{
+			name:         "leave household",
+			member:       mock.TestUserNormal,
+			wantRedirect: "/households",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+
+			status, headers, _ := server.postForm(t, householdURL+"/members/"+tt.member+"/remove", form)
+
+			assert.Equal(t, http.StatusSeeOther, status)
+			assert.Equal(t, tt.member, app.householdModel.(*mock.HouseholdModel).LastRemovedMember)
+			assertRedirects(t, headers, tt.wantRedirect)
+		})
+	}
+}
+
+func Test_application_householdInvitation(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	invitationURL := "/invitations/" + mock.ValidInvitationToken
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, invitationURL)
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, invitationURL)
+	})
+
+	server.authenticate(t, mock.TestUserNormal)
+
+	t.Run("unknown token", func(t *testing.T) {
+		status, _, _ := server.get(t, "/invitations/foo")
+
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("accept", func(t *testing.T) {
+		status, _, body := server.get(t, invitationURL)
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, mock.Household.Name)
+
+		form := url.Values{}
+		form.Add("csrf_token", extractCSRFToken(t, body))
+
+		status, headers, _ := server.postForm(t, invitationURL+"/accept", form)
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assert.Equal(t, mock.ValidInvitationToken, app.householdModel.(*mock.HouseholdModel).AcceptedInvitation)
+		assertRedirects(t, headers, mock.Household.URL())
+	})
+}
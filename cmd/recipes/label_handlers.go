@@ -0,0 +1,144 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/cdriehuys/recipes/internal/dietary"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/validation"
+)
+
+// labelOverride is a row of the form used to correct a recipe's allergen and diet labels.
+type labelOverride struct {
+	Name  string
+	Label string
+	// Detected reports if the label was detected from the recipe's ingredients.
+	Detected bool
+	// Value is "yes" or "no" if the label was set by hand, or blank to use the detected value.
+	Value string
+}
+
+// newLabelOverrides lists each allergen and diet label along with its detected and manual values.
+func newLabelOverrides(recipe models.Recipe, form *labelsForm) []labelOverride {
+	detected := dietary.Detect(recipe.IngredientList())
+
+	rows := make([]labelOverride, 0, len(dietary.Allergens)+len(dietary.Diets))
+	for _, allergen := range dietary.Allergens {
+		rows = append(rows, labelOverride{
+			Name:     string(allergen),
+			Label:    "Contains " + allergen.Label(),
+			Detected: detected.Allergens.Has(allergen),
+			Value:    form.Values[string(allergen)],
+		})
+	}
+
+	for _, diet := range dietary.Diets {
+		rows = append(rows, labelOverride{
+			Name:     string(diet),
+			Label:    diet.Label(),
+			Detected: detected.Suits(diet),
+			Value:    form.Values[string(diet)],
+		})
+	}
+
+	return rows
+}
+
+type labelsForm struct {
+	// Values holds "yes", "no", or blank for each label, keyed by the label's name.
+	Values map[string]string
+	validation.Validator
+}
+
+func newLabelsForm(overrides dietary.Overrides) *labelsForm {
+	form := labelsForm{Values: make(map[string]string)}
+	for label, present := range overrides {
+		if present {
+			form.Values[label] = "yes"
+		} else {
+			form.Values[label] = "no"
+		}
+	}
+
+	return &form
+}
+
+func (form *labelsForm) Validate() {
+	for label, value := range form.Values {
+		form.CheckField(validation.PermittedValue(value, "", "yes", "no"), label, "Choose an option from the list.")
+	}
+}
+
+// overrides returns the validated label overrides.
+func (form *labelsForm) overrides() dietary.Overrides {
+	overrides := make(dietary.Overrides)
+	for label, value := range form.Values {
+		if value != "" {
+			overrides[label] = value == "yes"
+		}
+	}
+
+	return overrides
+}
+
+func (app *application) recipeLabels(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	recipe, err := app.recipeModel.GetByID(r.Context(), userID, recipeID)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	app.renderRecipeLabels(w, r, http.StatusOK, recipe, newLabelsForm(recipe.LabelOverrides))
+}
+
+func (app *application) recipeLabelsPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	form := labelsForm{Values: make(map[string]string)}
+	for _, allergen := range dietary.Allergens {
+		form.Values[string(allergen)] = r.PostFormValue(string(allergen))
+	}
+	for _, diet := range dietary.Diets {
+		form.Values[string(diet)] = r.PostFormValue(string(diet))
+	}
+	form.Validate()
+
+	if !form.IsValid() {
+		recipe, err := app.recipeModel.GetByID(r.Context(), userID, recipeID)
+		if err != nil {
+			app.modelError(w, r, err)
+			return
+		}
+
+		app.renderRecipeLabels(w, r, http.StatusUnprocessableEntity, recipe, &form)
+		return
+	}
+
+	if err := app.labelModel.SetOverrides(r.Context(), userID, recipeID, form.overrides()); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/recipes/"+recipeID.String(), http.StatusSeeOther)
+}
+
+func (app *application) renderRecipeLabels(w http.ResponseWriter, r *http.Request, status int, recipe models.Recipe, form *labelsForm) {
+	data := app.newTemplateData(r)
+	data.Form = form
+	data.LabelOverrides = newLabelOverrides(recipe, form)
+	data.Recipe = recipe
+
+	app.render(w, r, status, "recipe-labels", data)
+}
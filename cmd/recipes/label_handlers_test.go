@@ -0,0 +1,129 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_application_getRecipe_labels(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	status, _, body := server.get(t, "/recipes/"+uuid.NewString())
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.StringContains(t, body, "Contains Dairy")
+	assert.StringContains(t, body, "Contains Egg")
+	assert.StringContains(t, body, "Vegetarian")
+}
+
+func Test_application_listRecipes_labels(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	status, _, body := server.get(t, "/recipes?exclude=nuts&diet=vegetarian")
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.StringContains(t, body, "Contains Dairy")
+	assert.StringContains(t, body, `value="nuts" checked`)
+	// Changing the sort keeps the filters.
+	assert.StringContains(t, body, `href="/recipes?diet=vegetarian&amp;exclude=nuts&amp;sort=rating"`)
+}
+
+func Test_application_recipeLabels(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	labelsURL := "/recipes/" + uuid.NewString() + "/labels"
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, labelsURL)
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, labelsURL)
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, body := server.get(t, labelsURL)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, "Contains Dairy")
+		assert.StringContains(t, body, "Automatic (yes)")
+		assert.StringContains(t, body, "Automatic (no)")
+	})
+}
+
+func Test_application_recipeLabelsPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	recipeID := uuid.New()
+	labelsURL := "/recipes/" + recipeID.String() + "/labels"
+
+	_, _, page := server.get(t, labelsURL)
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name                  string
+		values                map[string]string
+		wantStatus            int
+		wantValidationMessage string
+		wantOverrides         map[string]bool
+	}{
+		{
+			name:          "overrides",
+			values:        map[string]string{"nuts": "yes", "dairy": "no", "vegan": ""},
+			wantStatus:    http.StatusSeeOther,
+			wantOverrides: map[string]bool{"nuts": true, "dairy": false},
+		},
+		{
+			name:          "automatic",
+			values:        map[string]string{},
+			wantStatus:    http.StatusSeeOther,
+			wantOverrides: map[string]bool{},
+		},
+		{
+			name:                  "invalid value",
+			values:                map[string]string{"gluten": "maybe"},
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "Choose an option from the list.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			for label, value := range tt.values {
+				form.Add(label, value)
+			}
+
+			status, headers, body := server.postForm(t, labelsURL, form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+			} else {
+				assertRedirects(t, headers, "/recipes/"+recipeID.String())
+
+				model := app.labelModel.(*mock.LabelModel)
+				assert.Equal(t, recipeID, model.LastSetRecipe)
+				assert.Equal(t, len(tt.wantOverrides), len(model.LastSetOverrides))
+				for label, present := range tt.wantOverrides {
+					assert.Equal(t, present, model.LastSetOverrides[label])
+				}
+			}
+		})
+	}
+}
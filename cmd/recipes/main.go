@@ -16,6 +16,7 @@ import (
 
 	"github.com/cdriehuys/recipes"
 	"github.com/cdriehuys/recipes/internal/config"
+	"github.com/cdriehuys/recipes/internal/ratelimit"
 	"github.com/cdriehuys/recipes/migrations"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -34,6 +35,16 @@ func serve(ctx context.Context, app application) error {
 		BaseContext: func(net.Listener) context.Context { return ctx },
 	}
 
+	if app.webhookDispatcher != nil {
+		go app.webhookDispatcher.Run(ctx)
+	}
+
+	for _, limiter := range []*ratelimit.Limiter{app.authLimiter, app.apiLimiter, app.pageLimiter, app.tokenLimiter, app.feedLimiter} {
+		if limiter != nil {
+			go limiter.Run(ctx)
+		}
+	}
+
 	serverErrors := make(chan error)
 
 	var serverWG sync.WaitGroup
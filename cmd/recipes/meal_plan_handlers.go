@@ -0,0 +1,220 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/validation"
+	"github.com/google/uuid"
+)
+
+// mealPlanWeek is a week of planned items grouped by day and then by meal slot.
+type mealPlanWeek struct {
+	Start    time.Time
+	Previous time.Time
+	Next     time.Time
+	Days     []mealPlanDay
+	Slots    []models.MealSlot
+}
+
+type mealPlanDay struct {
+	Date  time.Time
+	Meals []mealPlanMeal
+}
+
+type mealPlanMeal struct {
+	Slot  models.MealSlot
+	Items []models.MealPlanItem
+}
+
+// weekStart returns the Monday of the week containing the provided time, truncated to a date.
+func weekStart(t time.Time) time.Time {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(date.Weekday()) + 6) % 7
+
+	return date.AddDate(0, 0, -offset)
+}
+
+// parseWeek returns the start of the week containing the date in the provided value. Blank or
+// invalid values refer to the current week.
+func parseWeek(value string) time.Time {
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return weekStart(time.Now())
+	}
+
+	return weekStart(date)
+}
+
+// mealPlanURL returns the URL of the planner showing the week starting on the provided date.
+func mealPlanURL(week time.Time) string {
+	query := url.Values{}
+	query.Set("week", week.Format(time.DateOnly))
+
+	return "/meal-plan?" + query.Encode()
+}
+
+// newMealPlanWeek arranges the items planned during the week starting on the provided date.
+func newMealPlanWeek(start time.Time, items []models.MealPlanItem) mealPlanWeek {
+	week := mealPlanWeek{
+		Start:    start,
+		Previous: start.AddDate(0, 0, -7),
+		Next:     start.AddDate(0, 0, 7),
+		Days:     make([]mealPlanDay, 7),
+		Slots:    models.MealSlots,
+	}
+
+	for i := range week.Days {
+		week.Days[i].Date = start.AddDate(0, 0, i)
+		week.Days[i].Meals = make([]mealPlanMeal, len(models.MealSlots))
+		for j, slot := range models.MealSlots {
+			week.Days[i].Meals[j].Slot = slot
+		}
+	}
+
+	for _, item := range items {
+		day := int(item.PlannedOn.Sub(start).Hours() / 24)
+		if day < 0 || day >= len(week.Days) {
+			continue
+		}
+
+		for j := range week.Days[day].Meals {
+			meal := &week.Days[day].Meals[j]
+			if meal.Slot == item.Slot {
+				meal.Items = append(meal.Items, item)
+			}
+		}
+	}
+
+	return week
+}
+
+type mealPlanItemForm struct {
+	PlannedOn string
+	Slot      string
+	Recipe    string
+	Note      string
+	validation.Validator
+}
+
+func (form *mealPlanItemForm) Validate() {
+	form.CheckField(validation.ValidDate(form.PlannedOn), "plannedOn", "This field must be a valid date.")
+	form.CheckField(
+		validation.PermittedValue(models.MealSlot(form.Slot), models.MealSlots...),
+		"slot",
+		"This field must be one of the provided options.",
+	)
+	form.CheckField(validation.UUIDOrBlank(form.Recipe), "recipe", "This field must be a valid recipe ID.")
+	form.CheckField(validation.MaxLength(form.Note, 200), "note", "This field may not contain more than 200 characters.")
+
+	if form.Recipe == "" {
+		form.CheckField(validation.NotBlank(form.Note), "note", "Choose a recipe or describe the item.")
+	}
+}
+
+func (app *application) mealPlan(w http.ResponseWriter, r *http.Request) {
+	week := parseWeek(r.URL.Query().Get("week"))
+
+	form := mealPlanItemForm{
+		PlannedOn: week.Format(time.DateOnly),
+		Slot:      string(models.SlotDinner),
+	}
+
+	app.renderMealPlan(w, r, http.StatusOK, reqUser(r), week, &form)
+}
+
+func (app *application) mealPlanPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	form := mealPlanItemForm{
+		PlannedOn: r.PostFormValue("plannedOn"),
+		Slot:      r.PostFormValue("slot"),
+		Recipe:    r.PostFormValue("recipe"),
+		Note:      r.PostFormValue("note"),
+	}
+	form.Validate()
+
+	week := parseWeek(form.PlannedOn)
+
+	if !form.IsValid() {
+		app.renderMealPlan(w, r, http.StatusUnprocessableEntity, userID, week, &form)
+		return
+	}
+
+	plannedOn, _ := time.Parse(time.DateOnly, form.PlannedOn)
+
+	item := models.MealPlanItem{
+		ID:        uuid.New(),
+		Owner:     userID,
+		PlannedOn: plannedOn,
+		Slot:      models.MealSlot(form.Slot),
+		Recipe:    optionalUUID(form.Recipe),
+	}
+
+	// Free-text notes are only kept for items that don't reference a recipe.
+	if item.Recipe == nil {
+		item.Note = form.Note
+	}
+
+	if err := app.mealPlanModel.Add(r.Context(), item); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, mealPlanURL(week), http.StatusSeeOther)
+}
+
+func (app *application) copyMealPlanWeekPost(w http.ResponseWriter, r *http.Request) {
+	week := parseWeek(r.PostFormValue("week"))
+
+	if _, err := app.mealPlanModel.CopyWeek(r.Context(), reqUser(r), week.AddDate(0, 0, -7), week); err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, mealPlanURL(week), http.StatusSeeOther)
+}
+
+func (app *application) deleteMealPlanItemPost(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := app.uuidPathValue(w, r, "itemID")
+	if !ok {
+		return
+	}
+
+	if err := app.mealPlanModel.Delete(r.Context(), reqUser(r), itemID); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, mealPlanURL(parseWeek(r.PostFormValue("week"))), http.StatusSeeOther)
+}
+
+func (app *application) renderMealPlan(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	userID string,
+	week time.Time,
+	form *mealPlanItemForm,
+) {
+	items, err := app.mealPlanModel.ListRange(r.Context(), userID, week, week.AddDate(0, 0, 7))
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	recipes, err := app.recipeModel.List(r.Context(), userID, models.RecipeListOptions{Sort: models.SortTitle})
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = form
+	data.MealPlan = newMealPlanWeek(week, items)
+	data.Recipes = recipes
+
+	app.render(w, r, status, "meal-plan", data)
+}
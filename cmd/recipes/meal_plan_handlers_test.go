@@ -0,0 +1,235 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_weekStart(t *testing.T) {
+	testCases := []struct {
+		name string
+		time time.Time
+		want string
+	}{
+		{
+			name: "monday",
+			time: time.Date(2024, time.May, 6, 12, 30, 0, 0, time.UTC),
+			want: "2024-05-06",
+		},
+		{
+			name: "midweek",
+			time: time.Date(2024, time.May, 9, 0, 0, 0, 0, time.UTC),
+			want: "2024-05-06",
+		},
+		{
+			name: "sunday",
+			time: time.Date(2024, time.May, 12, 23, 59, 0, 0, time.UTC),
+			want: "2024-05-06",
+		},
+		{
+			name: "across month",
+			time: time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC),
+			want: "2024-05-27",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, weekStart(tt.time).Format(time.DateOnly))
+		})
+	}
+}
+
+func Test_newMealPlanWeek(t *testing.T) {
+	start := time.Date(2024, time.May, 6, 0, 0, 0, 0, time.UTC)
+	items := []models.MealPlanItem{
+		{Note: "Oatmeal", PlannedOn: start, Slot: models.SlotBreakfast},
+		{Note: "Pizza", PlannedOn: start.AddDate(0, 0, 4), Slot: models.SlotDinner},
+		{Note: "Too late", PlannedOn: start.AddDate(0, 0, 7), Slot: models.SlotDinner},
+	}
+
+	week := newMealPlanWeek(start, items)
+
+	assert.Equal(t, 7, len(week.Days))
+	assert.Equal(t, "2024-04-29", week.Previous.Format(time.DateOnly))
+	assert.Equal(t, "2024-05-13", week.Next.Format(time.DateOnly))
+	assert.Equal(t, "Oatmeal", week.Days[0].Meals[0].Items[0].Note)
+	assert.Equal(t, "Pizza", week.Days[4].Meals[2].Items[0].Note)
+
+	total := 0
+	for _, day := range week.Days {
+		for _, meal := range day.Meals {
+			total += len(meal.Items)
+		}
+	}
+	assert.Equal(t, 2, total)
+}
+
+func Test_application_mealPlan(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, "/meal-plan")
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, "/meal-plan")
+	})
+
+	server.authenticate(t, mock.TestUserNormal)
+
+	t.Run("requested week", func(t *testing.T) {
+		status, _, body := server.get(t, "/meal-plan?week=2024-05-08")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, "Week of May 6, 2024")
+		assert.StringContains(t, body, "week=2024-04-29")
+		assert.StringContains(t, body, "week=2024-05-13")
+		assert.StringContains(t, body, mock.MealPlanItem.RecipeTitle.String)
+		assert.StringContains(t, body, "serves 6")
+	})
+
+	t.Run("invalid week", func(t *testing.T) {
+		status, _, body := server.get(t, "/meal-plan?week=foo")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, "Week of "+weekStart(time.Now()).Format("January 2, 2006"))
+	})
+}
+
+func Test_application_mealPlanPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/meal-plan")
+	csrfToken := extractCSRFToken(t, page)
+
+	recipeID := uuid.New()
+
+	testCases := []struct {
+		name                  string
+		plannedOn             string
+		slot                  string
+		recipe                string
+		note                  string
+		wantStatus            int
+		wantValidationMessage string
+		wantNote              string
+	}{
+		{
+			name:       "recipe",
+			plannedOn:  "2024-05-08",
+			slot:       "dinner",
+			recipe:     recipeID.String(),
+			note:       "ignored",
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:       "free text",
+			plannedOn:  "2024-05-08",
+			slot:       "lunch",
+			note:       "Leftovers",
+			wantStatus: http.StatusSeeOther,
+			wantNote:   "Leftovers",
+		},
+		{
+			name:                  "nothing planned",
+			plannedOn:             "2024-05-08",
+			slot:                  "lunch",
+			note:                  "  ",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "Choose a recipe or describe the item.",
+		},
+		{
+			name:                  "invalid slot",
+			plannedOn:             "2024-05-08",
+			slot:                  "brunch",
+			note:                  "Waffles",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be one of the provided options.",
+		},
+		{
+			name:                  "invalid date",
+			plannedOn:             "tomorrow",
+			slot:                  "lunch",
+			note:                  "Waffles",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be a valid date.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("plannedOn", tt.plannedOn)
+			form.Add("slot", tt.slot)
+			form.Add("recipe", tt.recipe)
+			form.Add("note", tt.note)
+
+			status, headers, body := server.postForm(t, "/meal-plan", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+				return
+			}
+
+			added := app.mealPlanModel.(*mock.MealPlanModel).LastAddedItem
+			assert.Equal(t, mock.TestUserNormal, added.Owner)
+			assert.Equal(t, tt.plannedOn, added.PlannedOn.Format(time.DateOnly))
+			assert.Equal(t, models.MealSlot(tt.slot), added.Slot)
+			assert.Equal(t, tt.recipe, optionalUUIDString(added.Recipe))
+			assert.Equal(t, tt.wantNote, added.Note)
+			assertRedirects(t, headers, "/meal-plan?week=2024-05-06")
+		})
+	}
+}
+
+func Test_application_copyMealPlanWeekPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/meal-plan")
+
+	form := url.Values{}
+	form.Add("csrf_token", extractCSRFToken(t, page))
+	form.Add("week", "2024-05-06")
+
+	status, headers, _ := server.postForm(t, "/meal-plan/copy-previous-week", form)
+
+	model := app.mealPlanModel.(*mock.MealPlanModel)
+	assert.Equal(t, http.StatusSeeOther, status)
+	assert.Equal(t, "2024-04-29", model.LastCopiedFrom.Format(time.DateOnly))
+	assert.Equal(t, "2024-05-06", model.LastCopiedTo.Format(time.DateOnly))
+	assertRedirects(t, headers, "/meal-plan?week=2024-05-06")
+}
+
+func Test_application_deleteMealPlanItemPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/meal-plan")
+	itemID := uuid.New()
+
+	form := url.Values{}
+	form.Add("csrf_token", extractCSRFToken(t, page))
+	form.Add("week", "2024-05-06")
+
+	status, headers, _ := server.postForm(t, "/meal-plan/items/"+itemID.String()+"/delete", form)
+
+	assert.Equal(t, http.StatusSeeOther, status)
+	assert.Equal(t, itemID, app.mealPlanModel.(*mock.MealPlanModel).LastDeletedItem)
+	assertRedirects(t, headers, "/meal-plan?week=2024-05-06")
+}
@@ -2,11 +2,21 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"net"
 	"net/http"
+	"net/netip"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/ratelimit"
+	"github.com/justinas/alice"
 	"github.com/justinas/nosurf"
 )
 
@@ -19,6 +29,14 @@ func (app *application) noSurf(next http.Handler) http.Handler {
 		Secure:   app.config.Insecure,
 	})
 
+	// Bearer tokens are never sent automatically by the browser, so API requests authenticated by
+	// one cannot be forged by another site. Those requests are authenticated by
+	// `app.authenticateBearer` rather than the session.
+	csrfHandler.ExemptFunc(func(r *http.Request) bool {
+		_, ok := bearerToken(r)
+		return ok && strings.HasPrefix(r.URL.Path, "/api/")
+	})
+
 	return csrfHandler
 }
 
@@ -80,12 +98,71 @@ func (app *application) authenticate(next http.Handler) http.Handler {
 	})
 }
 
+// authenticateBearer authenticates requests with an "Authorization: Bearer" header using the
+// personal access token in the header. The token's owner replaces any user authenticated by the
+// session, and requests with an invalid token are rejected rather than falling back to the session.
+// Safe methods require the token to have the read scope, and all others require the write scope.
+// Invalid tokens are counted against the client's address by the token limiter, and once it is
+// limited, no tokens are checked for that address.
+func (app *application) authenticateBearer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret, ok := bearerToken(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Only failed attempts count against the client's address, so that clients sharing an
+		// address with valid tokens are limited per user instead.
+		if !app.allowAttempt(w, r, app.tokenLimiter) {
+			return
+		}
+
+		token, err := app.tokenModel.Authenticate(r.Context(), secret)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				app.recordFailedAttempt(r, app.tokenLimiter)
+
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				app.apiErrorResponse(w, r, http.StatusUnauthorized, "The access token is invalid or has been revoked.")
+			} else {
+				app.apiServerError(w, r, err)
+			}
+
+			return
+		}
+
+		scope := models.ScopeWrite
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			scope = models.ScopeRead
+		}
+
+		if !token.HasScope(scope) {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, scope))
+			app.apiErrorResponse(w, r, http.StatusForbidden, fmt.Sprintf("The access token does not have the %s scope.", scope))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), contextKeyUserID, token.Owner)
+		ctx = context.WithValue(ctx, contextKeyAccessToken, token)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func (app *application) requireAuthentication(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// If the user is not authenticated, redirect them to the login page and
 		// return from the middleware chain so that no subsequent handlers in
 		// the chain are executed.
 		if !isAuthenticated(r) {
+			// Clients asking for JSON can't follow a login redirect.
+			if wantsJSON(r) {
+				app.apiClientError(w, r, http.StatusUnauthorized)
+				return
+			}
+
 			loginParams := url.Values{}
 			loginParams.Set("next", r.URL.Path)
 
@@ -107,3 +184,145 @@ func (app *application) requireAuthentication(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// requireAPIAuthentication rejects unauthenticated API requests with a 401 response instead of
+// redirecting them to the login page.
+func (app *application) requireAPIAuthentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isAuthenticated(r) {
+			app.apiClientError(w, r, http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Add("Cache-Control", "no-store")
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimit returns middleware rejecting requests in excess of the limiter's rate with a 429
+// response. Authenticated requests are limited per user and anonymous ones per client IP address,
+// so the middleware must come after any authentication middleware in the chain. A nil limiter
+// allows every request.
+func (app *application) rateLimit(limiter *ratelimit.Limiter) alice.Constructor {
+	return app.rateLimitBy(limiter, app.rateLimitKey)
+}
+
+// allowAttempt reports if the client may attempt to use a secret, such as an access token, that
+// could otherwise be guessed. Failed attempts are recorded per client IP address with
+// recordFailedAttempt, and once the limiter's allowance is used up, a 429 response is sent and no
+// more attempts are allowed until it refills. A nil limiter allows every attempt.
+func (app *application) allowAttempt(w http.ResponseWriter, r *http.Request, limiter *ratelimit.Limiter) bool {
+	if limiter == nil {
+		return true
+	}
+
+	key := "ip:" + app.clientAddress(r)
+	if wait := limiter.Wait(key, time.Now()); wait > 0 {
+		app.tooManyRequests(w, r, key, wait)
+		return false
+	}
+
+	return true
+}
+
+// recordFailedAttempt counts a failed attempt to use a secret against the client's IP address.
+func (app *application) recordFailedAttempt(r *http.Request, limiter *ratelimit.Limiter) {
+	if limiter != nil {
+		limiter.Allow("ip:"+app.clientAddress(r), time.Now())
+	}
+}
+
+// rateLimitBy returns middleware limiting requests with the same key, as returned by the key
+// function, to the limiter's rate.
+func (app *application) rateLimitBy(limiter *ratelimit.Limiter, key func(*http.Request) string) alice.Constructor {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := key(r)
+
+			ok, wait := limiter.Allow(key, time.Now())
+			if !ok {
+				app.tooManyRequests(w, r, key, wait)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// tooManyRequests sends a 429 response to a client that has exceeded the rate limit for the key,
+// telling it to retry after the wait.
+func (app *application) tooManyRequests(w http.ResponseWriter, r *http.Request, key string, wait time.Duration) {
+	app.logger.InfoContext(r.Context(), "Rate limit exceeded.", "key", key, "uri", r.URL.RequestURI())
+
+	retryAfter := int(math.Max(1, math.Ceil(wait.Seconds())))
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+	if wantsJSON(r) || strings.HasPrefix(r.URL.Path, "/api/") {
+		app.apiClientError(w, r, http.StatusTooManyRequests)
+	} else {
+		app.clientError(w, http.StatusTooManyRequests)
+	}
+}
+
+// rateLimitKey identifies the client making a request for rate limiting.
+func (app *application) rateLimitKey(r *http.Request) string {
+	if isAuthenticated(r) {
+		return "user:" + reqUser(r)
+	}
+
+	return "ip:" + app.clientAddress(r)
+}
+
+// clientAddress returns the IP address of the client making a request. Requests from a trusted
+// proxy are attributed to the address the proxy received them from, which is the last address in
+// the X-Forwarded-For header that is not itself a trusted proxy.
+func (app *application) clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !app.trustedProxy(addr) {
+		return host
+	}
+
+	var forwarded []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(header, ",")...)
+	}
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			// Anything before a malformed entry can't be trusted, so the request is attributed to
+			// the last proxy that was.
+			break
+		}
+
+		addr = hop.Unmap()
+		if !app.trustedProxy(addr) {
+			break
+		}
+	}
+
+	return addr.String()
+}
+
+// trustedProxy reports if the address belongs to a proxy trusted to report client addresses.
+func (app *application) trustedProxy(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range app.config.TrustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
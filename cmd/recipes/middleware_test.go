@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/cdriehuys/recipes/internal/ratelimit"
+)
+
+func Test_application_rateLimit(t *testing.T) {
+	testCases := []struct {
+		name          string
+		path          string
+		authenticated bool
+		configure     func(*application, *ratelimit.Limiter)
+		wantAllowed   int
+		wantJSON      bool
+	}{
+		{
+			name:        "login",
+			path:        "/auth/login",
+			configure:   func(app *application, l *ratelimit.Limiter) { app.authLimiter = l },
+			wantAllowed: http.StatusTemporaryRedirect,
+		},
+		{
+			name:        "feed tokens",
+			path:        "/feeds/invalid-token",
+			configure:   func(app *application, l *ratelimit.Limiter) { app.feedLimiter = l },
+			wantAllowed: http.StatusNotFound,
+		},
+		{
+			name:          "pages",
+			path:          "/recipes",
+			authenticated: true,
+			configure:     func(app *application, l *ratelimit.Limiter) { app.pageLimiter = l },
+			wantAllowed:   http.StatusOK,
+		},
+		{
+			name:          "API",
+			path:          "/api/v1/recipes",
+			authenticated: true,
+			configure:     func(app *application, l *ratelimit.Limiter) { app.apiLimiter = l },
+			wantAllowed:   http.StatusOK,
+			wantJSON:      true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			tt.configure(app, &ratelimit.Limiter{Rate: 1.0 / 60, Burst: 2})
+			server := newTestServer(t, app)
+
+			if tt.authenticated {
+				server.authenticate(t, mock.TestUserNormal)
+			}
+
+			for i := 0; i < 2; i++ {
+				status, _, _ := server.get(t, tt.path)
+				assert.Equal(t, tt.wantAllowed, status)
+			}
+
+			status, headers, body := server.get(t, tt.path)
+
+			assert.Equal(t, http.StatusTooManyRequests, status)
+
+			retryAfter, err := strconv.Atoi(headers.Get("Retry-After"))
+			assert.NilError(t, err)
+			if retryAfter < 1 || retryAfter > 60 {
+				t.Errorf("Expected to retry within a minute; got %d seconds", retryAfter)
+			}
+
+			if tt.wantJSON {
+				response := decodeJSON[apiError](t, body)
+				assert.Equal(t, http.StatusTooManyRequests, response.Error.Status)
+			}
+		})
+	}
+}
+
+func Test_application_rateLimit_feedTokens(t *testing.T) {
+	app := newTestApp(t)
+	app.authLimiter = &ratelimit.Limiter{Rate: 1.0 / 60, Burst: 1}
+	app.feedLimiter = &ratelimit.Limiter{Rate: 1.0 / 60, Burst: 1}
+	server := newTestServer(t, app)
+
+	// Feed readers polling a valid feed don't use up the address's allowance.
+	for i := 0; i < 3; i++ {
+		status, _, _ := server.get(t, "/feeds/"+mock.ValidFeedToken)
+		assert.Equal(t, http.StatusOK, status)
+	}
+
+	status, _, _ := server.get(t, "/feeds/invalid-token")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// Guessing feeds doesn't affect logins from the same address.
+	status, _, _ = server.get(t, "/auth/login")
+	assert.Equal(t, http.StatusTemporaryRedirect, status)
+
+	status, _, _ = server.get(t, "/feeds/"+mock.ValidFeedToken)
+	assert.Equal(t, http.StatusTooManyRequests, status)
+}
+
+func Test_application_rateLimit_separateGroups(t *testing.T) {
+	app := newTestApp(t)
+	app.authLimiter = &ratelimit.Limiter{Rate: 1.0 / 60, Burst: 1}
+	app.pageLimiter = &ratelimit.Limiter{Rate: 1.0 / 60, Burst: 1}
+	server := newTestServer(t, app)
+
+	status, _, _ := server.get(t, "/auth/login")
+	assert.Equal(t, http.StatusTemporaryRedirect, status)
+
+	// Using up the login limit doesn't affect other pages.
+	status, _, _ = server.get(t, "/privacy-policy")
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _, _ = server.get(t, "/auth/login")
+	assert.Equal(t, http.StatusTooManyRequests, status)
+}
+
+func Test_application_rateLimitKey(t *testing.T) {
+	trustedProxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	testCases := []struct {
+		name           string
+		remoteAddr     string
+		forwardedFor   []string
+		trustedProxies []netip.Prefix
+		userID         string
+		want           string
+	}{
+		{name: "anonymous", remoteAddr: "192.0.2.1:1234", want: "ip:192.0.2.1"},
+		{name: "anonymous IPv6", remoteAddr: "[2001:db8::1]:1234", want: "ip:2001:db8::1"},
+		{name: "authenticated", remoteAddr: "192.0.2.1:1234", userID: "user-1", want: "user:user-1"},
+		{
+			name:         "forwarded without trusted proxies",
+			remoteAddr:   "10.0.0.1:1234",
+			forwardedFor: []string{"192.0.2.1"},
+			want:         "ip:10.0.0.1",
+		},
+		{
+			name:           "forwarded by untrusted address",
+			remoteAddr:     "198.51.100.1:1234",
+			forwardedFor:   []string{"192.0.2.1"},
+			trustedProxies: trustedProxies,
+			want:           "ip:198.51.100.1",
+		},
+		{
+			name:           "forwarded by trusted proxy",
+			remoteAddr:     "10.0.0.1:1234",
+			forwardedFor:   []string{"192.0.2.1"},
+			trustedProxies: trustedProxies,
+			want:           "ip:192.0.2.1",
+		},
+		{
+			name:           "spoofed forwarded address",
+			remoteAddr:     "10.0.0.1:1234",
+			forwardedFor:   []string{"203.0.113.7, 192.0.2.1", "10.0.0.2"},
+			trustedProxies: trustedProxies,
+			want:           "ip:192.0.2.1",
+		},
+		{
+			name:           "malformed forwarded address",
+			remoteAddr:     "10.0.0.1:1234",
+			forwardedFor:   []string{"192.0.2.1, unknown"},
+			trustedProxies: trustedProxies,
+			want:           "ip:10.0.0.1",
+		},
+		{
+			name:           "only trusted proxies",
+			remoteAddr:     "10.0.0.1:1234",
+			forwardedFor:   []string{"10.0.0.3, 10.0.0.2"},
+			trustedProxies: trustedProxies,
+			want:           "ip:10.0.0.3",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			app.config.TrustedProxies = tt.trustedProxies
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, header := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", header)
+			}
+			if tt.userID != "" {
+				r = r.WithContext(context.WithValue(r.Context(), contextKeyUserID, tt.userID))
+			}
+
+			assert.Equal(t, tt.want, app.rateLimitKey(r))
+		})
+	}
+}
+
+func Test_application_rateLimit_bearerAttempts(t *testing.T) {
+	for _, path := range []string{"/api/v1/recipes", "/recipes"} {
+		t.Run(path, func(t *testing.T) {
+			app := newTestApp(t)
+			app.tokenLimiter = &ratelimit.Limiter{Rate: 1.0 / 60, Burst: 2}
+			server := newTestServer(t, app)
+
+			// Valid tokens don't count against the address.
+			for i := 0; i < 3; i++ {
+				status, _, _ := server.sendBearer(t, http.MethodGet, path, mock.ValidAccessToken, "")
+				assert.Equal(t, http.StatusOK, status)
+			}
+
+			for i := 0; i < 2; i++ {
+				status, _, _ := server.sendBearer(t, http.MethodGet, path, "guess-"+strconv.Itoa(i), "")
+				assert.Equal(t, http.StatusUnauthorized, status)
+			}
+
+			// Once the address is limited, tokens are no longer checked.
+			status, headers, _ := server.sendBearer(t, http.MethodGet, path, mock.ValidAccessToken, "")
+			assert.Equal(t, http.StatusTooManyRequests, status)
+			if headers.Get("Retry-After") == "" {
+				t.Error("Expected a Retry-After header")
+			}
+		})
+	}
+}
+
+func Test_application_rateLimit_sharedAddress(t *testing.T) {
+	app := newTestApp(t)
+	app.apiLimiter = &ratelimit.Limiter{Rate: 1.0 / 60, Burst: 2}
+	app.tokenLimiter = &ratelimit.Limiter{Rate: 1.0 / 60, Burst: 1}
+	server := newTestServer(t, app)
+
+	// Requests with a valid token are limited per user, each taking one token from its bucket.
+	for i := 0; i < 2; i++ {
+		status, _, _ := server.sendBearer(t, http.MethodGet, "/api/v1/recipes", mock.ValidAccessToken, "")
+		assert.Equal(t, http.StatusOK, status)
+	}
+
+	status, _, _ := server.sendBearer(t, http.MethodGet, "/api/v1/recipes", mock.ValidAccessToken, "")
+	assert.Equal(t, http.StatusTooManyRequests, status)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/models"
+)
+
+// wantsJSON reports if the client prefers a JSON response to an HTML one, based on the request's
+// Accept header. HTML is preferred when both are equally acceptable, so browsers sending broad
+// Accept headers still receive pages.
+func wantsJSON(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return false
+	}
+
+	return acceptQuality(accept, "application/json") > acceptQuality(accept, "text/html")
+}
+
+// acceptQuality returns the quality value an Accept header assigns to a media type, using the most
+// specific matching range. It is 0 if the media type is not acceptable.
+func acceptQuality(accept string, mediaType string) float64 {
+	mainType, _, _ := strings.Cut(mediaType, "/")
+
+	quality, specificity := 0.0, -1
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+		rangeType := strings.ToLower(strings.TrimSpace(params[0]))
+
+		var rangeSpecificity int
+		switch rangeType {
+		case mediaType:
+			rangeSpecificity = 2
+		case mainType + "/*":
+			rangeSpecificity = 1
+		case "*/*":
+			rangeSpecificity = 0
+		default:
+			continue
+		}
+
+		if rangeSpecificity < specificity {
+			continue
+		}
+
+		rangeQuality := 1.0
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					rangeQuality = q
+				}
+			}
+		}
+
+		quality, specificity = rangeQuality, rangeSpecificity
+	}
+
+	return quality
+}
+
+// recipesLastModified returns the most recent time any of the recipes was updated.
+func recipesLastModified(recipes ...models.Recipe) time.Time {
+	var lastModified time.Time
+	for _, recipe := range recipes {
+		if recipe.UpdatedAt.After(lastModified) {
+			lastModified = recipe.UpdatedAt
+		}
+	}
+
+	return lastModified
+}
+
+// contentETag returns a strong entity tag identifying the exact bytes of a response body. Hashing
+// the body rather than the underlying rows means any change to what the client sees, including
+// changes to related rows such as a category's name, results in a new tag.
+func contentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// writeCachableJSON writes a value as the JSON body of the response along with an ETag derived
+// from the body. If the request's If-None-Match header shows the client already has this version,
+// a 304 response with no body is sent instead.
+//
+// There is no Last-Modified header, since responses include details, such as a recipe's tags or
+// its category's name, whose changes don't bump any update time, and lists change when items are
+// removed without changing the update time of the remaining items.
+func (app *application) writeCachableJSON(w http.ResponseWriter, r *http.Request, value any) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		app.apiServerError(w, r, err)
+		return
+	}
+
+	etag := contentETag(body)
+
+	// The response is specific to the user, and must be revalidated before a cached copy is used.
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("ETag", etag)
+
+	if notModified(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// notModified evaluates the request's If-None-Match header against the ETag of the current version
+// of a response.
+func notModified(r *http.Request, etag string) bool {
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || (candidate != "" && strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/")) {
+			return true
+		}
+	}
+
+	return false
+}
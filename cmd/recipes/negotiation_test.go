@@ -0,0 +1,245 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_wantsJSON(t *testing.T) {
+	testCases := []struct {
+		name   string
+		accept string
+		want   bool
+	}{
+		{name: "no header"},
+		{name: "JSON", accept: "application/json", want: true},
+		{name: "JSON with parameters", accept: "application/json; charset=utf-8", want: true},
+		{name: "HTML", accept: "text/html"},
+		{name: "anything", accept: "*/*"},
+		{
+			name:   "browser",
+			accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+		},
+		{name: "JSON preferred", accept: "text/html;q=0.5, application/json", want: true},
+		{name: "HTML preferred", accept: "text/html, application/json;q=0.9"},
+		{name: "equally preferred", accept: "application/json, text/html"},
+		{name: "JSON before wildcard", accept: "application/json, */*;q=0.1", want: true},
+		{name: "JSON refused", accept: "application/json;q=0, */*"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodGet, "/recipes", nil)
+			assert.NilError(t, err)
+
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+
+			assert.Equal(t, tt.want, wantsJSON(r))
+		})
+	}
+}
+
+// getAccepting makes a GET request with the given Accept header and optional
+// If-None-Match header.
+func (ts *testServer) getAccepting(t *testing.T, urlPath string, accept string, ifNoneMatch string) (int, http.Header, string) {
+	req, err := http.NewRequest(http.MethodGet, ts.URL+urlPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Accept", accept)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+
+	return ts.send(t, req)
+}
+
+func Test_application_listRecipes_negotiated(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	t.Run("HTML", func(t *testing.T) {
+		status, headers, body := server.getAccepting(t, "/recipes", "text/html", "")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, headers.Get("Content-Type"), "text/html")
+		assert.StringContains(t, strings.Join(headers.Values("Vary"), ", "), "Accept")
+		assert.Equal(t, "", headers.Get("ETag"))
+		assert.StringContains(t, body, mock.Recipe.Title)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		status, headers, body := server.getAccepting(t, "/recipes?sort=title", "application/json", "")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "application/json", headers.Get("Content-Type"))
+		assert.StringContains(t, strings.Join(headers.Values("Vary"), ", "), "Accept")
+		assert.Equal(t, "private, no-cache", headers.Get("Cache-Control"))
+		// Removing a recipe from the list doesn't change the update time of the others.
+		assert.Equal(t, "", headers.Get("Last-Modified"))
+
+		response := decodeJSON[apiRecipeList](t, body)
+		assert.Equal(t, 1, len(response.Data))
+		assert.Equal(t, mock.Recipe.Title, response.Data[0].Title)
+		assert.Equal(t, "title", string(app.recipeModel.(*mock.RecipeModel).LastListOptions.Sort))
+
+		etag := headers.Get("ETag")
+		if etag == "" {
+			t.Fatal("Expected an ETag")
+		}
+
+		status, headers, body = server.getAccepting(t, "/recipes", "application/json", etag)
+
+		assert.Equal(t, http.StatusNotModified, status)
+		assert.Equal(t, etag, headers.Get("ETag"))
+		assert.Equal(t, "", body)
+	})
+}
+
+func Test_application_getRecipe_negotiated(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	recipeID := uuid.New()
+	path := "/recipes/" + recipeID.String()
+
+	_, headers, _ := server.getAccepting(t, path, "application/json", "")
+	etag := headers.Get("ETag")
+
+	testCases := []struct {
+		name        string
+		path        string
+		accept      string
+		ifNoneMatch string
+		wantStatus  int
+		wantJSON    bool
+	}{
+		{name: "HTML", path: path, accept: "text/html", wantStatus: http.StatusOK},
+		{name: "JSON", path: path, accept: "application/json", wantStatus: http.StatusOK, wantJSON: true},
+		{
+			name:        "stale ETag",
+			path:        path,
+			accept:      "application/json",
+			ifNoneMatch: `W/"stale"`,
+			wantStatus:  http.StatusOK,
+			wantJSON:    true,
+		},
+		{
+			name:        "current ETag",
+			path:        path,
+			accept:      "application/json",
+			ifNoneMatch: etag,
+			wantStatus:  http.StatusNotModified,
+		},
+		{
+			name:        "one of several ETags",
+			path:        path,
+			accept:      "application/json",
+			ifNoneMatch: `W/"stale", ` + etag,
+			wantStatus:  http.StatusNotModified,
+		},
+		{
+			name:       "invalid ID",
+			path:       "/recipes/not-a-uuid",
+			accept:     "application/json",
+			wantStatus: http.StatusNotFound,
+			wantJSON:   true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			status, headers, body := server.getAccepting(t, tt.path, tt.accept, tt.ifNoneMatch)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if !tt.wantJSON {
+				return
+			}
+
+			assert.Equal(t, "application/json", headers.Get("Content-Type"))
+
+			if tt.wantStatus != http.StatusOK {
+				response := decodeJSON[apiError](t, body)
+				assert.Equal(t, tt.wantStatus, response.Error.Status)
+				return
+			}
+
+			assert.Equal(t, etag, headers.Get("ETag"))
+
+			recipe := decodeJSON[apiRecipe](t, body)
+			assert.Equal(t, recipeID, recipe.ID)
+			assert.Equal(t, mock.Recipe.Title, recipe.Title)
+		})
+	}
+}
+
+func Test_application_negotiated_unauthenticated(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	t.Run("HTML", func(t *testing.T) {
+		status, headers, _ := server.getAccepting(t, "/recipes", "text/html", "")
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, "/recipes")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		status, headers, body := server.getAccepting(t, "/recipes", "application/json", "")
+
+		assert.Equal(t, http.StatusUnauthorized, status)
+		assert.Equal(t, "application/json", headers.Get("Content-Type"))
+
+		response := decodeJSON[apiError](t, body)
+		assert.Equal(t, http.StatusUnauthorized, response.Error.Status)
+	})
+}
+
+func Test_application_writeCachableJSON(t *testing.T) {
+	app := newTestApp(t)
+	updatedAt := time.Date(2024, 5, 19, 12, 30, 0, 0, time.UTC)
+
+	write := func(recipe apiRecipe) http.Header {
+		r := httptest.NewRequest(http.MethodGet, "/recipes", nil)
+		// The update time doesn't cover the whole response, so it isn't used to validate it.
+		r.Header.Set("If-Modified-Since", updatedAt.Add(time.Hour).Format(http.TimeFormat))
+		rr := httptest.NewRecorder()
+
+		app.writeCachableJSON(rr, r, recipe)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		return rr.Header()
+	}
+
+	recipe := apiRecipe{ID: uuid.New(), Title: "Soup", Tags: []string{"dinner"}, UpdatedAt: updatedAt}
+	original := write(recipe)
+
+	// Changes that don't touch the recipe's update time, such as tagging it or renaming its
+	// category, must still change the tag.
+	recipe.Tags = []string{"dinner", "quick"}
+	tagged := write(recipe)
+
+	categoryName := "Soups"
+	recipe.CategoryName = &categoryName
+	categorized := write(recipe)
+
+	if original.Get("ETag") == tagged.Get("ETag") || tagged.Get("ETag") == categorized.Get("ETag") {
+		t.Errorf("Expected changed content to change the ETag; got %q, %q, %q", original.Get("ETag"), tagged.Get("ETag"), categorized.Get("ETag"))
+	}
+
+	assert.Equal(t, "", original.Get("Last-Modified"))
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/cdriehuys/recipes/internal/ingredients"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/nutrition"
+	"github.com/cdriehuys/recipes/internal/validation"
+	"github.com/google/uuid"
+)
+
+// nutritionPanel holds the nutrition facts shown on a recipe's page.
+type nutritionPanel struct {
+	Facts nutrition.Facts
+	// Servings is the number of servings the facts are divided between, or zero if the recipe does
+	// not specify its servings.
+	Servings   int
+	PerServing nutrition.Nutrients
+}
+
+func newNutritionPanel(recipe models.Recipe, matches map[string]string) nutritionPanel {
+	panel := nutritionPanel{
+		Facts: nutrition.Calculate(recipe.IngredientList(), matches),
+	}
+
+	if recipe.Servings.Valid {
+		panel.Servings = int(recipe.Servings.Int16)
+	}
+	panel.PerServing = panel.Facts.PerServing(panel.Servings)
+
+	return panel
+}
+
+// nutritionMatch is a row of the form used to match a recipe's ingredients to foods by hand.
+type nutritionMatch struct {
+	Ingredient ingredients.Ingredient
+	Key        string
+	// Automatic is the name of the food the ingredient matches without intervention, if any.
+	Automatic string
+	// Food is the name of the food the ingredient was matched to by hand, or Ignore.
+	Food string
+}
+
+// newNutritionMatches lists each distinct ingredient of a recipe along with its matches.
+func newNutritionMatches(recipe models.Recipe, matches map[string]string) []nutritionMatch {
+	var rows []nutritionMatch
+	seen := make(map[string]bool)
+
+	for _, ingredient := range recipe.IngredientList() {
+		key := ingredients.Key(ingredient.Name)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		row := nutritionMatch{Ingredient: ingredient, Key: key, Food: matches[key]}
+		if food, ok := nutrition.Lookup(ingredient.Name); ok {
+			row.Automatic = food.Name
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+type nutritionMatchesForm struct {
+	Matches map[string]string
+	validation.Validator
+}
+
+func (form *nutritionMatchesForm) Validate() {
+	for _, food := range form.Matches {
+		if _, ok := nutrition.FoodByName(food); !ok && food != nutrition.Ignore {
+			form.AddNonFieldError("Choose each food from the list.")
+			break
+		}
+	}
+}
+
+func (app *application) recipeNutrition(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	matches, err := app.nutritionModel.Matches(r.Context(), userID, recipeID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.renderRecipeNutrition(w, r, http.StatusOK, userID, recipeID, &nutritionMatchesForm{Matches: matches})
+}
+
+func (app *application) recipeNutritionPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	keys, foods := r.PostForm["ingredient"], r.PostForm["food"]
+	if len(keys) != len(foods) {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form := nutritionMatchesForm{Matches: make(map[string]string)}
+	for i, key := range keys {
+		// A blank food keeps the automatic match.
+		if key != "" && foods[i] != "" {
+			form.Matches[key] = foods[i]
+		}
+	}
+	form.Validate()
+
+	if !form.IsValid() {
+		app.renderRecipeNutrition(w, r, http.StatusUnprocessableEntity, userID, recipeID, &form)
+		return
+	}
+
+	if err := app.nutritionModel.SetMatches(r.Context(), userID, recipeID, form.Matches); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/recipes/"+recipeID.String(), http.StatusSeeOther)
+}
+
+func (app *application) renderRecipeNutrition(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	userID string,
+	recipeID uuid.UUID,
+	form *nutritionMatchesForm,
+) {
+	recipe, err := app.recipeModel.GetByID(r.Context(), userID, recipeID)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Foods = nutrition.Foods()
+	data.Form = form
+	data.NutritionMatches = newNutritionMatches(recipe, form.Matches)
+	data.Recipe = recipe
+
+	app.render(w, r, status, "recipe-nutrition", data)
+}
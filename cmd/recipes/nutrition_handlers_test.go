@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/cdriehuys/recipes/internal/nutrition"
+	"github.com/google/uuid"
+)
+
+func Test_application_getRecipe_nutrition(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	recipeID := uuid.NewString()
+	status, _, body := server.get(t, "/recipes/"+recipeID)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.StringContains(t, body, "Nutrition Facts")
+	assert.StringContains(t, body, "Whole recipe")
+	// The mock recipe's eggs are ignored, leaving 2 cups and 250 ml of milk.
+	assert.StringContains(t, body, "454")
+	assert.StringContains(t, body, "/recipes/"+recipeID+"/nutrition")
+}
+
+func Test_application_recipeNutrition(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	nutritionURL := "/recipes/" + uuid.NewString() + "/nutrition"
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, nutritionURL)
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, nutritionURL)
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, body := server.get(t, nutritionURL)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, "Automatic (whole milk)")
+		assert.StringContains(t, body, `<option value="ignore" selected>`)
+	})
+}
+
+func Test_application_recipeNutritionPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	recipeID := uuid.New()
+	nutritionURL := "/recipes/" + recipeID.String() + "/nutrition"
+
+	_, _, page := server.get(t, nutritionURL)
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name                  string
+		ingredients           []string
+		foods                 []string
+		wantStatus            int
+		wantValidationMessage string
+		wantMatches           map[string]string
+	}{
+		{
+			name:        "valid",
+			ingredients: []string{"milk", "egg"},
+			foods:       []string{"almond milk", nutrition.Ignore},
+			wantStatus:  http.StatusSeeOther,
+			wantMatches: map[string]string{"milk": "almond milk", "egg": nutrition.Ignore},
+		},
+		{
+			name:        "automatic",
+			ingredients: []string{"milk", "egg"},
+			foods:       []string{"", ""},
+			wantStatus:  http.StatusSeeOther,
+			wantMatches: map[string]string{},
+		},
+		{
+			name:                  "unknown food",
+			ingredients:           []string{"milk"},
+			foods:                 []string{"unicorn milk"},
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "Choose each food from the list.",
+		},
+		{
+			name:        "mismatched fields",
+			ingredients: []string{"milk", "egg"},
+			foods:       []string{"almond milk"},
+			wantStatus:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			for _, ingredient := range tt.ingredients {
+				form.Add("ingredient", ingredient)
+			}
+			for _, food := range tt.foods {
+				form.Add("food", food)
+			}
+
+			status, headers, body := server.postForm(t, nutritionURL, form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+			}
+
+			if tt.wantStatus == http.StatusSeeOther {
+				assertRedirects(t, headers, "/recipes/"+recipeID.String())
+
+				model := app.nutritionModel.(*mock.NutritionModel)
+				assert.Equal(t, recipeID, model.LastSetRecipe)
+				assert.Equal(t, len(tt.wantMatches), len(model.LastSetMatches))
+				for ingredient, food := range tt.wantMatches {
+					assert.Equal(t, food, model.LastSetMatches[ingredient])
+				}
+			}
+		})
+	}
+}
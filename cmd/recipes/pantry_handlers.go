@@ -0,0 +1,214 @@
+package main
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/ingredients"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/validation"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// expiringBoost is added to the score of a cookable recipe for each pantry item it uses that is
+// about to expire. It is equivalent to having an extra quarter of the recipe's ingredients on hand.
+const expiringBoost = 0.25
+
+// pantryEntry is a pantry item along with its freshness as of the current day.
+type pantryEntry struct {
+	models.PantryItem
+	Expired      bool
+	ExpiringSoon bool
+}
+
+// cookableRecipe describes how much of a recipe can be made from the ingredients in the pantry.
+type cookableRecipe struct {
+	Recipe models.Recipe
+	// Have is the number of the recipe's ingredients that are in the pantry.
+	Have int
+	// Missing holds the recipe's ingredients that are not in the pantry.
+	Missing []ingredients.Ingredient
+	// Expiring holds the pantry items the recipe uses that are about to expire.
+	Expiring []models.PantryItem
+}
+
+// Total returns the number of ingredients in the recipe.
+func (c cookableRecipe) Total() int {
+	return c.Have + len(c.Missing)
+}
+
+// Percent returns the percentage of the recipe's ingredients that are in the pantry.
+func (c cookableRecipe) Percent() int {
+	return c.Have * 100 / c.Total()
+}
+
+func (c cookableRecipe) score() float64 {
+	return float64(c.Have)/float64(c.Total()) + expiringBoost*float64(len(c.Expiring))
+}
+
+// today returns the current date in UTC.
+func today() time.Time {
+	now := time.Now()
+
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// rankCookable scores each recipe by the fraction of its ingredients that are in the pantry, with a
+// boost for each pantry item it would use up before that item expires. Recipes are returned from
+// highest to lowest score, with ties keeping their original order. Recipes without any ingredients
+// are omitted.
+func rankCookable(recipes []models.Recipe, pantry []models.PantryItem, today time.Time) []cookableRecipe {
+	ranked := make([]cookableRecipe, 0, len(recipes))
+
+	for _, recipe := range recipes {
+		cookable := cookableRecipe{Recipe: recipe}
+		used := make(map[int]bool)
+
+		for _, ingredient := range recipe.IngredientList() {
+			if ingredient.Name == "" {
+				continue
+			}
+
+			match := slices.IndexFunc(pantry, func(item models.PantryItem) bool {
+				return ingredients.Matches(item.Name, ingredient.Name)
+			})
+			if match < 0 {
+				cookable.Missing = append(cookable.Missing, ingredient)
+				continue
+			}
+
+			cookable.Have++
+
+			if item := pantry[match]; item.ExpiringSoon(today) && !used[match] {
+				cookable.Expiring = append(cookable.Expiring, item)
+			}
+			used[match] = true
+		}
+
+		if cookable.Total() > 0 {
+			ranked = append(ranked, cookable)
+		}
+	}
+
+	slices.SortStableFunc(ranked, func(a, b cookableRecipe) int {
+		return cmp.Compare(b.score(), a.score())
+	})
+
+	return ranked
+}
+
+type pantryItemForm struct {
+	Item      string
+	ExpiresOn string
+	validation.Validator
+}
+
+func (form *pantryItemForm) Validate() {
+	checkItem(&form.Validator, form.Item)
+
+	if form.ExpiresOn != "" {
+		form.CheckField(validation.ValidDate(form.ExpiresOn), "expiresOn", "This field must be a valid date.")
+	}
+}
+
+// expiresOn returns the validated expiry date, which is null if none was provided.
+func (form *pantryItemForm) expiresOn() pgtype.Date {
+	expiresOn, err := time.Parse(time.DateOnly, form.ExpiresOn)
+	if err != nil {
+		return pgtype.Date{}
+	}
+
+	return pgtype.Date{Time: expiresOn, Valid: true}
+}
+
+func (app *application) pantry(w http.ResponseWriter, r *http.Request) {
+	app.renderPantry(w, r, http.StatusOK, reqUser(r), &pantryItemForm{})
+}
+
+func (app *application) pantryPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	form := pantryItemForm{
+		Item:      r.PostFormValue("item"),
+		ExpiresOn: r.PostFormValue("expiresOn"),
+	}
+	form.Validate()
+
+	if !form.IsValid() {
+		app.renderPantry(w, r, http.StatusUnprocessableEntity, userID, &form)
+		return
+	}
+
+	item := models.NewPantryItem(userID, ingredients.Parse(form.Item), form.expiresOn())
+	if err := app.pantryModel.Add(r.Context(), item); err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/pantry", http.StatusSeeOther)
+}
+
+func (app *application) deletePantryItemPost(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := app.uuidPathValue(w, r, "itemID")
+	if !ok {
+		return
+	}
+
+	if err := app.pantryModel.Delete(r.Context(), reqUser(r), itemID); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/pantry", http.StatusSeeOther)
+}
+
+func (app *application) cookableRecipes(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	pantry, err := app.pantryModel.List(r.Context(), userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	recipes, err := app.recipeModel.List(r.Context(), userID, models.RecipeListOptions{Sort: models.SortTitle})
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Cookable = rankCookable(recipes, pantry, today())
+	data.Pantry = newPantryEntries(pantry, today())
+
+	app.render(w, r, http.StatusOK, "cookable-recipes", data)
+}
+
+func newPantryEntries(items []models.PantryItem, today time.Time) []pantryEntry {
+	entries := make([]pantryEntry, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, pantryEntry{
+			PantryItem:   item,
+			Expired:      item.Expired(today),
+			ExpiringSoon: item.ExpiringSoon(today),
+		})
+	}
+
+	return entries
+}
+
+func (app *application) renderPantry(w http.ResponseWriter, r *http.Request, status int, userID string, form *pantryItemForm) {
+	items, err := app.pantryModel.List(r.Context(), userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = form
+	data.Pantry = newPantryEntries(items, today())
+
+	app.render(w, r, status, "pantry", data)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func Test_rankCookable(t *testing.T) {
+	today := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+	expiresOn := func(days int) pgtype.Date {
+		return pgtype.Date{Time: today.AddDate(0, 0, days), Valid: true}
+	}
+
+	pantry := []models.PantryItem{
+		{Name: "flour"},
+		{Name: "eggs", ExpiresOn: expiresOn(10)},
+		{Name: "milk", ExpiresOn: expiresOn(1)},
+		{Name: "spinach", ExpiresOn: expiresOn(-1)},
+	}
+
+	recipes := []models.Recipe{
+		{Title: "Empty"},
+		{Title: "Cake", Ingredients: "2 cups all-purpose flour\n2 eggs\n1 cup sugar\n1 cup butter"},
+		{Title: "Omelette", Ingredients: "3 eggs\n1 cup spinach"},
+		{Title: "Pancakes", Ingredients: "1 cup flour\n1 egg\n1 cup milk\n1 tbsp sugar\n2 tbsp butter\n1 tsp baking powder"},
+		{Title: "Salad", Ingredients: "lettuce\ntomatoes"},
+	}
+
+	ranked := rankCookable(recipes, pantry, today)
+
+	titles := make([]string, 0, len(ranked))
+	for _, cookable := range ranked {
+		titles = append(titles, cookable.Recipe.Title)
+	}
+
+	// Pancakes are boosted above the cake for using up the milk, and the expired spinach counts as
+	// on hand without boosting the omelette.
+	assert.Equal(t, "Omelette, Pancakes, Cake, Salad", strings.Join(titles, ", "))
+
+	omelette := ranked[0]
+	assert.Equal(t, 2, omelette.Have)
+	assert.Equal(t, 100, omelette.Percent())
+	assert.Equal(t, 0, len(omelette.Expiring))
+
+	pancakes := ranked[1]
+	assert.Equal(t, 3, pancakes.Have)
+	assert.Equal(t, 6, pancakes.Total())
+	assert.Equal(t, 1, len(pancakes.Expiring))
+	assert.Equal(t, "milk", pancakes.Expiring[0].Name)
+
+	cake := ranked[2]
+	assert.Equal(t, 50, cake.Percent())
+	assert.Equal(t, 2, len(cake.Missing))
+	assert.Equal(t, "sugar", cake.Missing[0].Name)
+	assert.Equal(t, "butter", cake.Missing[1].Name)
+
+	assert.Equal(t, 0, ranked[3].Have)
+}
+
+func Test_application_pantry(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, "/pantry")
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, "/pantry")
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, body := server.get(t, "/pantry")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, "1 quart milk")
+		assert.StringContains(t, body, "Expires "+mock.PantryItem.ExpiresOn.Time.Format("1/2/2006"))
+	})
+}
+
+func Test_application_pantryPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/pantry")
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name                  string
+		item                  string
+		expiresOn             string
+		wantStatus            int
+		wantValidationMessage string
+		wantItem              string
+	}{
+		{
+			name:       "with expiry",
+			item:       "1 lb ground beef",
+			expiresOn:  "2024-03-12",
+			wantStatus: http.StatusSeeOther,
+			wantItem:   "1 lb ground beef",
+		},
+		{
+			name:       "name only",
+			item:       "rice",
+			wantStatus: http.StatusSeeOther,
+			wantItem:   "rice",
+		},
+		{
+			name:                  "blank",
+			item:                  "",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field is required.",
+		},
+		{
+			name:                  "invalid expiry",
+			item:                  "rice",
+			expiresOn:             "soon",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be a valid date.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("item", tt.item)
+			form.Add("expiresOn", tt.expiresOn)
+
+			status, headers, body := server.postForm(t, "/pantry", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+			} else {
+				assertRedirects(t, headers, "/pantry")
+
+				added := app.pantryModel.(*mock.PantryModel).LastAddedItem
+				assert.Equal(t, tt.wantItem, added.String())
+				assert.Equal(t, mock.TestUserNormal, added.Owner)
+				assert.Equal(t, tt.expiresOn != "", added.ExpiresOn.Valid)
+			}
+		})
+	}
+}
+
+func Test_application_deletePantryItemPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/pantry")
+	csrfToken := extractCSRFToken(t, page)
+
+	itemID := uuid.New()
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+
+	status, headers, _ := server.postForm(t, "/pantry/items/"+itemID.String()+"/delete", form)
+
+	assert.Equal(t, http.StatusSeeOther, status)
+	assertRedirects(t, headers, "/pantry")
+	assert.Equal(t, itemID, app.pantryModel.(*mock.PantryModel).LastDeletedItem)
+}
+
+func Test_application_cookableRecipes(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, "/recipes/cookable")
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, "/recipes/cookable")
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, body := server.get(t, "/recipes/cookable")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, mock.Recipe.Title)
+		assert.StringContains(t, body, "2 of 3 ingredients")
+		assert.StringContains(t, body, "Uses up milk")
+		assert.StringContains(t, body, "Missing: eggs")
+	})
+}
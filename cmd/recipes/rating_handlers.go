@@ -0,0 +1,117 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/validation"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ratingForm struct {
+	Rating    string
+	MakeAgain string
+	validation.Validator
+}
+
+// newRatingForm returns a rating form populated with the user's existing rating of the recipe.
+func newRatingForm(recipe models.Recipe) *ratingForm {
+	form := ratingForm{}
+
+	if recipe.Rating.Valid {
+		form.Rating = strconv.Itoa(int(recipe.Rating.Int16))
+	}
+
+	if recipe.MakeAgain.Valid {
+		form.MakeAgain = "no"
+		if recipe.MakeAgain.Bool {
+			form.MakeAgain = "yes"
+		}
+	}
+
+	return &form
+}
+
+func (form *ratingForm) Validate() {
+	form.CheckField(
+		validation.PermittedValue(form.Rating, "", "1", "2", "3", "4", "5"),
+		"rating",
+		"This field must be a whole number from 1 to 5.",
+	)
+	form.CheckField(
+		validation.PermittedValue(form.MakeAgain, "", "yes", "no"),
+		"makeAgain",
+		"This field must be one of the provided options.",
+	)
+}
+
+// rating returns the validated rating, which is null if no rating was provided.
+func (form *ratingForm) rating() pgtype.Int2 {
+	rating, err := strconv.ParseInt(form.Rating, 10, 16)
+	if err != nil {
+		return pgtype.Int2{}
+	}
+
+	return pgtype.Int2{Int16: int16(rating), Valid: true}
+}
+
+// makeAgain returns the validated "would make again" flag, which is null if it was not provided.
+func (form *ratingForm) makeAgain() pgtype.Bool {
+	if form.MakeAgain == "" {
+		return pgtype.Bool{}
+	}
+
+	return pgtype.Bool{Bool: form.MakeAgain == "yes", Valid: true}
+}
+
+func (app *application) favoriteRecipePost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	favorite := r.PostFormValue("favorite") == "true"
+	if err := app.ratingModel.SetFavorite(r.Context(), userID, recipeID, favorite); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/recipes/"+recipeID.String(), http.StatusSeeOther)
+}
+
+func (app *application) rateRecipePost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	form := ratingForm{
+		Rating:    r.PostFormValue("rating"),
+		MakeAgain: r.PostFormValue("makeAgain"),
+	}
+	form.Validate()
+
+	if !form.IsValid() {
+		recipe, err := app.recipeModel.GetByID(r.Context(), userID, recipeID)
+		if err != nil {
+			app.modelError(w, r, err)
+			return
+		}
+
+		app.renderRecipe(w, r, http.StatusUnprocessableEntity, userID, recipe, &form)
+		return
+	}
+
+	err := app.ratingModel.Rate(r.Context(), userID, recipeID, form.rating(), form.makeAgain())
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/recipes/"+recipeID.String(), http.StatusSeeOther)
+}
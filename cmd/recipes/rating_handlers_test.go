@@ -0,0 +1,182 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/dietary"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_application_listRecipes_options(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	testCases := []struct {
+		name        string
+		query       string
+		wantOptions models.RecipeListOptions
+	}{
+		{
+			name:        "defaults",
+			query:       "",
+			wantOptions: models.RecipeListOptions{Sort: models.SortTitle},
+		},
+		{
+			name:        "favorites by rating",
+			query:       "?favorites=1&sort=rating",
+			wantOptions: models.RecipeListOptions{FavoritesOnly: true, Sort: models.SortRating},
+		},
+		{
+			name:        "unknown sort",
+			query:       "?sort=DROP+TABLE",
+			wantOptions: models.RecipeListOptions{Sort: models.SortTitle},
+		},
+		{
+			name:  "exclude allergens",
+			query: "?exclude=nuts&exclude=dairy&exclude=unknown",
+			wantOptions: models.RecipeListOptions{
+				Sort:             models.SortTitle,
+				ExcludeAllergens: dietary.AllergenSet(0).With(dietary.Nuts).With(dietary.Dairy),
+			},
+		},
+		{
+			name:        "vegetarian only",
+			query:       "?diet=vegetarian",
+			wantOptions: models.RecipeListOptions{Sort: models.SortTitle, Diet: dietary.Vegetarian},
+		},
+		{
+			name:        "unknown diet",
+			query:       "?diet=carnivore",
+			wantOptions: models.RecipeListOptions{Sort: models.SortTitle},
+		},
+		{
+			name:        "quickest under 30 minutes",
+			query:       "?sort=total-time&time=30",
+			wantOptions: models.RecipeListOptions{Sort: models.SortTotalTime, MaxTotalMinutes: 30},
+		},
+		{
+			name:        "unsupported time limit",
+			query:       "?time=45",
+			wantOptions: models.RecipeListOptions{Sort: models.SortTitle},
+		},
+		{
+			name:        "tagged",
+			query:       "?tag=+Weeknight++Dinner",
+			wantOptions: models.RecipeListOptions{Sort: models.SortTitle, Tag: "weeknight dinner"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, _ := server.get(t, "/recipes"+tt.query)
+
+			assert.Equal(t, http.StatusOK, status)
+			assert.Equal(t, tt.wantOptions, app.recipeModel.(*mock.RecipeModel).LastListOptions)
+		})
+	}
+}
+
+func Test_application_favoriteRecipePost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	recipeURL := "/recipes/" + uuid.NewString()
+
+	_, _, page := server.get(t, recipeURL)
+	csrfToken := extractCSRFToken(t, page)
+
+	for _, favorite := range []bool{true, false} {
+		form := url.Values{}
+		form.Add("csrf_token", csrfToken)
+		form.Add("favorite", strconv.FormatBool(favorite))
+
+		status, headers, _ := server.postForm(t, recipeURL+"/favorite", form)
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assert.Equal(t, favorite, app.ratingModel.(*mock.RatingModel).Favorite)
+		assertRedirects(t, headers, recipeURL)
+	}
+}
+
+func Test_application_rateRecipePost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	recipeURL := "/recipes/" + uuid.NewString()
+
+	_, _, page := server.get(t, recipeURL)
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name                  string
+		rating                string
+		makeAgain             string
+		wantStatus            int
+		wantValidationMessage string
+		wantRating            int16
+		wantMakeAgain         string
+	}{
+		{
+			name:          "rating and make again",
+			rating:        "4",
+			makeAgain:     "yes",
+			wantStatus:    http.StatusSeeOther,
+			wantRating:    4,
+			wantMakeAgain: "yes",
+		},
+		{
+			name:          "clear rating",
+			rating:        "",
+			makeAgain:     "no",
+			wantStatus:    http.StatusSeeOther,
+			wantMakeAgain: "no",
+		},
+		{
+			name:                  "rating out of range",
+			rating:                "6",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be a whole number from 1 to 5.",
+		},
+		{
+			name:                  "invalid make again",
+			rating:                "3",
+			makeAgain:             "maybe",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be one of the provided options.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("rating", tt.rating)
+			form.Add("makeAgain", tt.makeAgain)
+
+			status, headers, body := server.postForm(t, recipeURL+"/rating", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+				return
+			}
+
+			model := app.ratingModel.(*mock.RatingModel)
+			assert.Equal(t, tt.wantRating, model.Rating.Int16)
+			assert.Equal(t, tt.wantRating != 0, model.Rating.Valid)
+			assert.Equal(t, tt.wantMakeAgain == "yes", model.MakeAgain.Bool)
+			assert.Equal(t, tt.wantMakeAgain != "", model.MakeAgain.Valid)
+			assertRedirects(t, headers, recipeURL)
+		})
+	}
+}
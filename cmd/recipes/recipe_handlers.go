@@ -1,39 +1,263 @@
 package main
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/cdriehuys/recipes/internal/dietary"
 	"github.com/cdriehuys/recipes/internal/models"
 	"github.com/cdriehuys/recipes/internal/validation"
 	"github.com/google/uuid"
 )
 
+// maxRecipeMinutes is the longest a recipe's prep, cook, or total time may be.
+const maxRecipeMinutes = 7 * 24 * 60
+
 type RecipeForm struct {
 	Category     string
+	Household    string
 	Title        string
+	Servings     string
+	Ingredients  string
 	Instructions string
+	// PrepTime, CookTime, and TotalTime are whole numbers of minutes.
+	PrepTime   string
+	CookTime   string
+	TotalTime  string
+	SourceName string
+	SourceURL  string
+	// Version is the last modification time of the recipe being edited when the form was loaded.
+	// It is blank for new recipes.
+	Version string
+	// Draft is the ID of the draft the form is automatically saved to.
+	Draft string
 	validation.Validator
 }
 
+// newRecipeForm populates a recipe form from a submitted request.
+func newRecipeForm(r *http.Request) RecipeForm {
+	return recipeFormFromValues(r.PostFormValue)
+}
+
+// recipeFormFromValues populates a recipe form using a function returning the value of each field
+// by name.
+func recipeFormFromValues(value func(string) string) RecipeForm {
+	return RecipeForm{
+		Category:     value("category"),
+		Household:    value("household"),
+		Title:        value("title"),
+		Servings:     value("servings"),
+		Ingredients:  value("ingredients"),
+		Instructions: value("instructions"),
+		PrepTime:     strings.TrimSpace(value("prepTime")),
+		CookTime:     strings.TrimSpace(value("cookTime")),
+		TotalTime:    strings.TrimSpace(value("totalTime")),
+		SourceName:   strings.TrimSpace(value("sourceName")),
+		SourceURL:    strings.TrimSpace(value("sourceURL")),
+		Version:      value("version"),
+		Draft:        value("draft"),
+	}
+}
+
+// values returns the form's values keyed by field name, excluding the draft ID.
+func (form *RecipeForm) values() map[string]string {
+	return map[string]string{
+		"category":     form.Category,
+		"household":    form.Household,
+		"title":        form.Title,
+		"servings":     form.Servings,
+		"ingredients":  form.Ingredients,
+		"instructions": form.Instructions,
+		"prepTime":     form.PrepTime,
+		"cookTime":     form.CookTime,
+		"totalTime":    form.TotalTime,
+		"sourceName":   form.SourceName,
+		"sourceURL":    form.SourceURL,
+		"version":      form.Version,
+	}
+}
+
+// recipeFormFor returns a form populated with an existing recipe's values.
+func recipeFormFor(recipe models.Recipe) RecipeForm {
+	return RecipeForm{
+		Category:     optionalUUIDString(recipe.Category),
+		Household:    optionalUUIDString(recipe.Household),
+		Title:        recipe.Title,
+		Servings:     optionalInt2String(recipe.Servings),
+		Ingredients:  recipe.Ingredients,
+		Instructions: recipe.Instructions,
+		PrepTime:     optionalMinutesString(recipe.PrepTime),
+		CookTime:     optionalMinutesString(recipe.CookTime),
+		TotalTime:    optionalMinutesString(recipe.TotalTime),
+		SourceName:   recipe.SourceName,
+		SourceURL:    recipe.SourceURL,
+		Version:      recipeVersion(recipe),
+	}
+}
+
+// recipeVersion formats the last modification time of a recipe so that it can be submitted with a
+// form and compared without losing precision.
+func recipeVersion(recipe models.Recipe) string {
+	return recipe.UpdatedAt.Format(time.RFC3339Nano)
+}
+
+// recipe returns a recipe with the validated values of the form. If no total time was provided,
+// it defaults to the sum of the prep and cook times.
+func (form *RecipeForm) recipe(id uuid.UUID) models.Recipe {
+	recipe := models.Recipe{
+		ID:           id,
+		Household:    optionalUUID(form.Household),
+		Category:     optionalUUID(form.Category),
+		Title:        form.Title,
+		Ingredients:  form.Ingredients,
+		Instructions: form.Instructions,
+		Servings:     optionalInt2(form.Servings),
+		PrepTime:     optionalMinutes(form.PrepTime),
+		CookTime:     optionalMinutes(form.CookTime),
+		TotalTime:    optionalMinutes(form.TotalTime),
+		SourceName:   form.SourceName,
+		SourceURL:    form.SourceURL,
+	}
+
+	if !recipe.TotalTime.Valid {
+		recipe.TotalTime = recipe.PrepTime.Add(recipe.CookTime)
+	}
+
+	if version, err := time.Parse(time.RFC3339Nano, form.Version); err == nil {
+		recipe.UpdatedAt = version
+	}
+
+	return recipe
+}
+
 func (form *RecipeForm) Validate() {
 	form.CheckField(validation.UUIDOrBlank(form.Category), "category", "This field must be a valid category ID.")
+	form.CheckField(validation.UUIDOrBlank(form.Household), "household", "This field must be a valid household ID.")
 	form.CheckField(validation.NotBlank(form.Title), "title", "This field is required.")
 	form.CheckField(validation.MaxLength(form.Title, 200), "title", "This field may not contain more than 200 characters.")
+	form.CheckField(
+		form.Servings == "" || validation.IntInRange(form.Servings, 1, 100),
+		"servings",
+		"This field must be a whole number from 1 to 100.",
+	)
+	form.CheckField(
+		validation.MaxLength(form.Ingredients, 10000),
+		"ingredients",
+		"This field may not contain more than 10000 characters.",
+	)
 	form.CheckField(validation.NotBlank(form.Instructions), "instructions", "This field is required.")
+
+	timesValid := true
+	for field, value := range map[string]string{
+		"prepTime":  form.PrepTime,
+		"cookTime":  form.CookTime,
+		"totalTime": form.TotalTime,
+	} {
+		if value != "" && !validation.IntInRange(value, 0, maxRecipeMinutes) {
+			form.AddFieldError(field, fmt.Sprintf("This field must be a whole number of minutes from 0 to %d.", maxRecipeMinutes))
+			timesValid = false
+		}
+	}
+
+	if timesValid && form.TotalTime != "" {
+		prepAndCook := optionalMinutes(form.PrepTime).Add(optionalMinutes(form.CookTime))
+		form.CheckField(
+			optionalMinutes(form.TotalTime).Duration >= prepAndCook.Duration,
+			"totalTime",
+			"This field may not be less than the prep and cook times combined.",
+		)
+	}
+
+	form.CheckField(
+		validation.MaxLength(form.SourceName, 200),
+		"sourceName",
+		"This field may not contain more than 200 characters.",
+	)
+	form.CheckField(
+		validation.MaxLength(form.SourceURL, 2000),
+		"sourceURL",
+		"This field may not contain more than 2000 characters.",
+	)
+	form.CheckField(
+		validation.WebURLOrBlank(form.SourceURL),
+		"sourceURL",
+		"This field must be a web address starting with http:// or https://.",
+	)
+	form.CheckField(validation.UUIDOrBlank(form.Draft), "draft", "This field must be a valid draft ID.")
+	form.CheckField(
+		form.Version == "" || validation.ValidTimestamp(form.Version),
+		"version",
+		"This form is out of date. Reload the page and try again.",
+	)
 }
 
-func (app *application) addRecipe(w http.ResponseWriter, r *http.Request) {
-	userID := app.sessionManager.GetString(r.Context(), "authenticatedUserID")
+// recipeListOptions parses the filtering and sorting options for the recipe list from a query
+// string. Unrecognized values are ignored.
+func recipeListOptions(query url.Values) models.RecipeListOptions {
+	opts := models.RecipeListOptions{
+		FavoritesOnly: query.Get("favorites") == "1",
+		Sort:          models.SortTitle,
+	}
+
+	if sort := models.RecipeSort(query.Get("sort")); validation.PermittedValue(sort, models.RecipeSorts...) {
+		opts.Sort = sort
+	}
+
+	for _, allergen := range query["exclude"] {
+		opts.ExcludeAllergens = opts.ExcludeAllergens.With(dietary.Allergen(allergen))
+	}
 
+	if diet := dietary.Diet(query.Get("diet")); validation.PermittedValue(diet, dietary.Diets...) {
+		opts.Diet = diet
+	}
+
+	if minutes, err := strconv.Atoi(query.Get("time")); err == nil && validation.PermittedValue(minutes, models.MaxTotalMinutes...) {
+		opts.MaxTotalMinutes = minutes
+	}
+
+	opts.Tag = models.NormalizeTag(query.Get("tag"))
+
+	return opts
+}
+
+// recipeFormData returns the template data required to render a recipe form for the user.
+func (app *application) recipeFormData(r *http.Request, userID string, form *RecipeForm) (templateData, error) {
 	categories, err := app.categoryModel.List(r.Context(), userID)
 	if err != nil {
-		app.serverError(w, r, err)
-		return
+		return templateData{}, err
+	}
+
+	households, err := app.editableHouseholds(r, userID)
+	if err != nil {
+		return templateData{}, err
 	}
 
 	data := app.newTemplateData(r)
 	data.Categories = categories
-	data.Form = &RecipeForm{}
+	data.Households = households
+	data.Form = form
+
+	return data, nil
+}
+
+func (app *application) addRecipe(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	form, err := app.resumeDraft(r, userID, nil, RecipeForm{})
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	data, err := app.recipeFormData(r, userID, &form)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
 
 	app.render(w, r, http.StatusOK, "add-recipe", data)
 }
@@ -41,48 +265,202 @@ func (app *application) addRecipe(w http.ResponseWriter, r *http.Request) {
 func (app *application) addRecipePost(w http.ResponseWriter, r *http.Request) {
 	userID := reqUser(r)
 
-	form := RecipeForm{
-		Category:     r.PostFormValue("category"),
-		Title:        r.PostFormValue("title"),
-		Instructions: r.PostFormValue("instructions"),
+	form := newRecipeForm(r)
+	form.Validate()
+
+	data, err := app.recipeFormData(r, userID, &form)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
 	}
 
-	form.Validate()
+	form.CheckField(
+		canAddToHousehold(data.Households, form.Household),
+		"household",
+		"You may not add recipes to this household.",
+	)
 
 	if !form.IsValid() {
 		app.logger.DebugContext(r.Context(), "New recipe form did not validate.")
+		app.render(w, r, http.StatusUnprocessableEntity, "add-recipe", data)
+		return
+	}
 
-		categories, err := app.categoryModel.List(r.Context(), userID)
-		if err != nil {
-			app.serverError(w, r, err)
-			return
-		}
+	recipe := form.recipe(uuid.New())
+	recipe.Owner = userID
 
-		data := app.newTemplateData(r)
-		data.Categories = categories
-		data.Form = &form
+	if err := app.recipeModel.Add(r.Context(), recipe); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
 
-		app.render(w, r, http.StatusUnprocessableEntity, "add-recipe", data)
+	app.discardDraft(r, userID, &form)
+	app.sendRecipeEvents(r, userID, models.EventRecipeCreated, recipe.ID)
+
+	http.Redirect(w, r, "/recipes/"+recipe.ID.String(), http.StatusSeeOther)
+}
+
+// renderRecipe renders the detail view of a recipe, including its cooking history.
+func (app *application) renderRecipe(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	userID string,
+	recipe models.Recipe,
+	form *ratingForm,
+) {
+	cookLog, err := app.cookLogModel.ListForRecipe(r.Context(), userID, recipe.ID)
+	if err != nil {
+		app.serverError(w, r, err)
 		return
 	}
 
-	recipe := models.Recipe{
-		ID:           uuid.New(),
-		Owner:        userID,
-		Title:        form.Title,
-		Instructions: form.Instructions,
+	matches, err := app.nutritionModel.Matches(r.Context(), userID, recipe.ID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	components, err := app.recipeModel.Components(r.Context(), userID, recipe.ID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
 	}
 
-	if form.Category != "" {
-		// We should have validated the ID, so it's okay to panic if it fails to parse.
-		categoryID := uuid.MustParse(form.Category)
-		recipe.Category = &categoryID
+	referencedBy, err := app.recipeModel.ReferencedBy(r.Context(), userID, recipe)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
 	}
 
-	if err := app.recipeModel.Add(r.Context(), recipe); err != nil {
+	collections, err := app.collectionModel.List(r.Context(), userID)
+	if err != nil {
 		app.serverError(w, r, err)
 		return
 	}
 
-	http.Redirect(w, r, "/recipes/"+recipe.ID.String(), http.StatusSeeOther)
+	recipeCollections, err := app.collectionModel.ListForRecipe(r.Context(), userID, recipe.ID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	scale := parseScale(r.URL.Query())
+
+	data := app.newTemplateData(r)
+	data.Collections = collections
+	data.CookLog = cookLog
+	data.Form = form
+	data.Instructions = linkComponents(recipe.Instructions, components)
+	data.Nutrition = newNutritionPanel(recipe, matches)
+	data.Recipe = recipe
+	data.RecipeCollections = recipeCollections
+	data.RecipeIngredients = newRecipeIngredients(recipe, components, scale)
+	data.RecipeScales = recipeScales
+	data.ReferencedBy = referencedBy
+	data.Scale = scale
+
+	app.render(w, r, status, "recipe", data)
+}
+
+func (app *application) duplicateRecipePost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	copyID, err := app.recipeModel.Duplicate(r.Context(), userID, recipeID)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	app.sendRecipeEvents(r, userID, models.EventRecipeCreated, copyID)
+
+	http.Redirect(w, r, "/recipes/"+copyID.String()+"/edit", http.StatusSeeOther)
+}
+
+// recipeChange is a field of a recipe that differs between two versions of the recipe.
+type recipeChange struct {
+	Field  string
+	Mine   string
+	Theirs string
+}
+
+// recipeChanges lists the fields of a recipe form that differ from the saved version of the
+// recipe. Categories and households are described by name.
+func recipeChanges(mine RecipeForm, theirs RecipeForm, categories []models.Category, households []models.Household) []recipeChange {
+	categoryName := func(id string) string {
+		for _, category := range categories {
+			if category.ID.String() == id {
+				return category.Name
+			}
+		}
+
+		return "Uncategorized"
+	}
+
+	householdName := func(id string) string {
+		for _, household := range households {
+			if household.ID.String() == id {
+				return household.Name
+			}
+		}
+
+		return "Just me"
+	}
+
+	fields := []recipeChange{
+		{"Title", mine.Title, theirs.Title},
+		{"Category", categoryName(mine.Category), categoryName(theirs.Category)},
+		{"Household", householdName(mine.Household), householdName(theirs.Household)},
+		{"Servings", mine.Servings, theirs.Servings},
+		{"Prep time (minutes)", mine.PrepTime, theirs.PrepTime},
+		{"Cook time (minutes)", mine.CookTime, theirs.CookTime},
+		{"Total time (minutes)", mine.TotalTime, theirs.TotalTime},
+		{"Ingredients", mine.Ingredients, theirs.Ingredients},
+		{"Instructions", mine.Instructions, theirs.Instructions},
+		{"Source", mine.SourceName, theirs.SourceName},
+		{"Source URL", mine.SourceURL, theirs.SourceURL},
+	}
+
+	changes := make([]recipeChange, 0, len(fields))
+	for _, field := range fields {
+		if field.Mine != field.Theirs {
+			changes = append(changes, field)
+		}
+	}
+
+	return changes
+}
+
+// renderRecipeConflict renders a comparison of the user's edits with the current version of a
+// recipe that was modified after the user started editing it. The user's edits are resubmitted
+// against the current version so that they may either overwrite it or merge the two versions.
+func (app *application) renderRecipeConflict(
+	w http.ResponseWriter,
+	r *http.Request,
+	userID string,
+	id uuid.UUID,
+	data templateData,
+	form *RecipeForm,
+) {
+	current, err := app.recipeModel.GetByID(r.Context(), userID, id)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	// The submitted values are compared as they were entered, since defaults filled in when saving,
+	// such as the total time, aren't changes the user made.
+	mine := *form
+	form.Version = recipeVersion(current)
+
+	data.Form = form
+	data.Recipe = current
+	data.RecipeChanges = recipeChanges(mine, recipeFormFor(current), data.Categories, data.Households)
+
+	app.render(w, r, http.StatusConflict, "recipe-conflict", data)
 }
@@ -5,11 +5,13 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"golang.org/x/net/html"
 
 	"github.com/cdriehuys/recipes/internal/assert"
 	"github.com/cdriehuys/recipes/internal/htmlutils"
+	"github.com/cdriehuys/recipes/internal/models"
 	"github.com/cdriehuys/recipes/internal/models/mock"
 	"github.com/google/uuid"
 )
@@ -75,6 +77,8 @@ func Test_application_newRecipePost(t *testing.T) {
 		name                  string
 		title                 string
 		category              string
+		household             string
+		servings              string
 		instructions          string
 		wantStatus            int
 		wantValidationMessage string
@@ -95,6 +99,38 @@ func Test_application_newRecipePost(t *testing.T) {
 			wantStatus:   http.StatusSeeOther,
 			wantCreated:  true,
 		},
+		{
+			name:         "valid with household",
+			title:        "Test",
+			household:    mock.Household.ID.String(),
+			instructions: "Do the thing.",
+			wantStatus:   http.StatusSeeOther,
+			wantCreated:  true,
+		},
+		{
+			name:         "valid with servings",
+			title:        "Test",
+			servings:     "4",
+			instructions: "Do the thing.",
+			wantStatus:   http.StatusSeeOther,
+			wantCreated:  true,
+		},
+		{
+			name:                  "servings out of range",
+			title:                 "Test",
+			servings:              "0",
+			instructions:          "Do the thing.",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be a whole number from 1 to 100.",
+		},
+		{
+			name:                  "read only household",
+			title:                 "Test",
+			household:             mock.ViewableHousehold.ID.String(),
+			instructions:          "Do the thing.",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "You may not add recipes to this household.",
+		},
 		{
 			name:                  "missing title",
 			title:                 "",
@@ -132,6 +168,9 @@ func Test_application_newRecipePost(t *testing.T) {
 			form.Add("csrf_token", csrfToken)
 			form.Add("title", tt.title)
 			form.Add("category", tt.category)
+			form.Add("household", tt.household)
+			form.Add("servings", tt.servings)
+			form.Add("ingredients", "2 eggs\n1 cup flour")
 			form.Add("instructions", tt.instructions)
 
 			status, headers, body := server.postForm(t, "/new-recipe", form)
@@ -147,13 +186,314 @@ func Test_application_newRecipePost(t *testing.T) {
 				assert.Equal(t, mock.TestUserNormal, created.Owner)
 				assert.Equal(t, tt.title, created.Title)
 				assert.Equal(t, tt.instructions, created.Instructions)
+				assert.Equal(t, tt.servings, optionalInt2String(created.Servings))
+				assert.Equal(t, "2 eggs\n1 cup flour", created.Ingredients)
 
 				if tt.category != "" {
 					assert.Equal(t, tt.category, created.Category.String())
 				}
 
+				if tt.household != "" {
+					assert.Equal(t, tt.household, created.Household.String())
+				}
+
 				assertRedirects(t, headers, "/recipes/"+created.ID.String())
 			}
 		})
 	}
 }
+
+func Test_application_newRecipePost_timesAndSource(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, formResponse := server.get(t, "/new-recipe")
+	csrfToken := extractCSRFToken(t, formResponse)
+
+	testCases := []struct {
+		name                  string
+		prepTime              string
+		cookTime              string
+		totalTime             string
+		sourceName            string
+		sourceURL             string
+		wantStatus            int
+		wantValidationMessage string
+		wantTotalTime         string
+	}{
+		{
+			name:       "blank",
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:          "total defaults to prep and cook",
+			prepTime:      "15",
+			cookTime:      "25",
+			wantStatus:    http.StatusSeeOther,
+			wantTotalTime: "40",
+		},
+		{
+			name:          "total includes resting time",
+			prepTime:      "15",
+			cookTime:      "25",
+			totalTime:     "100",
+			wantStatus:    http.StatusSeeOther,
+			wantTotalTime: "100",
+		},
+		{
+			name:          "only total",
+			totalTime:     "0",
+			wantStatus:    http.StatusSeeOther,
+			wantTotalTime: "0",
+		},
+		{
+			name:                  "total less than prep and cook",
+			prepTime:              "15",
+			cookTime:              "25",
+			totalTime:             "30",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field may not be less than the prep and cook times combined.",
+		},
+		{
+			name:                  "negative time",
+			prepTime:              "-5",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be a whole number of minutes from 0 to 10080.",
+		},
+		{
+			name:                  "time not a number",
+			cookTime:              "1 hour",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be a whole number of minutes from 0 to 10080.",
+		},
+		{
+			name:       "source",
+			sourceName: "Grandma",
+			sourceURL:  "https://example.com/recipes/pie",
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:                  "source name too long",
+			sourceName:            strings.Repeat("a", 201),
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field may not contain more than 200 characters.",
+		},
+		{
+			name:                  "source URL not a web address",
+			sourceURL:             "javascript:alert(1)",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be a web address starting with http:// or https://.",
+		},
+		{
+			name:                  "source URL relative",
+			sourceURL:             "example.com",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be a web address starting with http:// or https://.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("title", "Test")
+			form.Add("instructions", "Do the thing.")
+			form.Add("prepTime", tt.prepTime)
+			form.Add("cookTime", tt.cookTime)
+			form.Add("totalTime", tt.totalTime)
+			form.Add("sourceName", tt.sourceName)
+			form.Add("sourceURL", tt.sourceURL)
+
+			status, _, body := server.postForm(t, "/new-recipe", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+				return
+			}
+
+			created := app.recipeModel.(*mock.RecipeModel).LastCreatedRecipe
+			assert.Equal(t, tt.prepTime, optionalMinutesString(created.PrepTime))
+			assert.Equal(t, tt.cookTime, optionalMinutesString(created.CookTime))
+			assert.Equal(t, tt.wantTotalTime, optionalMinutesString(created.TotalTime))
+			assert.Equal(t, tt.sourceName, created.SourceName)
+			assert.Equal(t, tt.sourceURL, created.SourceURL)
+		})
+	}
+}
+
+func Test_application_getRecipe_timesAndSource(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	status, _, body := server.get(t, "/recipes/"+uuid.NewString())
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.StringContains(t, body, `<time datetime="PT5M">5 min</time>`)
+	assert.StringContains(t, body, `<time datetime="PT1H5M">1 hr 5 min</time>`)
+	assert.StringContains(t, body, `<a class="underline" href="https://example.com/mock-recipe" rel="noopener noreferrer">Mock Kitchen</a>`)
+}
+
+func Test_application_duplicateRecipePost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	recipeID := uuid.New()
+	duplicateURL := "/recipes/" + recipeID.String() + "/duplicate"
+
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/recipes/"+recipeID.String())
+	assert.StringContains(t, page, `action="`+duplicateURL+`"`)
+
+	form := url.Values{}
+	form.Add("csrf_token", extractCSRFToken(t, page))
+
+	status, headers, _ := server.postForm(t, duplicateURL, form)
+
+	assert.Equal(t, http.StatusSeeOther, status)
+	assertRedirects(t, headers, "/recipes/"+mock.DuplicateRecipeID.String()+"/edit")
+	assert.Equal(t, recipeID, app.recipeModel.(*mock.RecipeModel).LastDuplicatedID)
+}
+
+func Test_application_getRecipe_forkedFrom(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	status, _, body := server.get(t, "/recipes/"+mock.DuplicateRecipeID.String())
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.StringContains(t, body, `Copied from <a class="underline" href="/recipes/`+mock.SubRecipe.ID.String()+`">Pizza Dough</a>`)
+}
+
+func Test_application_editRecipePost_conflict(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	recipeID := uuid.New()
+	editURL := "/recipes/" + recipeID.String() + "/edit"
+
+	_, _, formResponse := server.get(t, editURL)
+	csrfToken := extractCSRFToken(t, formResponse)
+
+	currentVersion := recipeVersion(mock.Recipe)
+	assert.StringContains(t, formResponse, currentVersion)
+
+	testCases := []struct {
+		name                  string
+		version               string
+		wantStatus            int
+		wantValidationMessage string
+	}{
+		{
+			name:       "current version",
+			version:    currentVersion,
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:       "stale version",
+			version:    mock.Recipe.UpdatedAt.Add(-time.Minute).Format(time.RFC3339Nano),
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "no version",
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:                  "invalid version",
+			version:               "yesterday",
+			wantStatus:            http.StatusOK,
+			wantValidationMessage: "This form is out of date. Reload the page and try again.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recipeModel := app.recipeModel.(*mock.RecipeModel)
+			recipeModel.LastUpdatedRecipe = models.Recipe{}
+
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("title", "My Edit")
+			form.Add("instructions", mock.Recipe.Instructions)
+			form.Add("version", tt.version)
+
+			status, headers, body := server.postForm(t, editURL, form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			switch {
+			case tt.wantValidationMessage != "":
+				assert.StringContains(t, body, tt.wantValidationMessage)
+				assert.Equal(t, uuid.UUID{}, recipeModel.LastUpdatedRecipe.ID)
+			case tt.wantStatus == http.StatusConflict:
+				assert.Equal(t, uuid.UUID{}, recipeModel.LastUpdatedRecipe.ID)
+
+				// Both versions are shown, and resubmitting targets the current version.
+				assert.StringContains(t, body, "My Edit")
+				assert.StringContains(t, body, mock.Recipe.Title)
+				assert.StringContains(t, body, `name="version" value="`+currentVersion+`"`)
+			default:
+				assertRedirects(t, headers, "/recipes/"+recipeID.String())
+				assert.Equal(t, recipeID, recipeModel.LastUpdatedRecipe.ID)
+				assert.Equal(t, "My Edit", recipeModel.LastUpdatedRecipe.Title)
+			}
+		})
+	}
+}
+
+func Test_application_editRecipePost_conflictShowsSubmittedValues(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	editURL := "/recipes/" + uuid.New().String() + "/edit"
+
+	_, _, formResponse := server.get(t, editURL)
+
+	form := url.Values{}
+	form.Add("csrf_token", extractCSRFToken(t, formResponse))
+	form.Add("title", mock.Recipe.Title)
+	form.Add("ingredients", mock.Recipe.Ingredients)
+	form.Add("instructions", mock.Recipe.Instructions)
+	form.Add("prepTime", "5")
+	form.Add("cookTime", "60")
+	form.Add("sourceName", mock.Recipe.SourceName)
+	form.Add("sourceURL", mock.Recipe.SourceURL)
+	form.Add("version", mock.Recipe.UpdatedAt.Add(-time.Minute).Format(time.RFC3339Nano))
+
+	status, _, body := server.postForm(t, editURL, form)
+
+	assert.Equal(t, http.StatusConflict, status)
+
+	// Saving would fill in the cleared total time from the prep and cook times, but the user
+	// cleared it, so that is shown as their change.
+	assert.StringContains(t, body, "Total time (minutes)")
+	if strings.Contains(body, "Prep time (minutes)") {
+		t.Error("Expected unchanged fields to be left out of the comparison")
+	}
+}
+
+func Test_recipeChanges(t *testing.T) {
+	category := models.Category{ID: uuid.New(), Name: "Dinner"}
+
+	mine := RecipeForm{Title: "Soup", Category: category.ID.String(), Instructions: "Simmer.", Version: "a"}
+	theirs := RecipeForm{Title: "Soup", Instructions: "Boil.", Version: "b"}
+
+	changes := recipeChanges(mine, theirs, []models.Category{category}, nil)
+
+	want := []recipeChange{
+		{Field: "Category", Mine: "Dinner", Theirs: "Uncategorized"},
+		{Field: "Instructions", Mine: "Simmer.", Theirs: "Boil."},
+	}
+
+	assert.Equal(t, len(want), len(changes))
+	for i := range want {
+		assert.Equal(t, want[i], changes[i])
+	}
+}
@@ -17,27 +17,122 @@ func (app *application) routes() http.Handler {
 		standard.Then(http.StripPrefix("/static/", app.staticServer)),
 	)
 
+	mux.Handle("GET /api/openapi.json", standard.ThenFunc(app.apiDocument))
+
 	dynamic := standard.Append(app.sessionManager.LoadAndSave, app.noSurf, app.authenticate)
+	pages := dynamic.Append(app.rateLimit(app.pageLimiter))
+	auth := dynamic.Append(app.rateLimit(app.authLimiter))
+
+	mux.Handle("GET /{$}", pages.ThenFunc(app.index))
+	mux.Handle("GET /auth/callback", auth.ThenFunc(app.oauthCallback))
+	mux.Handle("GET /auth/login", auth.ThenFunc(app.login))
+	mux.Handle("GET /c/{token}", pages.ThenFunc(app.sharedCollection))
+	mux.Handle("GET /c/{token}/feed", pages.ThenFunc(app.collectionFeed))
+	mux.Handle("GET /feeds/{token}", pages.ThenFunc(app.userFeed))
+	mux.Handle("GET /privacy-policy", pages.ThenFunc(app.privacyPolicy))
+	mux.Handle("GET /s/{token}", pages.ThenFunc(app.sharedRecipe))
+
+	api := dynamic.Append(app.authenticateBearer, app.rateLimit(app.apiLimiter), app.requireAPIAuthentication)
+
+	mux.Handle("GET /api/v1/categories", api.ThenFunc(app.apiListCategories))
+	mux.Handle("POST /api/v1/categories", api.ThenFunc(app.apiCreateCategory))
+	mux.Handle("GET /api/v1/categories/{categoryID}", api.ThenFunc(app.apiGetCategory))
+	mux.Handle("PUT /api/v1/categories/{categoryID}", api.ThenFunc(app.apiUpdateCategory))
+	mux.Handle("DELETE /api/v1/categories/{categoryID}", api.ThenFunc(app.apiDeleteCategory))
+	mux.Handle("GET /api/v1/recipes", api.ThenFunc(app.apiListRecipes))
+	mux.Handle("POST /api/v1/recipes", api.ThenFunc(app.apiCreateRecipe))
+	mux.Handle("GET /api/v1/recipes/{recipeID}", api.ThenFunc(app.apiGetRecipe))
+	mux.Handle("PUT /api/v1/recipes/{recipeID}", api.ThenFunc(app.apiUpdateRecipe))
+	mux.Handle("DELETE /api/v1/recipes/{recipeID}", api.ThenFunc(app.apiDeleteRecipe))
 
-	mux.Handle("GET /{$}", dynamic.ThenFunc(app.index))
-	mux.Handle("GET /auth/callback", dynamic.ThenFunc(app.oauthCallback))
-	mux.Handle("GET /auth/login", dynamic.ThenFunc(app.login))
-	mux.Handle("GET /privacy-policy", dynamic.ThenFunc(app.privacyPolicy))
+	requiresAuth := pages.Append(app.requireAuthentication)
 
-	requiresAuth := dynamic.Append(app.requireAuthentication)
+	// Pages that can also be requested as JSON accept access tokens for scripts.
+	negotiated := dynamic.Append(app.authenticateBearer, app.rateLimit(app.pageLimiter), app.requireAuthentication)
 
 	mux.Handle("GET /auth/complete-registration", requiresAuth.ThenFunc(app.completeRegistration))
 	mux.Handle("POST /auth/complete-registration", requiresAuth.ThenFunc(app.completeRegistrationPost))
 	mux.Handle("POST /auth/logout", requiresAuth.ThenFunc(app.logout))
+	mux.Handle("GET /collections", requiresAuth.ThenFunc(app.listCollections))
+	mux.Handle("POST /collections", requiresAuth.ThenFunc(app.listCollectionsPost))
+	mux.Handle("GET /collections/{collectionID}", requiresAuth.ThenFunc(app.getCollection))
+	mux.Handle("POST /collections/{collectionID}/delete", requiresAuth.ThenFunc(app.deleteCollectionPost))
+	mux.Handle("GET /collections/{collectionID}/edit", requiresAuth.ThenFunc(app.editCollection))
+	mux.Handle("POST /collections/{collectionID}/edit", requiresAuth.ThenFunc(app.editCollectionPost))
+	mux.Handle("POST /collections/{collectionID}/order", requiresAuth.ThenFunc(app.collectionOrderPost))
+	mux.Handle("GET /collections/{collectionID}/print", requiresAuth.ThenFunc(app.printCollection))
+	mux.Handle("POST /collections/{collectionID}/recipes/{recipeID}/remove", requiresAuth.ThenFunc(app.removeCollectionRecipePost))
+	mux.Handle("POST /collections/{collectionID}/share", requiresAuth.ThenFunc(app.shareCollectionPost))
+	mux.Handle("POST /collections/{collectionID}/unshare", requiresAuth.ThenFunc(app.unshareCollectionPost))
+	mux.Handle("GET /drafts", requiresAuth.ThenFunc(app.listDrafts))
+	mux.Handle("POST /drafts", requiresAuth.ThenFunc(app.draftsPost))
+	mux.Handle("POST /drafts/{draftID}/delete", requiresAuth.ThenFunc(app.deleteDraftPost))
+	mux.Handle("GET /households", requiresAuth.ThenFunc(app.listHouseholds))
+	mux.Handle("POST /households", requiresAuth.ThenFunc(app.listHouseholdsPost))
+	mux.Handle("GET /households/{householdID}", requiresAuth.ThenFunc(app.getHousehold))
+	mux.Handle("POST /households/{householdID}/invitations", requiresAuth.ThenFunc(app.householdInvitationPost))
+	mux.Handle("POST /households/{householdID}/members/{memberID}/remove", requiresAuth.ThenFunc(app.householdMemberRemovePost))
+	mux.Handle("POST /households/{householdID}/members/{memberID}/role", requiresAuth.ThenFunc(app.householdMemberRolePost))
+	mux.Handle("GET /invitations/{token}", requiresAuth.ThenFunc(app.householdInvitation))
+	mux.Handle("POST /invitations/{token}/accept", requiresAuth.ThenFunc(app.acceptHouseholdInvitationPost))
+	mux.Handle("GET /meal-plan", requiresAuth.ThenFunc(app.mealPlan))
+	mux.Handle("POST /meal-plan", requiresAuth.ThenFunc(app.mealPlanPost))
+	mux.Handle("POST /meal-plan/copy-previous-week", requiresAuth.ThenFunc(app.copyMealPlanWeekPost))
+	mux.Handle("POST /meal-plan/items/{itemID}/delete", requiresAuth.ThenFunc(app.deleteMealPlanItemPost))
 	mux.Handle("GET /new-category", requiresAuth.ThenFunc(app.newCategory))
 	mux.Handle("POST /new-category", requiresAuth.ThenFunc(app.newCategoryPost))
 	mux.Handle("GET /new-recipe", requiresAuth.ThenFunc(app.addRecipe))
 	mux.Handle("POST /new-recipe", requiresAuth.ThenFunc(app.addRecipePost))
-	mux.Handle("GET /recipes", requiresAuth.ThenFunc(app.listRecipes))
-	mux.Handle("GET /recipes/{recipeID}", requiresAuth.ThenFunc(app.getRecipe))
+	mux.Handle("GET /pantry", requiresAuth.ThenFunc(app.pantry))
+	mux.Handle("POST /pantry", requiresAuth.ThenFunc(app.pantryPost))
+	mux.Handle("POST /pantry/items/{itemID}/delete", requiresAuth.ThenFunc(app.deletePantryItemPost))
+	mux.Handle("GET /recipes", negotiated.ThenFunc(app.listRecipes))
+	mux.Handle("POST /recipes/bulk", requiresAuth.ThenFunc(app.bulkRecipesPost))
+	mux.Handle("GET /recipes/cookable", requiresAuth.ThenFunc(app.cookableRecipes))
+	mux.Handle("GET /recipes/{recipeID}", negotiated.ThenFunc(app.getRecipe))
+	mux.Handle("POST /recipes/{recipeID}/collections", requiresAuth.ThenFunc(app.recipeCollectionsPost))
+	mux.Handle("GET /recipes/{recipeID}/components", requiresAuth.ThenFunc(app.recipeComponentLinks))
+	mux.Handle("POST /recipes/{recipeID}/components", requiresAuth.ThenFunc(app.recipeComponentLinksPost))
+	mux.Handle("GET /recipes/{recipeID}/cook", requiresAuth.ThenFunc(app.cookMode))
+	mux.Handle("GET /recipes/{recipeID}/cooked", requiresAuth.ThenFunc(app.cookedRecipe))
+	mux.Handle("POST /recipes/{recipeID}/cooked", requiresAuth.ThenFunc(app.cookedRecipePost))
+	mux.Handle("POST /recipes/{recipeID}/cooked/{entryID}/delete", requiresAuth.ThenFunc(app.deleteCookLogEntryPost))
 	mux.Handle("POST /recipes/{recipeID}/delete", requiresAuth.ThenFunc(app.deleteRecipePost))
+	mux.Handle("POST /recipes/{recipeID}/duplicate", requiresAuth.ThenFunc(app.duplicateRecipePost))
 	mux.Handle("GET /recipes/{recipeID}/edit", requiresAuth.ThenFunc(app.editRecipe))
 	mux.Handle("POST /recipes/{recipeID}/edit", requiresAuth.ThenFunc(app.editRecipePost))
+	mux.Handle("POST /recipes/{recipeID}/favorite", requiresAuth.ThenFunc(app.favoriteRecipePost))
+	mux.Handle("GET /recipes/{recipeID}/labels", requiresAuth.ThenFunc(app.recipeLabels))
+	mux.Handle("POST /recipes/{recipeID}/labels", requiresAuth.ThenFunc(app.recipeLabelsPost))
+	mux.Handle("GET /recipes/{recipeID}/nutrition", requiresAuth.ThenFunc(app.recipeNutrition))
+	mux.Handle("POST /recipes/{recipeID}/nutrition", requiresAuth.ThenFunc(app.recipeNutritionPost))
+	mux.Handle("POST /recipes/{recipeID}/rating", requiresAuth.ThenFunc(app.rateRecipePost))
+	mux.Handle("GET /recipes/{recipeID}/shares", requiresAuth.ThenFunc(app.recipeShares))
+	mux.Handle("POST /recipes/{recipeID}/shares", requiresAuth.ThenFunc(app.recipeSharesPost))
+	mux.Handle("POST /recipes/{recipeID}/shares/{shareID}/revoke", requiresAuth.ThenFunc(app.revokeRecipeSharePost))
+	mux.Handle("GET /shopping-list", requiresAuth.ThenFunc(app.shoppingList))
+	mux.Handle("POST /shopping-list", requiresAuth.ThenFunc(app.shoppingListPost))
+	mux.Handle("POST /shopping-list/clear-checked", requiresAuth.ThenFunc(app.clearCheckedShoppingListPost))
+	mux.Handle("POST /shopping-list/items/{itemID}/aisle", requiresAuth.ThenFunc(app.shoppingListItemAislePost))
+	mux.Handle("POST /shopping-list/items/{itemID}/check", requiresAuth.ThenFunc(app.checkShoppingListItemPost))
+	mux.Handle("POST /shopping-list/items/{itemID}/delete", requiresAuth.ThenFunc(app.deleteShoppingListItemPost))
+	mux.Handle("GET /shopping-list/items/{itemID}/edit", requiresAuth.ThenFunc(app.editShoppingListItem))
+	mux.Handle("POST /shopping-list/items/{itemID}/edit", requiresAuth.ThenFunc(app.editShoppingListItemPost))
+	mux.Handle("POST /shopping-list/meal-plan", requiresAuth.ThenFunc(app.shoppingListMealPlanPost))
+	mux.Handle("POST /shopping-list/recipes", requiresAuth.ThenFunc(app.shoppingListRecipesPost))
+	mux.Handle("GET /store-layout", requiresAuth.ThenFunc(app.storeLayout))
+	mux.Handle("POST /store-layout", requiresAuth.ThenFunc(app.storeLayoutPost))
+	mux.Handle("POST /store-layout/{aisleID}/delete", requiresAuth.ThenFunc(app.deleteAislePost))
+	mux.Handle("POST /store-layout/{aisleID}/move", requiresAuth.ThenFunc(app.moveAislePost))
+	mux.Handle("GET /tokens", requiresAuth.ThenFunc(app.listTokens))
+	mux.Handle("POST /tokens", requiresAuth.ThenFunc(app.listTokensPost))
+	mux.Handle("POST /tokens/feed", requiresAuth.ThenFunc(app.feedTokenPost))
+	mux.Handle("POST /tokens/feed/disable", requiresAuth.ThenFunc(app.disableFeedPost))
+	mux.Handle("POST /tokens/{tokenID}/revoke", requiresAuth.ThenFunc(app.revokeTokenPost))
+	mux.Handle("GET /webhooks", requiresAuth.ThenFunc(app.listWebhooks))
+	mux.Handle("POST /webhooks", requiresAuth.ThenFunc(app.listWebhooksPost))
+	mux.Handle("GET /webhooks/{webhookID}", requiresAuth.ThenFunc(app.getWebhook))
+	mux.Handle("POST /webhooks/{webhookID}/delete", requiresAuth.ThenFunc(app.deleteWebhookPost))
 
 	return mux
 }
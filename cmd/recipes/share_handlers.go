@@ -0,0 +1,147 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/validation"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type shareForm struct {
+	ExpiresInDays string
+	validation.Validator
+}
+
+func (form *shareForm) Validate() {
+	form.CheckField(
+		validation.PermittedValue(form.ExpiresInDays, "", "1", "7", "30"),
+		"expires",
+		"This field must be one of the provided options.",
+	)
+}
+
+// expiresAt returns the expiration time of the share relative to the provided time.
+func (form *shareForm) expiresAt(now time.Time) pgtype.Timestamptz {
+	days, err := strconv.Atoi(form.ExpiresInDays)
+	if err != nil {
+		return pgtype.Timestamptz{}
+	}
+
+	return pgtype.Timestamptz{Time: now.AddDate(0, 0, days), Valid: true}
+}
+
+func (app *application) recipeShares(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	app.renderRecipeShares(w, r, http.StatusOK, userID, recipeID, &shareForm{})
+}
+
+func (app *application) recipeSharesPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	form := shareForm{
+		ExpiresInDays: r.PostFormValue("expires"),
+	}
+	form.Validate()
+
+	if !form.IsValid() {
+		app.renderRecipeShares(w, r, http.StatusUnprocessableEntity, userID, recipeID, &form)
+		return
+	}
+
+	token, err := generateToken(32)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	share := models.RecipeShare{
+		ID:        uuid.New(),
+		Recipe:    recipeID,
+		Token:     token,
+		ExpiresAt: form.expiresAt(time.Now()),
+	}
+	if err := app.shareModel.Create(r.Context(), userID, share); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/recipes/"+recipeID.String()+"/shares", http.StatusSeeOther)
+}
+
+func (app *application) revokeRecipeSharePost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	recipeID, ok := app.uuidPathValue(w, r, "recipeID")
+	if !ok {
+		return
+	}
+
+	shareID, ok := app.uuidPathValue(w, r, "shareID")
+	if !ok {
+		return
+	}
+
+	if err := app.shareModel.Revoke(r.Context(), userID, recipeID, shareID); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/recipes/"+recipeID.String()+"/shares", http.StatusSeeOther)
+}
+
+// sharedRecipe renders the public, read-only view of a recipe referenced by a share token.
+func (app *application) sharedRecipe(w http.ResponseWriter, r *http.Request) {
+	recipe, err := app.shareModel.GetRecipe(r.Context(), r.PathValue("token"))
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Recipe = recipe
+
+	app.render(w, r, http.StatusOK, "shared-recipe", data)
+}
+
+func (app *application) renderRecipeShares(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	userID string,
+	recipeID uuid.UUID,
+	form *shareForm,
+) {
+	recipe, err := app.recipeModel.GetByID(r.Context(), userID, recipeID)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	shares, err := app.shareModel.ListForRecipe(r.Context(), userID, recipeID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = form
+	data.Recipe = recipe
+	data.Shares = shares
+
+	app.render(w, r, status, "recipe-shares", data)
+}
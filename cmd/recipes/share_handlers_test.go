@@ -0,0 +1,149 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_application_sharedRecipe(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	t.Run("valid token", func(t *testing.T) {
+		status, _, body := server.get(t, "/s/"+mock.ValidShareToken)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, mock.SharedRecipe.Title)
+
+		if strings.Contains(body, "/edit") || strings.Contains(body, "/delete") {
+			t.Errorf("Expected shared recipe to contain no edit or delete actions; got %q", body)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		status, _, _ := server.get(t, "/s/foo")
+
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func Test_application_recipeShares(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	recipeID := uuid.New()
+	sharesURL := "/recipes/" + recipeID.String() + "/shares"
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, sharesURL)
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, sharesURL)
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, _ := server.get(t, sharesURL)
+
+		assert.Equal(t, http.StatusOK, status)
+	})
+}
+
+func Test_application_recipeSharesPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	recipeID := uuid.New()
+	sharesURL := "/recipes/" + recipeID.String() + "/shares"
+
+	_, _, formResponse := server.get(t, sharesURL)
+	csrfToken := extractCSRFToken(t, formResponse)
+
+	testCases := []struct {
+		name                  string
+		expires               string
+		wantStatus            int
+		wantValidationMessage string
+		wantExpiry            bool
+	}{
+		{
+			name:       "never expires",
+			expires:    "",
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:       "expires in a week",
+			expires:    "7",
+			wantStatus: http.StatusSeeOther,
+			wantExpiry: true,
+		},
+		{
+			name:                  "invalid expiry",
+			expires:               "365",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be one of the provided options.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			shareModel := app.shareModel.(*mock.ShareModel)
+			shareModel.LastCreatedShare.Token = ""
+
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("expires", tt.expires)
+
+			status, headers, body := server.postForm(t, sharesURL, form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+				assert.Equal(t, "", shareModel.LastCreatedShare.Token)
+				return
+			}
+
+			created := shareModel.LastCreatedShare
+			assert.Equal(t, recipeID, created.Recipe)
+			assert.Equal(t, tt.wantExpiry, created.ExpiresAt.Valid)
+
+			if len(created.Token) < 32 {
+				t.Errorf("Expected an unguessable token; got %q", created.Token)
+			}
+
+			assertRedirects(t, headers, sharesURL)
+		})
+	}
+}
+
+func Test_application_revokeRecipeSharePost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	recipeID := uuid.New()
+	shareID := uuid.New()
+	sharesURL := "/recipes/" + recipeID.String() + "/shares"
+
+	_, _, formResponse := server.get(t, sharesURL)
+	csrfToken := extractCSRFToken(t, formResponse)
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+
+	status, headers, _ := server.postForm(t, sharesURL+"/"+shareID.String()+"/revoke", form)
+
+	assert.Equal(t, http.StatusSeeOther, status)
+	assert.Equal(t, shareID, app.shareModel.(*mock.ShareModel).LastRevokedShare)
+	assert.Equal(t, recipeID, app.shareModel.(*mock.ShareModel).LastRevokedShareRecipe)
+	assertRedirects(t, headers, sharesURL)
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/ingredients"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/validation"
+	"github.com/google/uuid"
+)
+
+// maxShoppingListDays is the longest range of planned meals a shopping list can be built from.
+const maxShoppingListDays = 31
+
+// shoppingListForm holds the inputs of each of the forms used to add items to a shopping list.
+// Only the fields of the submitted form are validated.
+type shoppingListForm struct {
+	Item    string
+	Recipes []string
+	Start   string
+	End     string
+	validation.Validator
+}
+
+// checkItem validates a single manually entered shopping list or pantry item such as "2 lbs apples".
+func checkItem(v *validation.Validator, item string) {
+	v.CheckField(validation.NotBlank(item), "item", "This field is required.")
+	v.CheckField(validation.MaxLength(item, 200), "item", "This field may not contain more than 200 characters.")
+
+	if v.IsValid() {
+		v.CheckField(ingredients.Parse(item).Name != "", "item", "This field must include the name of the item.")
+	}
+}
+
+func (form *shoppingListForm) validateItem() {
+	checkItem(&form.Validator, form.Item)
+}
+
+func (form *shoppingListForm) validateRecipes() {
+	form.CheckField(len(form.Recipes) > 0, "recipes", "Select at least one recipe.")
+
+	for _, id := range form.Recipes {
+		if _, err := uuid.Parse(id); err != nil {
+			form.AddFieldError("recipes", "This field must only contain valid recipe IDs.")
+			break
+		}
+	}
+}
+
+func (form *shoppingListForm) validateMealPlan() {
+	form.CheckField(validation.ValidDate(form.Start), "start", "This field must be a valid date.")
+	form.CheckField(validation.ValidDate(form.End), "end", "This field must be a valid date.")
+
+	if form.IsValid() {
+		start, end := form.dateRange()
+		form.CheckField(!end.Before(start), "end", "This date may not be before the start date.")
+		form.CheckField(
+			end.Sub(start) < maxShoppingListDays*24*time.Hour,
+			"end",
+			"A shopping list may cover at most 31 days of meals.",
+		)
+	}
+}
+
+// dateRange returns the validated first and last days of planned meals to shop for.
+func (form *shoppingListForm) dateRange() (time.Time, time.Time) {
+	start, _ := time.Parse(time.DateOnly, form.Start)
+	end, _ := time.Parse(time.DateOnly, form.End)
+
+	return start, end
+}
+
+// newShoppingListForm returns a form defaulting to the meals planned for the current week.
+func newShoppingListForm() *shoppingListForm {
+	week := weekStart(time.Now())
+
+	return &shoppingListForm{
+		Start: week.Format(time.DateOnly),
+		End:   week.AddDate(0, 0, 6).Format(time.DateOnly),
+	}
+}
+
+type shoppingListItemForm struct {
+	Item string
+	validation.Validator
+}
+
+func (form *shoppingListItemForm) Validate() {
+	checkItem(&form.Validator, form.Item)
+}
+
+// shoppingListItems combines the ingredients of the recipes with the provided IDs into shopping list
+// items. A recipe may be included multiple times, in which case its ingredients are added for each
+// occurrence. Ingredients made from other recipes are replaced by those recipes' ingredients.
+func (app *application) shoppingListItems(r *http.Request, userID string, recipeIDs []uuid.UUID) ([]models.ShoppingListItem, error) {
+	recipes := make(map[uuid.UUID]models.Recipe)
+
+	var list []ingredients.Ingredient
+	for _, id := range recipeIDs {
+		recipe, ok := recipes[id]
+		if !ok {
+			var err error
+			recipe, err = app.recipeModel.GetByID(r.Context(), userID, id)
+			if err != nil {
+				return nil, err
+			}
+
+			recipes[id] = recipe
+		}
+
+		expanded, err := app.expandedIngredients(r.Context(), userID, recipe, 1, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, expanded...)
+	}
+
+	combined := ingredients.Aggregate(list)
+
+	items := make([]models.ShoppingListItem, 0, len(combined))
+	for _, ingredient := range combined {
+		if ingredient.Name != "" {
+			items = append(items, models.NewShoppingListItem(userID, ingredient))
+		}
+	}
+
+	return items, nil
+}
+
+func (app *application) shoppingList(w http.ResponseWriter, r *http.Request) {
+	app.renderShoppingList(w, r, http.StatusOK, reqUser(r), newShoppingListForm())
+}
+
+func (app *application) shoppingListPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	form := newShoppingListForm()
+	form.Item = r.PostFormValue("item")
+	form.validateItem()
+
+	if !form.IsValid() {
+		app.renderShoppingList(w, r, http.StatusUnprocessableEntity, userID, form)
+		return
+	}
+
+	item := models.NewShoppingListItem(userID, ingredients.Parse(form.Item))
+	if err := app.shoppingListModel.Add(r.Context(), userID, []models.ShoppingListItem{item}); err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/shopping-list", http.StatusSeeOther)
+}
+
+func (app *application) shoppingListRecipesPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form := newShoppingListForm()
+	form.Recipes = r.PostForm["recipe"]
+	form.validateRecipes()
+
+	if !form.IsValid() {
+		app.renderShoppingList(w, r, http.StatusUnprocessableEntity, userID, form)
+		return
+	}
+
+	recipeIDs := make([]uuid.UUID, 0, len(form.Recipes))
+	for _, id := range form.Recipes {
+		recipeIDs = append(recipeIDs, uuid.MustParse(id))
+	}
+
+	app.addShoppingListItems(w, r, userID, recipeIDs)
+}
+
+func (app *application) shoppingListMealPlanPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	form := newShoppingListForm()
+	form.Start = r.PostFormValue("start")
+	form.End = r.PostFormValue("end")
+	form.validateMealPlan()
+
+	if !form.IsValid() {
+		app.renderShoppingList(w, r, http.StatusUnprocessableEntity, userID, form)
+		return
+	}
+
+	start, end := form.dateRange()
+	planned, err := app.mealPlanModel.ListRange(r.Context(), userID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	var recipeIDs []uuid.UUID
+	for _, item := range planned {
+		// Recipes the user can no longer view have no title, and are skipped.
+		if item.Recipe != nil && item.RecipeTitle.Valid {
+			recipeIDs = append(recipeIDs, *item.Recipe)
+		}
+	}
+
+	app.addShoppingListItems(w, r, userID, recipeIDs)
+}
+
+// addShoppingListItems adds the combined ingredients of the recipes to the user's shopping list and
+// redirects to the list.
+func (app *application) addShoppingListItems(w http.ResponseWriter, r *http.Request, userID string, recipeIDs []uuid.UUID) {
+	items, err := app.shoppingListItems(r, userID, recipeIDs)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	if len(items) > 0 {
+		if err := app.shoppingListModel.Add(r.Context(), userID, items); err != nil {
+			app.serverError(w, r, err)
+			return
+		}
+	}
+
+	http.Redirect(w, r, "/shopping-list", http.StatusSeeOther)
+}
+
+func (app *application) clearCheckedShoppingListPost(w http.ResponseWriter, r *http.Request) {
+	if err := app.shoppingListModel.DeleteChecked(r.Context(), reqUser(r)); err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/shopping-list", http.StatusSeeOther)
+}
+
+func (app *application) checkShoppingListItemPost(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := app.uuidPathValue(w, r, "itemID")
+	if !ok {
+		return
+	}
+
+	checked := r.PostFormValue("checked") == "true"
+	if err := app.shoppingListModel.SetChecked(r.Context(), reqUser(r), itemID, checked); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/shopping-list", http.StatusSeeOther)
+}
+
+func (app *application) deleteShoppingListItemPost(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := app.uuidPathValue(w, r, "itemID")
+	if !ok {
+		return
+	}
+
+	if err := app.shoppingListModel.Delete(r.Context(), reqUser(r), itemID); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/shopping-list", http.StatusSeeOther)
+}
+
+func (app *application) editShoppingListItem(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := app.uuidPathValue(w, r, "itemID")
+	if !ok {
+		return
+	}
+
+	item, err := app.shoppingListModel.Get(r.Context(), reqUser(r), itemID)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = &shoppingListItemForm{Item: item.String()}
+	data.ShoppingListItem = item
+
+	app.render(w, r, http.StatusOK, "shopping-list-item", data)
+}
+
+func (app *application) editShoppingListItemPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	itemID, ok := app.uuidPathValue(w, r, "itemID")
+	if !ok {
+		return
+	}
+
+	form := shoppingListItemForm{
+		Item: r.PostFormValue("item"),
+	}
+	form.Validate()
+
+	if !form.IsValid() {
+		item, err := app.shoppingListModel.Get(r.Context(), userID, itemID)
+		if err != nil {
+			app.modelError(w, r, err)
+			return
+		}
+
+		data := app.newTemplateData(r)
+		data.Form = &form
+		data.ShoppingListItem = item
+
+		app.render(w, r, http.StatusUnprocessableEntity, "shopping-list-item", data)
+		return
+	}
+
+	item := models.NewShoppingListItem(userID, ingredients.Parse(form.Item))
+	item.ID = itemID
+
+	if err := app.shoppingListModel.Update(r.Context(), userID, item); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/shopping-list", http.StatusSeeOther)
+}
+
+func (app *application) renderShoppingList(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	userID string,
+	form *shoppingListForm,
+) {
+	items, err := app.shoppingListModel.List(r.Context(), userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	aisles, err := app.aisleModel.List(r.Context(), userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	assignments, err := app.aisleModel.Assignments(r.Context(), userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	recipes, err := app.recipeModel.List(r.Context(), userID, models.RecipeListOptions{Sort: models.SortTitle})
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Aisles = aisles
+	data.Form = form
+	data.Recipes = recipes
+	data.ShoppingList = items
+	data.ShoppingListAisles = groupByAisle(items, aisles, assignments)
+
+	app.render(w, r, status, "shopping-list", data)
+}
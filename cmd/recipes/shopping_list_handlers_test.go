@@ -0,0 +1,350 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_application_shoppingList(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, "/shopping-list")
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, "/shopping-list")
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, body := server.get(t, "/shopping-list")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, "2 cups milk")
+		assert.StringContains(t, body, mock.Aisle.Name)
+		assert.StringContains(t, body, weekStart(time.Now()).Format(time.DateOnly))
+	})
+}
+
+func Test_application_shoppingListPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/shopping-list")
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name                  string
+		item                  string
+		wantStatus            int
+		wantValidationMessage string
+		wantItem              string
+	}{
+		{
+			name:       "with amount",
+			item:       "2 lbs apples",
+			wantStatus: http.StatusSeeOther,
+			wantItem:   "2 lbs apples",
+		},
+		{
+			name:       "name only",
+			item:       "paper towels",
+			wantStatus: http.StatusSeeOther,
+			wantItem:   "paper towels",
+		},
+		{
+			name:                  "blank",
+			item:                  " ",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field is required.",
+		},
+		{
+			name:                  "amount only",
+			item:                  "2 cups",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must include the name of the item.",
+		},
+		{
+			name:                  "too long",
+			item:                  strings.Repeat("a", 201),
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field may not contain more than 200 characters.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			model := app.shoppingListModel.(*mock.ShoppingListModel)
+			model.AddedItems = nil
+
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("item", tt.item)
+
+			status, headers, body := server.postForm(t, "/shopping-list", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+				return
+			}
+
+			assert.Equal(t, 1, len(model.AddedItems))
+			assert.Equal(t, tt.wantItem, model.AddedItems[0].String())
+			assert.Equal(t, mock.TestUserNormal, model.AddedItems[0].Owner)
+			assertRedirects(t, headers, "/shopping-list")
+		})
+	}
+}
+
+func Test_application_shoppingListRecipesPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/shopping-list")
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name                  string
+		recipes               []string
+		wantStatus            int
+		wantValidationMessage string
+		wantItems             []string
+	}{
+		{
+			name:       "single recipe",
+			recipes:    []string{uuid.NewString()},
+			wantStatus: http.StatusSeeOther,
+			wantItems:  []string{"3.06 cups milk", "2 eggs"},
+		},
+		{
+			name:       "multiple recipes",
+			recipes:    []string{uuid.NewString(), uuid.NewString()},
+			wantStatus: http.StatusSeeOther,
+			wantItems:  []string{"6.11 cups milk", "4 eggs"},
+		},
+		{
+			name:       "sub-recipes expanded",
+			recipes:    []string{mock.ParentRecipe.ID.String()},
+			wantStatus: http.StatusSeeOther,
+			wantItems:  []string{"6 cups flour", "2 cups water", "1 cup mozzarella"},
+		},
+		{
+			name:                  "no recipes",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "Select at least one recipe.",
+		},
+		{
+			name:                  "invalid recipe",
+			recipes:               []string{"foo"},
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must only contain valid recipe IDs.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			model := app.shoppingListModel.(*mock.ShoppingListModel)
+			model.AddedItems = nil
+
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			for _, recipe := range tt.recipes {
+				form.Add("recipe", recipe)
+			}
+
+			status, headers, body := server.postForm(t, "/shopping-list/recipes", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+				return
+			}
+
+			assert.Equal(t, len(tt.wantItems), len(model.AddedItems))
+			for i := range min(len(tt.wantItems), len(model.AddedItems)) {
+				assert.Equal(t, tt.wantItems[i], model.AddedItems[i].String())
+			}
+			assertRedirects(t, headers, "/shopping-list")
+		})
+	}
+}
+
+func Test_application_shoppingListMealPlanPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/shopping-list")
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name                  string
+		start                 string
+		end                   string
+		wantStatus            int
+		wantValidationMessage string
+	}{
+		{
+			name:       "week",
+			start:      "2024-05-06",
+			end:        "2024-05-12",
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:                  "end before start",
+			start:                 "2024-05-06",
+			end:                   "2024-05-05",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This date may not be before the start date.",
+		},
+		{
+			name:                  "too long",
+			start:                 "2024-05-01",
+			end:                   "2024-06-01",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "A shopping list may cover at most 31 days of meals.",
+		},
+		{
+			name:                  "invalid start",
+			start:                 "",
+			end:                   "2024-05-05",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be a valid date.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			model := app.shoppingListModel.(*mock.ShoppingListModel)
+			model.AddedItems = nil
+
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("start", tt.start)
+			form.Add("end", tt.end)
+
+			status, headers, body := server.postForm(t, "/shopping-list/meal-plan", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+				return
+			}
+
+			// The mock meal plan contains a single planned recipe.
+			assert.Equal(t, 2, len(model.AddedItems))
+			assertRedirects(t, headers, "/shopping-list")
+		})
+	}
+}
+
+func Test_application_checkShoppingListItemPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/shopping-list")
+	csrfToken := extractCSRFToken(t, page)
+
+	itemID := uuid.New()
+
+	for _, checked := range []string{"true", "false"} {
+		form := url.Values{}
+		form.Add("csrf_token", csrfToken)
+		form.Add("checked", checked)
+
+		status, headers, _ := server.postForm(t, "/shopping-list/items/"+itemID.String()+"/check", form)
+
+		model := app.shoppingListModel.(*mock.ShoppingListModel)
+		assert.Equal(t, http.StatusSeeOther, status)
+		assert.Equal(t, itemID, model.LastCheckedItem)
+		assert.Equal(t, checked == "true", model.LastChecked)
+		assertRedirects(t, headers, "/shopping-list")
+	}
+}
+
+func Test_application_editShoppingListItem(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	editURL := "/shopping-list/items/" + mock.ShoppingListItem.ID.String() + "/edit"
+
+	t.Run("unknown item", func(t *testing.T) {
+		status, _, _ := server.get(t, "/shopping-list/items/"+uuid.NewString()+"/edit")
+
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	status, _, page := server.get(t, editURL)
+	assert.Equal(t, http.StatusOK, status)
+	assert.StringContains(t, page, "2 cups milk")
+
+	csrfToken := extractCSRFToken(t, page)
+
+	t.Run("invalid", func(t *testing.T) {
+		form := url.Values{}
+		form.Add("csrf_token", csrfToken)
+		form.Add("item", "")
+
+		status, _, body := server.postForm(t, editURL, form)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.StringContains(t, body, "This field is required.")
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		form := url.Values{}
+		form.Add("csrf_token", csrfToken)
+		form.Add("item", "1 quart whole milk")
+
+		status, headers, _ := server.postForm(t, editURL, form)
+
+		updated := app.shoppingListModel.(*mock.ShoppingListModel).LastUpdatedItem
+		assert.Equal(t, http.StatusSeeOther, status)
+		assert.Equal(t, mock.ShoppingListItem.ID, updated.ID)
+		assert.Equal(t, "whole milk", updated.Name)
+		assert.Equal(t, "quart", updated.Unit)
+		assert.Equal(t, 1.0, updated.Quantity.Float64)
+		assertRedirects(t, headers, "/shopping-list")
+	})
+}
+
+func Test_application_deleteShoppingListItemPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/shopping-list")
+	csrfToken := extractCSRFToken(t, page)
+
+	itemID := uuid.New()
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+
+	status, headers, _ := server.postForm(t, "/shopping-list/items/"+itemID.String()+"/delete", form)
+	assert.Equal(t, http.StatusSeeOther, status)
+	assert.Equal(t, itemID, app.shoppingListModel.(*mock.ShoppingListModel).LastDeletedItem)
+	assertRedirects(t, headers, "/shopping-list")
+
+	status, headers, _ = server.postForm(t, "/shopping-list/clear-checked", form)
+	assert.Equal(t, http.StatusSeeOther, status)
+	assert.Equal(t, true, app.shoppingListModel.(*mock.ShoppingListModel).ClearedChecked)
+	assertRedirects(t, headers, "/shopping-list")
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"net/http"
 
+	"github.com/cdriehuys/recipes/internal/dietary"
 	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/nutrition"
 	"github.com/justinas/nosurf"
 )
 
@@ -14,17 +16,63 @@ type form interface {
 type templateData struct {
 	CSRFToken       string
 	IsAuthenticated bool
+	UserID          string
 
 	Form form
 
-	Categories []models.Category
-	Recipe     models.Recipe
-	Recipes    []models.Recipe
+	AccessTokens       []models.AccessToken
+	Aisles             []models.StoreAisle
+	Allergens          []dietary.Allergen
+	BulkActions        []bulkAction
+	Categories         []models.Category
+	Collection         models.Collection
+	Collections        []models.Collection
+	ComponentLinks     []componentLink
+	Cookable           []cookableRecipe
+	CookLog            []models.CookLogEntry
+	CookSteps          []cookStep
+	Diets              []dietary.Diet
+	Drafts             []models.RecipeDraft
+	FeedEnabled        bool
+	FeedURL            string
+	Foods              []nutrition.Food
+	Household          models.Household
+	HouseholdMembers   []models.HouseholdMember
+	Households         []models.Household
+	Invitation         models.HouseholdInvitation
+	Instructions       []textSegment
+	Invitations        []models.HouseholdInvitation
+	LabelOverrides     []labelOverride
+	ListOptions        models.RecipeListOptions
+	MaxTotalMinutes    []int
+	MealPlan           mealPlanWeek
+	NewAccessToken     string
+	Nutrition          nutritionPanel
+	NutritionMatches   []nutritionMatch
+	Pantry             []pantryEntry
+	Recipe             models.Recipe
+	RecipeChanges      []recipeChange
+	RecipeCollections  []models.Collection
+	RecipeIngredients  []recipeIngredient
+	RecipeScales       []float64
+	Recipes            []models.Recipe
+	ReferencedBy       []models.RecipeComponent
+	Scale              float64
+	Shares             []models.RecipeShare
+	ShoppingList       []models.ShoppingListItem
+	ShoppingListAisles []shoppingListAisle
+	ShoppingListItem   models.ShoppingListItem
+	TokenScopes        []string
+	Webhook            models.Webhook
+	WebhookDeliveries  []models.WebhookDelivery
+	WebhookEvents      []string
+	Webhooks           []models.Webhook
 }
 
 func (app *application) newTemplateData(r *http.Request) templateData {
 	return templateData{
 		CSRFToken:       nosurf.Token(r),
 		IsAuthenticated: isAuthenticated(r),
+		UserID:          reqUser(r),
 	}
 }
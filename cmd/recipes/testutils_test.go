@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -56,14 +57,28 @@ func newTestApp(t *testing.T) *application {
 	}
 
 	return &application{
-		logger:         logger,
-		oauthConfig:    &oauthConfig,
-		categoryModel:  &mock.CategoryModel{},
-		recipeModel:    &mock.RecipeModel{},
-		userModel:      &mock.UserModel{},
-		sessionManager: sessionManager,
-		staticServer:   &staticServer,
-		templates:      &templateWriter,
+		logger:            logger,
+		oauthConfig:       &oauthConfig,
+		aisleModel:        &mock.AisleModel{},
+		categoryModel:     &mock.CategoryModel{},
+		collectionModel:   &mock.CollectionModel{},
+		cookLogModel:      &mock.CookLogModel{},
+		draftModel:        &mock.DraftModel{},
+		householdModel:    &mock.HouseholdModel{},
+		labelModel:        &mock.LabelModel{},
+		mealPlanModel:     &mock.MealPlanModel{},
+		nutritionModel:    &mock.NutritionModel{},
+		pantryModel:       &mock.PantryModel{},
+		ratingModel:       &mock.RatingModel{},
+		recipeModel:       &mock.RecipeModel{},
+		shareModel:        &mock.ShareModel{},
+		shoppingListModel: &mock.ShoppingListModel{},
+		tokenModel:        &mock.TokenModel{},
+		userModel:         &mock.UserModel{},
+		webhookModel:      &mock.WebhookModel{},
+		sessionManager:    sessionManager,
+		staticServer:      &staticServer,
+		templates:         &templateWriter,
 	}
 }
 
@@ -152,6 +167,55 @@ func (ts *testServer) postForm(t *testing.T, urlPath string, form url.Values) (i
 	return rs.StatusCode, rs.Header, string(body)
 }
 
+// sendJSON makes a request with a JSON body to a given url path. The CSRF token is sent in a header
+// if it is not blank.
+func (ts *testServer) sendJSON(t *testing.T, method string, urlPath string, csrfToken string, body string) (int, http.Header, string) {
+	req, err := http.NewRequest(method, ts.URL+urlPath, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if csrfToken != "" {
+		req.Header.Set("X-CSRF-Token", csrfToken)
+	}
+
+	return ts.send(t, req)
+}
+
+// sendBearer makes a request with a JSON body to a given url path, authenticated by the provided
+// access token rather than the session. The body is omitted if it is blank.
+func (ts *testServer) sendBearer(t *testing.T, method string, urlPath string, token string, body string) (int, http.Header, string) {
+	req, err := http.NewRequest(method, ts.URL+urlPath, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return ts.send(t, req)
+}
+
+// send makes a request and returns the response status code, headers, and body.
+func (ts *testServer) send(t *testing.T, req *http.Request) (int, http.Header, string) {
+	rs, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer rs.Body.Close()
+	respBody, err := io.ReadAll(rs.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	respBody = bytes.TrimSpace(respBody)
+
+	return rs.StatusCode, rs.Header, string(respBody)
+}
+
 func (ts *testServer) authenticate(t *testing.T, userID string) {
 	form := url.Values{}
 	form.Add("userID", userID)
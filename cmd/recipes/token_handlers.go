@@ -0,0 +1,134 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/validation"
+	"github.com/google/uuid"
+)
+
+// accessTokenPrefix starts the secret of every personal access token, which makes leaked tokens
+// easy to recognize.
+const accessTokenPrefix = "rcp_"
+
+// sessionKeyNewAccessToken holds the secret of a newly created access token until it is shown to
+// the user. The secret is not stored anywhere else, so it is only ever shown once.
+const sessionKeyNewAccessToken = "newAccessToken"
+
+// sessionKeyNewFeedURL holds the URL of a newly created recipe feed until it is shown to the user.
+// Like an access token's secret, the feed's token is only stored as a hash.
+const sessionKeyNewFeedURL = "newFeedURL"
+
+type tokenForm struct {
+	Name   string
+	Scopes []string
+	validation.Validator
+}
+
+func (form *tokenForm) Validate() {
+	form.CheckField(validation.NotBlank(form.Name), "name", "This field is required.")
+	form.CheckField(validation.MaxLength(form.Name, 100), "name", "This field may not contain more than 100 characters.")
+
+	if len(form.Scopes) == 0 {
+		form.AddFieldError("scopes", "Select at least one scope.")
+	}
+
+	for _, scope := range form.Scopes {
+		form.CheckField(
+			validation.PermittedValue(scope, models.TokenScopes...),
+			"scopes",
+			"This field must be one of the provided options.",
+		)
+	}
+}
+
+// HasScope returns true if the scope is selected in the form.
+func (form *tokenForm) HasScope(scope string) bool {
+	return slices.Contains(form.Scopes, scope)
+}
+
+func (app *application) listTokens(w http.ResponseWriter, r *http.Request) {
+	app.renderTokens(w, r, http.StatusOK, reqUser(r), &tokenForm{Scopes: []string{models.ScopeRead}})
+}
+
+func (app *application) listTokensPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form := tokenForm{
+		Name:   r.PostForm.Get("name"),
+		Scopes: r.PostForm["scope"],
+	}
+	form.Validate()
+
+	if !form.IsValid() {
+		app.renderTokens(w, r, http.StatusUnprocessableEntity, userID, &form)
+		return
+	}
+
+	secret, err := generateToken(32)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+	secret = accessTokenPrefix + secret
+
+	token := models.AccessToken{
+		ID:     uuid.New(),
+		Owner:  userID,
+		Name:   form.Name,
+		Scopes: form.Scopes,
+	}
+	if err := app.tokenModel.Create(r.Context(), token, secret); err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	app.sessionManager.Put(r.Context(), sessionKeyNewAccessToken, secret)
+
+	http.Redirect(w, r, "/tokens", http.StatusSeeOther)
+}
+
+func (app *application) revokeTokenPost(w http.ResponseWriter, r *http.Request) {
+	tokenID, ok := app.uuidPathValue(w, r, "tokenID")
+	if !ok {
+		return
+	}
+
+	if err := app.tokenModel.Revoke(r.Context(), reqUser(r), tokenID); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/tokens", http.StatusSeeOther)
+}
+
+func (app *application) renderTokens(w http.ResponseWriter, r *http.Request, status int, userID string, form *tokenForm) {
+	tokens, err := app.tokenModel.List(r.Context(), userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	feedEnabled, err := app.userModel.FeedEnabled(r.Context(), userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.AccessTokens = tokens
+	data.FeedEnabled = feedEnabled
+	data.FeedURL = app.sessionManager.PopString(r.Context(), sessionKeyNewFeedURL)
+	data.Form = form
+	data.NewAccessToken = app.sessionManager.PopString(r.Context(), sessionKeyNewAccessToken)
+	data.TokenScopes = models.TokenScopes
+
+	app.render(w, r, status, "tokens", data)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_application_listTokens(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, "/tokens")
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, "/tokens")
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, body := server.get(t, "/tokens")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, `value="read" checked`)
+	})
+}
+
+func Test_application_listTokensPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/tokens")
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name                  string
+		tokenName             string
+		scopes                []string
+		wantStatus            int
+		wantValidationMessage string
+	}{
+		{
+			name:       "valid",
+			tokenName:  "Backup script",
+			scopes:     []string{"read", "write"},
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:                  "blank name",
+			tokenName:             " ",
+			scopes:                []string{"read"},
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field is required.",
+		},
+		{
+			name:                  "name too long",
+			tokenName:             strings.Repeat("a", 101),
+			scopes:                []string{"read"},
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field may not contain more than 100 characters.",
+		},
+		{
+			name:                  "no scopes",
+			tokenName:             "Backup script",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "Select at least one scope.",
+		},
+		{
+			name:                  "unknown scope",
+			tokenName:             "Backup script",
+			scopes:                []string{"admin"},
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be one of the provided options.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("name", tt.tokenName)
+			for _, scope := range tt.scopes {
+				form.Add("scope", scope)
+			}
+
+			status, headers, body := server.postForm(t, "/tokens", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+				return
+			}
+
+			assertRedirects(t, headers, "/tokens")
+
+			tokenModel := app.tokenModel.(*mock.TokenModel)
+			assert.Equal(t, tt.tokenName, tokenModel.LastCreatedToken.Name)
+			assert.Equal(t, mock.TestUserNormal, tokenModel.LastCreatedToken.Owner)
+			assert.Equal(t, strings.Join(tt.scopes, ","), strings.Join(tokenModel.LastCreatedToken.Scopes, ","))
+
+			secret := tokenModel.LastCreatedSecret
+			if !strings.HasPrefix(secret, accessTokenPrefix) {
+				t.Errorf("Expected secret with prefix %q; got %q", accessTokenPrefix, secret)
+			}
+
+			// The secret is shown once, and never again.
+			_, _, page := server.get(t, "/tokens")
+			assert.StringContains(t, page, secret)
+
+			_, _, page = server.get(t, "/tokens")
+			if strings.Contains(page, secret) {
+				t.Errorf("Expected secret to only be shown once; got %q", page)
+			}
+		})
+	}
+}
+
+func Test_application_revokeTokenPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/tokens")
+	csrfToken := extractCSRFToken(t, page)
+
+	tokenID := uuid.New()
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+
+	status, headers, _ := server.postForm(t, "/tokens/"+tokenID.String()+"/revoke", form)
+
+	assert.Equal(t, http.StatusSeeOther, status)
+	assertRedirects(t, headers, "/tokens")
+	assert.Equal(t, tokenID, app.tokenModel.(*mock.TokenModel).LastRevokedToken)
+}
+
+func Test_application_authenticateBearer(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "read",
+			method:     http.MethodGet,
+			path:       "/api/v1/recipes",
+			token:      mock.ValidAccessToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "write without CSRF token",
+			method:     http.MethodPost,
+			path:       "/api/v1/categories",
+			token:      mock.ValidAccessToken,
+			body:       `{"name": "Soups"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "read-only token reading",
+			method:     http.MethodGet,
+			path:       "/api/v1/categories",
+			token:      mock.ReadOnlyAccessToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "read-only token writing",
+			method:     http.MethodPost,
+			path:       "/api/v1/categories",
+			token:      mock.ReadOnlyAccessToken,
+			body:       `{"name": "Soups"}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "invalid token",
+			method:     http.MethodGet,
+			path:       "/api/v1/recipes",
+			token:      "rcp_invalid",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "negotiated page",
+			method:     http.MethodGet,
+			path:       "/recipes",
+			token:      mock.ValidAccessToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "outside the API",
+			method:     http.MethodGet,
+			path:       "/collections",
+			token:      mock.ValidAccessToken,
+			wantStatus: http.StatusSeeOther,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			status, headers, body := server.sendBearer(t, tt.method, tt.path, tt.token, tt.body)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			switch tt.wantStatus {
+			case http.StatusUnauthorized, http.StatusForbidden:
+				response := decodeJSON[apiError](t, body)
+				assert.Equal(t, tt.wantStatus, response.Error.Status)
+				assert.StringContains(t, headers.Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+}
+
+func Test_application_authenticateBearer_overridesSession(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	// An invalid token must not fall back to the session, or it could be used to skip CSRF
+	// protection.
+	status, _, _ := server.sendBearer(t, http.MethodPost, "/api/v1/categories", "rcp_invalid", `{"name": "Soups"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "", app.categoryModel.(*mock.CategoryModel).LastCreatedCategory.Name)
+}
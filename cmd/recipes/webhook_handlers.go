@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/validation"
+	"github.com/cdriehuys/recipes/internal/webhooks"
+	"github.com/google/uuid"
+)
+
+// webhookDeliveryLogSize is the number of recent deliveries shown for a webhook.
+const webhookDeliveryLogSize = 50
+
+// webhookPayload is the body of every webhook delivery.
+type webhookPayload struct {
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Data is the object the event happened to, in the same format as the API. Only the ID of a
+	// deleted object is sent.
+	Data any `json:"data"`
+}
+
+type webhookDeletedObject struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// sendWebhookEvent queues a delivery of the event to the webhooks of each user who can view the
+// object with the given owner and household. Failures are logged rather than reported since the
+// change the event describes has already been saved.
+func (app *application) sendWebhookEvent(r *http.Request, owner string, household *uuid.UUID, event string, data any) {
+	payload, err := json.Marshal(webhookPayload{Event: event, OccurredAt: time.Now().UTC(), Data: data})
+	if err == nil {
+		err = app.webhookModel.Enqueue(r.Context(), owner, household, event, payload)
+	}
+
+	if err != nil {
+		app.logger.ErrorContext(r.Context(), "Failed to enqueue webhook deliveries.", "event", event, "error", err)
+	}
+}
+
+// sendRecipeEvents sends a webhook event for each of the recipes after they are created or
+// updated.
+func (app *application) sendRecipeEvents(r *http.Request, userID string, event string, ids ...uuid.UUID) {
+	recipes, err := app.recipeModel.ListByIDs(r.Context(), userID, ids)
+	if err != nil {
+		app.logger.ErrorContext(r.Context(), "Failed to fetch recipes for webhook event.", "event", event, "error", err)
+		return
+	}
+
+	for _, recipe := range recipes {
+		app.sendWebhookEvent(r, recipe.Owner, recipe.Household, event, newAPIRecipe(recipe))
+	}
+}
+
+// sendRecipesDeleted sends a webhook event for each of the recipes after they are deleted. The
+// recipes must be fetched before they are deleted so the users who could view them are known.
+func (app *application) sendRecipesDeleted(r *http.Request, recipes ...models.Recipe) {
+	for _, recipe := range recipes {
+		app.sendWebhookEvent(r, recipe.Owner, recipe.Household, models.EventRecipeDeleted, webhookDeletedObject{ID: recipe.ID})
+	}
+}
+
+// sendCategoryEvent sends a webhook event for the category after it is created or updated.
+func (app *application) sendCategoryEvent(r *http.Request, userID string, event string, id uuid.UUID) {
+	category, err := app.categoryModel.Get(r.Context(), userID, id)
+	if err != nil {
+		app.logger.ErrorContext(r.Context(), "Failed to fetch category for webhook event.", "event", event, "error", err)
+		return
+	}
+
+	app.sendWebhookEvent(r, category.Owner, category.Household, event, newAPICategory(category))
+}
+
+// sendCategoryDeleted sends a webhook event for the category after it is deleted.
+func (app *application) sendCategoryDeleted(r *http.Request, category models.Category) {
+	app.sendWebhookEvent(r, category.Owner, category.Household, models.EventCategoryDeleted, webhookDeletedObject{ID: category.ID})
+}
+
+type webhookForm struct {
+	URL    string
+	Events []string
+	// AllowPrivate permits URLs on private or local networks, which are otherwise refused so
+	// webhooks can't be used to reach the server's own network.
+	AllowPrivate bool
+	validation.Validator
+}
+
+func (form *webhookForm) Validate() {
+	form.CheckField(validation.NotBlank(form.URL), "url", "This field is required.")
+	form.CheckField(validation.MaxLength(form.URL, 2000), "url", "This field may not contain more than 2000 characters.")
+	form.CheckField(
+		validation.WebURLOrBlank(form.URL),
+		"url",
+		"This field must be a web address starting with http:// or https://.",
+	)
+
+	if !form.AllowPrivate && validation.WebURLOrBlank(form.URL) {
+		form.CheckField(
+			webhooks.CheckURL(form.URL) == nil,
+			"url",
+			"This field must be a public web address rather than one on a private or local network.",
+		)
+	}
+
+	if len(form.Events) == 0 {
+		form.AddFieldError("events", "Select at least one event.")
+	}
+
+	for _, event := range form.Events {
+		form.CheckField(
+			validation.PermittedValue(event, models.WebhookEvents...),
+			"events",
+			"This field must be one of the provided options.",
+		)
+	}
+}
+
+// HasEvent returns true if the event is selected in the form.
+func (form *webhookForm) HasEvent(event string) bool {
+	return slices.Contains(form.Events, event)
+}
+
+func (app *application) listWebhooks(w http.ResponseWriter, r *http.Request) {
+	app.renderWebhooks(w, r, http.StatusOK, reqUser(r), &webhookForm{Events: models.WebhookEvents})
+}
+
+func (app *application) listWebhooksPost(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	if err := r.ParseForm(); err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form := webhookForm{
+		URL:          r.PostForm.Get("url"),
+		Events:       r.PostForm["event"],
+		AllowPrivate: app.config.AllowPrivateWebhooks,
+	}
+	form.Validate()
+
+	if !form.IsValid() {
+		app.renderWebhooks(w, r, http.StatusUnprocessableEntity, userID, &form)
+		return
+	}
+
+	secret, err := generateToken(32)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	webhook := models.Webhook{
+		ID:     uuid.New(),
+		Owner:  userID,
+		URL:    form.URL,
+		Secret: secret,
+		Events: form.Events,
+	}
+	if err := app.webhookModel.Create(r.Context(), webhook); err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/webhooks/"+webhook.ID.String(), http.StatusSeeOther)
+}
+
+func (app *application) getWebhook(w http.ResponseWriter, r *http.Request) {
+	userID := reqUser(r)
+
+	id, ok := app.uuidPathValue(w, r, "webhookID")
+	if !ok {
+		return
+	}
+
+	webhook, err := app.webhookModel.Get(r.Context(), userID, id)
+	if err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	deliveries, err := app.webhookModel.Deliveries(r.Context(), userID, id, webhookDeliveryLogSize)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Webhook = webhook
+	data.WebhookDeliveries = deliveries
+
+	app.render(w, r, http.StatusOK, "webhook", data)
+}
+
+func (app *application) deleteWebhookPost(w http.ResponseWriter, r *http.Request) {
+	id, ok := app.uuidPathValue(w, r, "webhookID")
+	if !ok {
+		return
+	}
+
+	if err := app.webhookModel.Delete(r.Context(), reqUser(r), id); err != nil {
+		app.modelError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/webhooks", http.StatusSeeOther)
+}
+
+func (app *application) renderWebhooks(w http.ResponseWriter, r *http.Request, status int, userID string, form *webhookForm) {
+	webhooks, err := app.webhookModel.List(r.Context(), userID)
+	if err != nil {
+		app.serverError(w, r, err)
+		return
+	}
+
+	data := app.newTemplateData(r)
+	data.Form = form
+	data.WebhookEvents = models.WebhookEvents
+	data.Webhooks = webhooks
+
+	app.render(w, r, status, "webhooks", data)
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/models/mock"
+	"github.com/google/uuid"
+)
+
+func Test_application_listWebhooks(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		status, headers, _ := server.get(t, "/webhooks")
+
+		assert.Equal(t, http.StatusSeeOther, status)
+		assertLoginRedirect(t, headers, "/webhooks")
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		server.authenticate(t, mock.TestUserNormal)
+
+		status, _, body := server.get(t, "/webhooks")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, mock.Webhook.URL)
+		assert.StringContains(t, body, `value="recipe.deleted" checked`)
+	})
+}
+
+func Test_application_listWebhooksPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/webhooks")
+	csrfToken := extractCSRFToken(t, page)
+
+	testCases := []struct {
+		name                  string
+		url                   string
+		events                []string
+		allowPrivate          bool
+		wantStatus            int
+		wantValidationMessage string
+	}{
+		{
+			name:       "valid",
+			url:        "https://example.com/hooks/recipes",
+			events:     []string{"recipe.created", "category.deleted"},
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name:                  "private network",
+			url:                   "http://homeassistant.local:8123/api/webhook/recipes",
+			events:                []string{"recipe.created"},
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be a public web address rather than one on a private or local network.",
+		},
+		{
+			name:                  "loopback address",
+			url:                   "http://127.0.0.1:8080/hook",
+			events:                []string{"recipe.created"},
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be a public web address rather than one on a private or local network.",
+		},
+		{
+			name:         "private network allowed",
+			url:          "http://homeassistant.local:8123/api/webhook/recipes",
+			events:       []string{"recipe.created"},
+			allowPrivate: true,
+			wantStatus:   http.StatusSeeOther,
+		},
+		{
+			name:                  "blank URL",
+			events:                []string{"recipe.created"},
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field is required.",
+		},
+		{
+			name:                  "not a web address",
+			url:                   "ftp://example.com",
+			events:                []string{"recipe.created"},
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be a web address starting with http:// or https://.",
+		},
+		{
+			name:                  "URL too long",
+			url:                   "https://example.com/" + strings.Repeat("a", 2000),
+			events:                []string{"recipe.created"},
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field may not contain more than 2000 characters.",
+		},
+		{
+			name:                  "no events",
+			url:                   "https://example.com/hook",
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "Select at least one event.",
+		},
+		{
+			name:                  "unknown event",
+			url:                   "https://example.com/hook",
+			events:                []string{"recipe.cooked"},
+			wantStatus:            http.StatusUnprocessableEntity,
+			wantValidationMessage: "This field must be one of the provided options.",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			app.config.AllowPrivateWebhooks = tt.allowPrivate
+
+			form := url.Values{}
+			form.Add("csrf_token", csrfToken)
+			form.Add("url", tt.url)
+			for _, event := range tt.events {
+				form.Add("event", event)
+			}
+
+			status, headers, body := server.postForm(t, "/webhooks", form)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantValidationMessage != "" {
+				assert.StringContains(t, body, tt.wantValidationMessage)
+				return
+			}
+
+			webhook := app.webhookModel.(*mock.WebhookModel).LastCreatedWebhook
+			assertRedirects(t, headers, "/webhooks/"+webhook.ID.String())
+			assert.Equal(t, mock.TestUserNormal, webhook.Owner)
+			assert.Equal(t, tt.url, webhook.URL)
+			assert.Equal(t, strings.Join(tt.events, ","), strings.Join(webhook.Events, ","))
+
+			if len(webhook.Secret) < 32 {
+				t.Errorf("Expected a random secret; got %q", webhook.Secret)
+			}
+		})
+	}
+}
+
+func Test_application_getWebhook(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	t.Run("found", func(t *testing.T) {
+		status, _, body := server.get(t, "/webhooks/"+mock.Webhook.ID.String())
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.StringContains(t, body, mock.Webhook.URL)
+		assert.StringContains(t, body, mock.Webhook.Secret)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		status, _, _ := server.get(t, "/webhooks/"+uuid.NewString())
+
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func Test_application_deleteWebhookPost(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/webhooks")
+	csrfToken := extractCSRFToken(t, page)
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+
+	status, headers, _ := server.postForm(t, "/webhooks/"+mock.Webhook.ID.String()+"/delete", form)
+
+	assert.Equal(t, http.StatusSeeOther, status)
+	assertRedirects(t, headers, "/webhooks")
+	assert.Equal(t, mock.Webhook.ID, app.webhookModel.(*mock.WebhookModel).LastDeletedWebhook)
+}
+
+func Test_application_webhookEvents(t *testing.T) {
+	recipeID := uuid.New()
+
+	testCases := []struct {
+		name      string
+		method    string
+		path      string
+		body      string
+		wantEvent string
+		wantData  string
+	}{
+		{
+			name:      "recipe created",
+			method:    http.MethodPost,
+			path:      "/api/v1/recipes",
+			body:      `{"title": "Soup", "instructions": "Simmer."}`,
+			wantEvent: models.EventRecipeCreated,
+			wantData:  `"title":"` + mock.Recipe.Title + `"`,
+		},
+		{
+			name:      "recipe updated",
+			method:    http.MethodPut,
+			path:      "/api/v1/recipes/" + recipeID.String(),
+			body:      `{"title": "Soup", "instructions": "Simmer."}`,
+			wantEvent: models.EventRecipeUpdated,
+			wantData:  `"id":"` + recipeID.String() + `"`,
+		},
+		{
+			name:      "recipe deleted",
+			method:    http.MethodDelete,
+			path:      "/api/v1/recipes/" + recipeID.String(),
+			wantEvent: models.EventRecipeDeleted,
+			wantData:  `"data":{"id":"` + recipeID.String() + `"}`,
+		},
+		{
+			name:      "category created",
+			method:    http.MethodPost,
+			path:      "/api/v1/categories",
+			body:      `{"name": "Soups"}`,
+			wantEvent: models.EventCategoryCreated,
+			wantData:  `"name":"Soups"`,
+		},
+		{
+			name:      "category deleted",
+			method:    http.MethodDelete,
+			path:      "/api/v1/categories/" + mock.ListedCategories[0].ID.String(),
+			wantEvent: models.EventCategoryDeleted,
+			wantData:  `"data":{"id":"` + mock.ListedCategories[0].ID.String() + `"}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			server, csrfToken := newAPITestServer(t, app)
+
+			status, _, body := server.sendJSON(t, tt.method, tt.path, csrfToken, tt.body)
+			if status >= 300 {
+				t.Fatalf("Expected success; got %d: %s", status, body)
+			}
+
+			enqueued := app.webhookModel.(*mock.WebhookModel).Enqueued
+			assert.Equal(t, 1, len(enqueued))
+			assert.Equal(t, tt.wantEvent, enqueued[0].Event)
+			assert.StringContains(t, string(enqueued[0].Payload), `"event":"`+tt.wantEvent+`"`)
+			assert.StringContains(t, string(enqueued[0].Payload), tt.wantData)
+		})
+	}
+}
+
+func Test_application_webhookEvents_household(t *testing.T) {
+	// The event goes to everyone who can view the recipe, rather than only the user who changed it.
+	original := mock.Recipe
+	mock.Recipe.Owner = "household-owner"
+	mock.Recipe.Household = &mock.Household.ID
+	t.Cleanup(func() { mock.Recipe = original })
+
+	testCases := []struct {
+		name   string
+		method string
+		body   string
+	}{
+		{name: "updated", method: http.MethodPut, body: `{"title": "Soup", "instructions": "Simmer."}`},
+		{name: "deleted", method: http.MethodDelete},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			server, csrfToken := newAPITestServer(t, app)
+
+			status, _, body := server.sendJSON(t, tt.method, "/api/v1/recipes/"+uuid.NewString(), csrfToken, tt.body)
+			if status >= 300 {
+				t.Fatalf("Expected success; got %d: %s", status, body)
+			}
+
+			enqueued := app.webhookModel.(*mock.WebhookModel).Enqueued
+			assert.Equal(t, 1, len(enqueued))
+			assert.Equal(t, "household-owner", enqueued[0].Owner)
+			if enqueued[0].Household == nil || *enqueued[0].Household != mock.Household.ID {
+				t.Errorf("Expected the event for household %s; got %v", mock.Household.ID, enqueued[0].Household)
+			}
+		})
+	}
+}
+
+func Test_application_webhookEvents_bulk(t *testing.T) {
+	app := newTestApp(t)
+	server := newTestServer(t, app)
+	server.authenticate(t, mock.TestUserNormal)
+
+	_, _, page := server.get(t, "/recipes")
+	csrfToken := extractCSRFToken(t, page)
+
+	recipes := []uuid.UUID{mock.SubRecipe.ID, mock.ParentRecipe.ID}
+
+	form := url.Values{}
+	form.Add("csrf_token", csrfToken)
+	form.Add("action", "delete")
+	form.Add("confirm", "true")
+	for _, recipe := range recipes {
+		form.Add("recipe", recipe.String())
+	}
+
+	status, _, _ := server.postForm(t, "/recipes/bulk", form)
+	assert.Equal(t, http.StatusSeeOther, status)
+
+	enqueued := app.webhookModel.(*mock.WebhookModel).Enqueued
+	assert.Equal(t, len(recipes), len(enqueued))
+	for i, recipe := range recipes {
+		assert.Equal(t, models.EventRecipeDeleted, enqueued[i].Event)
+		assert.StringContains(t, string(enqueued[i].Payload), recipe.String())
+	}
+}
@@ -7,3 +7,8 @@ var StaticFS embed.FS
 
 //go:embed templates
 var TemplateFS embed.FS
+
+// OpenAPIDocument is the OpenAPI description of the JSON API.
+//
+//go:embed openapi.json
+var OpenAPIDocument []byte
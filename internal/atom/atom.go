@@ -0,0 +1,85 @@
+// Package atom writes Atom syndication feeds as described by RFC 4287.
+package atom
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ContentType is the media type of an Atom feed document.
+const ContentType = "application/atom+xml"
+
+type Feed struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	// ID permanently and uniquely identifies the feed. It should not change even if the feed moves.
+	ID       string    `xml:"id"`
+	Title    string    `xml:"title"`
+	Subtitle string    `xml:"subtitle,omitempty"`
+	Updated  time.Time `xml:"updated"`
+	Author   *Person   `xml:"author,omitempty"`
+	Links    []Link    `xml:"link"`
+	Entries  []Entry   `xml:"entry"`
+}
+
+type Entry struct {
+	// ID permanently and uniquely identifies the entry, such as a "urn:uuid:" URI.
+	ID         string     `xml:"id"`
+	Title      string     `xml:"title"`
+	Updated    time.Time  `xml:"updated"`
+	Published  *time.Time `xml:"published,omitempty"`
+	Author     *Person    `xml:"author,omitempty"`
+	Links      []Link     `xml:"link"`
+	Categories []Category `xml:"category"`
+	Summary    *Text      `xml:"summary,omitempty"`
+	Content    *Text      `xml:"content,omitempty"`
+}
+
+type Link struct {
+	Href string `xml:"href,attr"`
+	// Rel is the relationship of the linked resource, such as "alternate" or "self".
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type Person struct {
+	Name string `xml:"name"`
+}
+
+type Category struct {
+	Term string `xml:"term,attr"`
+}
+
+// Text is a human-readable text construct. Its type is "text" for plain text or "html" for escaped
+// HTML markup.
+type Text struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// PlainText returns a text construct holding plain text.
+func PlainText(text string) *Text {
+	return &Text{Type: "text", Body: text}
+}
+
+// HTML returns a text construct holding HTML markup. The markup is escaped when the feed is written.
+func HTML(markup string) *Text {
+	return &Text{Type: "html", Body: markup}
+}
+
+// Write writes the feed as an XML document.
+func (f Feed) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write XML header: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(f); err != nil {
+		return fmt.Errorf("failed to encode feed: %w", err)
+	}
+
+	return encoder.Close()
+}
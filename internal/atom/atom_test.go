@@ -0,0 +1,52 @@
+package atom_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/atom"
+)
+
+func TestFeed_Write(t *testing.T) {
+	updated := time.Date(2024, 5, 19, 12, 30, 0, 0, time.UTC)
+
+	feed := atom.Feed{
+		ID:      "https://example.com/feed",
+		Title:   "Recipes",
+		Updated: updated,
+		Author:  &atom.Person{Name: "Jane Smith"},
+		Links:   []atom.Link{{Href: "https://example.com/feed", Rel: "self", Type: atom.ContentType}},
+		Entries: []atom.Entry{
+			{
+				ID:         "urn:uuid:6f1f2a52-54a3-4c1e-9b8a-6c0d7f8e1a01",
+				Title:      "Fish & Chips",
+				Updated:    updated,
+				Links:      []atom.Link{{Href: "https://example.com/recipes/1", Rel: "alternate"}},
+				Categories: []atom.Category{{Term: "dinner"}},
+				Content:    atom.HTML("<p>Fry.</p>"),
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NilError(t, feed.Write(&buf))
+
+	body := buf.String()
+	assert.Equal(t, true, strings.HasPrefix(body, `<?xml version="1.0" encoding="UTF-8"?>`))
+	assert.StringContains(t, body, `<feed xmlns="http://www.w3.org/2005/Atom">`)
+	assert.StringContains(t, body, `<updated>2024-05-19T12:30:00Z</updated>`)
+	assert.StringContains(t, body, `<title>Fish &amp; Chips</title>`)
+	assert.StringContains(t, body, `<content type="html">&lt;p&gt;Fry.&lt;/p&gt;</content>`)
+	assert.StringContains(t, body, `<category term="dinner"></category>`)
+
+	var decoded atom.Feed
+	assert.NilError(t, xml.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, feed.ID, decoded.ID)
+	assert.Equal(t, 1, len(decoded.Entries))
+	assert.Equal(t, "<p>Fry.</p>", decoded.Entries[0].Content.Body)
+	assert.Equal(t, "self", decoded.Links[0].Rel)
+}
@@ -1,12 +1,21 @@
 package config
 
 import (
+	"fmt"
+	"net/netip"
 	"net/url"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
+	// Allow webhooks to be sent to private and local network addresses, such as localhost.
+	//
+	// These are refused by default so users can't make requests to services on the server's own
+	// network. This is intended for development, where the receiver often runs on the same machine.
+	AllowPrivateWebhooks bool
+
 	// Address to bind the webserver to.
 	BindAddr string
 
@@ -28,7 +37,42 @@ type Config struct {
 
 	OAuthCallbackURL string
 
+	// Limits on the rate of requests each client may make to each group of routes.
+	RateLimits RateLimits
+
 	RunMigrations bool
+
+	// Networks of reverse proxies whose X-Forwarded-For headers are trusted to identify clients.
+	//
+	// Requests from any other address are identified by that address, since anyone can send the
+	// header. Leave this empty unless the app is only reachable through the proxies, or else every
+	// client behind a proxy is treated as one client for purposes like rate limiting.
+	TrustedProxies []netip.Prefix
+}
+
+type RateLimits struct {
+	// Limit for the login and OAuth callback routes.
+	Auth RateLimit
+
+	// Limit for the JSON API.
+	API RateLimit
+
+	// Limit for all other pages.
+	Pages RateLimit
+
+	// Limit for failed access token attempts from each client address.
+	Tokens RateLimit
+
+	// Limit for requests for unknown recipe feeds from each client address.
+	Feeds RateLimit
+}
+
+type RateLimit struct {
+	// The number of requests per minute a client may sustain. Zero disables the limit.
+	PerMinute float64
+
+	// The number of requests a client may make at once after being idle.
+	Burst int
 }
 
 type DatabaseConfig struct {
@@ -66,6 +110,9 @@ func (c *DatabaseConfig) ConnectionURL() url.URL {
 }
 
 func init() {
+	viper.BindEnv("allow-private-webhooks", "ALLOW_PRIVATE_WEBHOOKS")
+	viper.SetDefault("allow-private-webhooks", false)
+
 	viper.SetDefault("bind-address", ":8000")
 
 	viper.BindEnv("database.host", "POSTGRES_HOSTNAME")
@@ -81,12 +128,45 @@ func init() {
 
 	viper.BindEnv("oauth-callback-url", "OAUTH_CALLBACK_URL")
 
+	viper.BindEnv("rate-limit.auth.per-minute", "RATE_LIMIT_AUTH_PER_MINUTE")
+	viper.SetDefault("rate-limit.auth.per-minute", 10)
+	viper.BindEnv("rate-limit.auth.burst", "RATE_LIMIT_AUTH_BURST")
+	viper.SetDefault("rate-limit.auth.burst", 10)
+
+	viper.BindEnv("rate-limit.api.per-minute", "RATE_LIMIT_API_PER_MINUTE")
+	viper.SetDefault("rate-limit.api.per-minute", 120)
+	viper.BindEnv("rate-limit.api.burst", "RATE_LIMIT_API_BURST")
+	viper.SetDefault("rate-limit.api.burst", 60)
+
+	viper.BindEnv("rate-limit.pages.per-minute", "RATE_LIMIT_PAGES_PER_MINUTE")
+	viper.SetDefault("rate-limit.pages.per-minute", 300)
+	viper.BindEnv("rate-limit.pages.burst", "RATE_LIMIT_PAGES_BURST")
+	viper.SetDefault("rate-limit.pages.burst", 100)
+
+	viper.BindEnv("rate-limit.tokens.per-minute", "RATE_LIMIT_TOKENS_PER_MINUTE")
+	viper.SetDefault("rate-limit.tokens.per-minute", 10)
+	viper.BindEnv("rate-limit.tokens.burst", "RATE_LIMIT_TOKENS_BURST")
+	viper.SetDefault("rate-limit.tokens.burst", 10)
+
+	viper.BindEnv("rate-limit.feeds.per-minute", "RATE_LIMIT_FEEDS_PER_MINUTE")
+	viper.SetDefault("rate-limit.feeds.per-minute", 10)
+	viper.BindEnv("rate-limit.feeds.burst", "RATE_LIMIT_FEEDS_BURST")
+	viper.SetDefault("rate-limit.feeds.burst", 10)
+
 	viper.SetDefault("run-migrations", false)
+
+	viper.BindEnv("trusted-proxies", "TRUSTED_PROXIES")
 }
 
 func FromEnvironment() (Config, error) {
+	trustedProxies, err := ParseTrustedProxies(viper.GetString("trusted-proxies"))
+	if err != nil {
+		return Config{}, err
+	}
+
 	config := Config{
-		BindAddr: viper.GetString("bind-address"),
+		AllowPrivateWebhooks: viper.GetBool("allow-private-webhooks"),
+		BindAddr:             viper.GetString("bind-address"),
 		Database: DatabaseConfig{
 			User:     viper.GetString("database.user"),
 			Password: viper.GetString("database.password"),
@@ -98,8 +178,54 @@ func FromEnvironment() (Config, error) {
 		GoogleClientID:     viper.GetString("google-client-id"),
 		GoogleClientSecret: viper.GetString("google-client-secret"),
 		OAuthCallbackURL:   viper.GetString("oauth-callback-url"),
-		RunMigrations:      viper.GetBool("run-migrations"),
+		RateLimits: RateLimits{
+			Auth:   rateLimit("rate-limit.auth"),
+			API:    rateLimit("rate-limit.api"),
+			Pages:  rateLimit("rate-limit.pages"),
+			Tokens: rateLimit("rate-limit.tokens"),
+			Feeds:  rateLimit("rate-limit.feeds"),
+		},
+		RunMigrations:  viper.GetBool("run-migrations"),
+		TrustedProxies: trustedProxies,
 	}
 
 	return config, nil
 }
+
+func rateLimit(key string) RateLimit {
+	return RateLimit{
+		PerMinute: viper.GetFloat64(key + ".per-minute"),
+		Burst:     viper.GetInt(key + ".burst"),
+	}
+}
+
+// ParseTrustedProxies parses a comma-separated list of IP addresses and networks in CIDR notation,
+// such as "10.0.0.0/8, 192.0.2.1".
+func ParseTrustedProxies(value string) ([]netip.Prefix, error) {
+	var proxies []netip.Prefix
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+			}
+
+			proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+
+		proxies = append(proxies, prefix.Masked())
+	}
+
+	return proxies, nil
+}
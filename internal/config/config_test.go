@@ -1,6 +1,7 @@
 package config
 
 import (
+	"net/netip"
 	"net/url"
 	"reflect"
 	"testing"
@@ -66,3 +67,40 @@ func TestDatabaseConfig_ConnectionURL(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []netip.Prefix
+		wantErr bool
+	}{
+		{name: "blank", value: ""},
+		{
+			name:  "addresses and networks",
+			value: "10.0.0.0/8, 192.0.2.1,2001:db8::/32",
+			want: []netip.Prefix{
+				netip.MustParsePrefix("10.0.0.0/8"),
+				netip.MustParsePrefix("192.0.2.1/32"),
+				netip.MustParsePrefix("2001:db8::/32"),
+			},
+		},
+		{
+			name:  "unmasked network",
+			value: "10.1.2.3/8",
+			want:  []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+		},
+		{name: "invalid", value: "10.0.0.0/8,proxy.example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTrustedProxies(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTrustedProxies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTrustedProxies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+// Package dietary infers allergen and diet labels, such as "contains nuts" or "vegetarian", from the
+// names of a recipe's ingredients.
+package dietary
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/cdriehuys/recipes/internal/ingredients"
+)
+
+// Allergen is a common food allergen.
+type Allergen string
+
+const (
+	Gluten    Allergen = "gluten"
+	Dairy     Allergen = "dairy"
+	Nuts      Allergen = "nuts"
+	Egg       Allergen = "egg"
+	Shellfish Allergen = "shellfish"
+	Soy       Allergen = "soy"
+)
+
+// Allergens lists every allergen that is detected.
+var Allergens = []Allergen{Gluten, Dairy, Nuts, Egg, Shellfish, Soy}
+
+// Label returns the human readable name of the allergen.
+func (a Allergen) Label() string {
+	return strings.ToUpper(string(a[:1])) + string(a[1:])
+}
+
+// AllergenSet is a set of allergens. The zero value is the empty set.
+type AllergenSet uint8
+
+func (a Allergen) bit() AllergenSet {
+	for i, allergen := range Allergens {
+		if allergen == a {
+			return 1 << i
+		}
+	}
+
+	return 0
+}
+
+// Has reports if the allergen is in the set.
+func (s AllergenSet) Has(a Allergen) bool {
+	return a.bit() != 0 && s&a.bit() != 0
+}
+
+// With returns the set with the allergen added.
+func (s AllergenSet) With(a Allergen) AllergenSet {
+	return s | a.bit()
+}
+
+// Without returns the set with the allergen removed.
+func (s AllergenSet) Without(a Allergen) AllergenSet {
+	return s &^ a.bit()
+}
+
+// List returns the allergens in the set in the order of Allergens.
+func (s AllergenSet) List() []Allergen {
+	var list []Allergen
+	for _, allergen := range Allergens {
+		if s.Has(allergen) {
+			list = append(list, allergen)
+		}
+	}
+
+	return list
+}
+
+// Diet is a dietary restriction a recipe may be suitable for.
+type Diet string
+
+const (
+	Vegetarian Diet = "vegetarian"
+	Vegan      Diet = "vegan"
+)
+
+// Diets lists every diet that is detected.
+var Diets = []Diet{Vegetarian, Vegan}
+
+// Label returns the human readable name of the diet.
+func (d Diet) Label() string {
+	return strings.ToUpper(string(d[:1])) + string(d[1:])
+}
+
+// Labels are the allergen and diet labels of a recipe.
+type Labels struct {
+	Allergens  AllergenSet
+	Vegetarian bool
+	Vegan      bool
+
+	// Sources holds the names of the ingredients each detected allergen was found in. Allergens that
+	// were added manually have no sources.
+	Sources map[Allergen][]string
+}
+
+// Suits reports if the labelled recipe is suitable for the diet. Recipes are suitable for any diet
+// if no diet is provided.
+func (l Labels) Suits(diet Diet) bool {
+	switch diet {
+	case Vegetarian:
+		return l.Vegetarian
+	case Vegan:
+		return l.Vegan
+	default:
+		return true
+	}
+}
+
+// Overrides are manual corrections to detected labels, keyed by the name of an allergen or diet.
+// A true value forces the label on, and a false value forces it off.
+type Overrides map[string]bool
+
+// Apply returns the labels with the overrides applied. Vegan recipes are always vegetarian, so
+// marking a recipe vegan also marks it vegetarian, and marking it not vegetarian also marks it not
+// vegan.
+func (l Labels) Apply(overrides Overrides) Labels {
+	for _, allergen := range Allergens {
+		present, ok := overrides[string(allergen)]
+		switch {
+		case !ok:
+		case present:
+			l.Allergens = l.Allergens.With(allergen)
+		default:
+			l.Allergens = l.Allergens.Without(allergen)
+		}
+	}
+
+	if vegetarian, ok := overrides[string(Vegetarian)]; ok {
+		l.Vegetarian = vegetarian
+	}
+
+	if vegan, ok := overrides[string(Vegan)]; ok {
+		l.Vegan = vegan
+	}
+
+	if l.Vegan && !l.Vegetarian {
+		if _, ok := overrides[string(Vegan)]; ok {
+			l.Vegetarian = true
+		} else {
+			l.Vegan = false
+		}
+	}
+
+	return l
+}
+
+// rule detects a label from ingredient names. An ingredient matches if its name contains any of the
+// keywords, unless it also contains one of the exceptions.
+type rule struct {
+	keywords   []string
+	exceptions []string
+}
+
+// matches reports if the rule matches the words of an ingredient name.
+func (r rule) matches(words []string) bool {
+	for _, exception := range r.exceptions {
+		if containsPhrase(words, exception) {
+			return false
+		}
+	}
+
+	for _, keyword := range r.keywords {
+		if containsPhrase(words, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// plantBased are words that indicate an ingredient is a substitute for an animal product.
+var plantBased = []string{"vegan", "vegetarian", "plant based", "meatless", "dairy free", "egg free"}
+
+var allergenRules = map[Allergen]rule{
+	Gluten: {
+		keywords: []string{
+			"wheat", "flour", "bread", "breadcrumb", "bread crumb", "panko", "crouton", "pasta",
+			"spaghetti", "macaroni", "penne", "fettuccine", "linguine", "rigatoni", "lasagna", "orzo",
+			"noodle", "ramen", "udon", "couscous", "barley", "rye", "bulgur", "semolina", "farro",
+			"spelt", "seitan", "cracker", "tortilla", "pita", "bagel", "bun", "croissant", "biscuit",
+			"pastry", "pie crust", "soy sauce", "teriyaki", "beer", "malt", "oat", "oatmeal",
+		},
+		exceptions: []string{
+			"gluten free", "rice flour", "almond flour", "coconut flour", "corn flour", "tapioca flour",
+			"chickpea flour", "potato flour", "buckwheat flour", "rice noodle", "glass noodle",
+			"corn tortilla", "tamari",
+		},
+	},
+	Dairy: {
+		keywords: []string{
+			"milk", "butter", "buttermilk", "cream", "cheese", "yogurt", "yoghurt", "ghee", "whey",
+			"casein", "half and half", "mozzarella", "parmesan", "parmigiano", "cheddar", "ricotta",
+			"feta", "mascarpone", "brie", "gouda", "gruyere", "provolone", "queso", "paneer", "custard",
+			"kefir",
+		},
+		exceptions: append([]string{
+			"peanut butter", "almond butter", "cashew butter", "nut butter", "apple butter",
+			"cocoa butter", "coconut milk", "coconut cream", "cream of coconut", "almond milk",
+			"oat milk", "soy milk", "rice milk", "cashew milk", "cream of tartar",
+		}, plantBased...),
+	},
+	Nuts: {
+		keywords: []string{
+			"nut", "almond", "walnut", "pecan", "cashew", "pistachio", "hazelnut", "filbert",
+			"macadamia", "chestnut", "peanut", "praline", "marzipan", "nutella", "gianduja",
+		},
+		exceptions: []string{"nut free", "water chestnut"},
+	},
+	Egg: {
+		keywords:   []string{"egg", "mayonnaise", "mayo", "meringue", "aioli"},
+		exceptions: append([]string{"egg free", "egg replacer"}, plantBased...),
+	},
+	Shellfish: {
+		keywords: []string{
+			"shrimp", "prawn", "crab", "lobster", "crawfish", "crayfish", "langoustine", "scallop",
+			"clam", "mussel", "oyster", "shellfish",
+		},
+		exceptions: []string{"oyster mushroom", "imitation crab"},
+	},
+	Soy: {
+		keywords: []string{
+			"soy", "soya", "soybean", "tofu", "tempeh", "edamame", "miso", "tamari", "soy sauce",
+		},
+		exceptions: []string{"soy free"},
+	},
+}
+
+// meatRule matches ingredients that are not vegetarian.
+var meatRule = rule{
+	keywords: []string{
+		"meat", "beef", "steak", "veal", "pork", "bacon", "ham", "prosciutto", "pancetta", "salami",
+		"pepperoni", "chorizo", "sausage", "hot dog", "lamb", "mutton", "goat", "venison", "chicken",
+		"turkey", "duck", "goose", "fish", "salmon", "tuna", "cod", "tilapia", "halibut", "trout",
+		"anchovy", "anchovie", "sardine", "mackerel", "gelatin", "lard", "suet", "worcestershire",
+		"shrimp", "prawn", "crab", "lobster", "crawfish", "scallop", "clam", "mussel", "oyster",
+		"squid", "calamari", "octopus", "bone broth",
+	},
+	exceptions: append([]string{"oyster mushroom", "vegetable broth", "vegetable stock", "goat cheese"}, plantBased...),
+}
+
+// animalRule matches vegetarian ingredients that are not vegan, in addition to dairy and eggs.
+var animalRule = rule{
+	keywords: []string{"honey", "ghee"},
+}
+
+// words splits an ingredient name into normalized words so it can be searched for phrases. Words
+// are split on spaces and punctuation, including hyphens.
+func words(name string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, field := range fields {
+		fields[i] = ingredients.Key(field)
+	}
+
+	return fields
+}
+
+// containsPhrase reports if the words contain the phrase as a consecutive run of words.
+func containsPhrase(haystack []string, phrase string) bool {
+	needle := words(phrase)
+	if len(needle) == 0 {
+		return false
+	}
+
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j, word := range needle {
+			if haystack[i+j] != word {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Detect infers labels from the names of the ingredients in a list. Diets are only inferred if the
+// list contains at least one named ingredient.
+func Detect(list []ingredients.Ingredient) Labels {
+	labels := Labels{Sources: make(map[Allergen][]string)}
+
+	named := 0
+	meat, animal := false, false
+	for _, ingredient := range list {
+		if ingredient.Name == "" {
+			continue
+		}
+		named++
+
+		name := words(ingredient.Name)
+		for _, allergen := range Allergens {
+			if allergenRules[allergen].matches(name) {
+				labels.Allergens = labels.Allergens.With(allergen)
+				labels.Sources[allergen] = append(labels.Sources[allergen], ingredient.Name)
+			}
+		}
+
+		meat = meat || meatRule.matches(name)
+		animal = animal || animalRule.matches(name)
+	}
+
+	labels.Vegetarian = named > 0 && !meat
+	labels.Vegan = labels.Vegetarian && !animal && !labels.Allergens.Has(Dairy) && !labels.Allergens.Has(Egg)
+
+	return labels
+}
@@ -0,0 +1,165 @@
+package dietary_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/dietary"
+	"github.com/cdriehuys/recipes/internal/ingredients"
+)
+
+func allergenNames(set dietary.AllergenSet) string {
+	var names []string
+	for _, allergen := range set.List() {
+		names = append(names, string(allergen))
+	}
+
+	return strings.Join(names, ",")
+}
+
+func TestDetect_allergens(t *testing.T) {
+	testCases := []struct {
+		line string
+		want string
+	}{
+		{line: "2 cups all-purpose flour", want: "gluten"},
+		{line: "1 cup gluten-free flour", want: ""},
+		{line: "1 cup almond flour", want: "nuts"},
+		{line: "1 cup rice flour", want: ""},
+		{line: "8 oz egg noodles", want: "gluten,egg"},
+		{line: "2 tbsp soy sauce", want: "gluten,soy"},
+		{line: "2 tbsp tamari", want: "soy"},
+		{line: "1 block extra-firm tofu", want: "soy"},
+		{line: "1 cup whole milk", want: "dairy"},
+		{line: "2 tbsp unsalted butter", want: "dairy"},
+		{line: "1/2 cup peanut butter", want: "nuts"},
+		{line: "1 can coconut milk", want: ""},
+		{line: "1 cup unsweetened almond milk", want: "nuts"},
+		{line: "1/2 tsp cream of tartar", want: ""},
+		{line: "1 cup shredded Cheddar cheese", want: "dairy"},
+		{line: "1 cup vegan cheese", want: ""},
+		{line: "1/2 cup chopped walnuts", want: "nuts"},
+		{line: "2 tbsp pine nuts", want: "nuts"},
+		{line: "1/2 tsp nutmeg", want: ""},
+		{line: "1 butternut squash", want: ""},
+		{line: "1 can water chestnuts", want: ""},
+		{line: "1 cup shredded coconut", want: ""},
+		{line: "3 large eggs", want: "egg"},
+		{line: "1 eggplant", want: ""},
+		{line: "1/4 cup mayonnaise", want: "egg"},
+		{line: "1 lb shrimp, peeled", want: "shellfish"},
+		{line: "8 oz oyster mushrooms", want: ""},
+		{line: "4 salmon fillets", want: ""},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.line, func(t *testing.T) {
+			labels := dietary.Detect([]ingredients.Ingredient{ingredients.Parse(tt.line)})
+
+			assert.Equal(t, tt.want, allergenNames(labels.Allergens))
+		})
+	}
+}
+
+func TestDetect_sources(t *testing.T) {
+	labels := dietary.Detect(ingredients.ParseList("1 cup flour\n1 cup milk\n2 tbsp butter"))
+
+	assert.Equal(t, "milk, butter", strings.Join(labels.Sources[dietary.Dairy], ", "))
+	assert.Equal(t, "flour", strings.Join(labels.Sources[dietary.Gluten], ", "))
+}
+
+func TestDetect_diets(t *testing.T) {
+	testCases := []struct {
+		name           string
+		ingredients    string
+		wantVegetarian bool
+		wantVegan      bool
+	}{
+		{
+			name:           "vegan",
+			ingredients:    "1 can chickpeas\n2 tbsp olive oil\n1 lemon",
+			wantVegetarian: true,
+			wantVegan:      true,
+		},
+		{
+			name:           "dairy",
+			ingredients:    "1 lb pasta\n1 cup parmesan",
+			wantVegetarian: true,
+		},
+		{
+			name:           "eggs",
+			ingredients:    "4 eggs\nsalt",
+			wantVegetarian: true,
+		},
+		{
+			name:           "honey",
+			ingredients:    "1 cup oats\n2 tbsp honey",
+			wantVegetarian: true,
+		},
+		{
+			name:        "meat",
+			ingredients: "1 lb ground beef\n1 onion",
+		},
+		{
+			name:        "fish",
+			ingredients: "2 salmon fillets\n1 lemon",
+		},
+		{
+			name:        "chicken broth",
+			ingredients: "4 cups chicken broth\n1 cup rice",
+		},
+		{
+			name:           "vegetable broth",
+			ingredients:    "4 cups vegetable broth\n1 cup rice",
+			wantVegetarian: true,
+			wantVegan:      true,
+		},
+		{
+			name:           "plant-based sausage",
+			ingredients:    "1 package plant-based sausage",
+			wantVegetarian: true,
+			wantVegan:      true,
+		},
+		{
+			name:        "worcestershire",
+			ingredients: "1 tbsp Worcestershire sauce",
+		},
+		{
+			name:        "no ingredients",
+			ingredients: "",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := dietary.Detect(ingredients.ParseList(tt.ingredients))
+
+			assert.Equal(t, tt.wantVegetarian, labels.Vegetarian)
+			assert.Equal(t, tt.wantVegan, labels.Vegan)
+		})
+	}
+}
+
+func TestLabels_Apply(t *testing.T) {
+	detected := dietary.Detect(ingredients.ParseList("1 cup flour\n1 cup milk"))
+
+	labels := detected.Apply(dietary.Overrides{"gluten": false, "nuts": true})
+	assert.Equal(t, "dairy,nuts", allergenNames(labels.Allergens))
+
+	labels = detected.Apply(dietary.Overrides{"vegan": true})
+	assert.Equal(t, true, labels.Vegan)
+	assert.Equal(t, true, labels.Vegetarian)
+
+	labels = dietary.Detect(ingredients.ParseList("1 cup rice")).Apply(dietary.Overrides{"vegetarian": false})
+	assert.Equal(t, false, labels.Vegetarian)
+	assert.Equal(t, false, labels.Vegan)
+}
+
+func TestLabels_Suits(t *testing.T) {
+	labels := dietary.Labels{Vegetarian: true}
+
+	assert.Equal(t, true, labels.Suits(""))
+	assert.Equal(t, true, labels.Suits(dietary.Vegetarian))
+	assert.Equal(t, false, labels.Suits(dietary.Vegan))
+}
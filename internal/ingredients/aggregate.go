@@ -0,0 +1,140 @@
+package ingredients
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Key returns a normalized form of an ingredient name so that trivially different spellings, such
+// as "Eggs" and "egg", compare equal.
+func Key(name string) string {
+	key := strings.Join(strings.Fields(strings.ToLower(name)), " ")
+
+	switch {
+	case strings.HasSuffix(key, "oes"):
+		key = strings.TrimSuffix(key, "es")
+	case strings.HasSuffix(key, "s") && !strings.HasSuffix(key, "ss") && len(key) > 3:
+		key = strings.TrimSuffix(key, "s")
+	}
+
+	return key
+}
+
+// Convert returns the quantity expressed in a different unit of the same dimension. The returned
+// boolean is false if the units cannot be converted between.
+func Convert(quantity float64, from, to Unit) (float64, bool) {
+	if from.Dimension != to.Dimension {
+		return 0, false
+	}
+
+	if from.Dimension == Discrete {
+		return quantity, from.Name == to.Name
+	}
+
+	return quantity * from.Factor / to.Factor, true
+}
+
+// Aggregate combines ingredients with the same name into a single entry wherever their units can be
+// converted between. Combined amounts are expressed in the unit of the first occurrence of the
+// ingredient. Ingredients without a quantity are dropped if the same ingredient also appears with
+// one. The order of first occurrence is preserved, and notes and raw text are discarded.
+func Aggregate(list []Ingredient) []Ingredient {
+	var combined []Ingredient
+	byName := make(map[string][]int)
+
+	for _, ingredient := range list {
+		ingredient.Note = ""
+		ingredient.Raw = ""
+		key := Key(ingredient.Name)
+
+		merged := false
+		for _, i := range byName[key] {
+			existing := &combined[i]
+
+			if !ingredient.HasQuantity() {
+				merged = true
+				break
+			}
+
+			if !existing.HasQuantity() {
+				existing.Quantity = ingredient.Quantity
+				existing.Unit = ingredient.Unit
+				merged = true
+				break
+			}
+
+			if q, ok := Convert(ingredient.Quantity, ingredient.Unit, existing.Unit); ok {
+				existing.Quantity += q
+				merged = true
+				break
+			}
+		}
+
+		if !merged {
+			byName[key] = append(byName[key], len(combined))
+			combined = append(combined, ingredient)
+		}
+	}
+
+	return combined
+}
+
+// Matches reports if an ingredient on hand, such as "flour", satisfies an ingredient called for by
+// a recipe, such as "All-purpose flour". The recipe's ingredient matches if it is the same as the
+// one on hand or ends with it as a separate word.
+func Matches(have, want string) bool {
+	haveKey, wantKey := Key(have), Key(want)
+	if haveKey == "" {
+		return false
+	}
+
+	return wantKey == haveKey || strings.HasSuffix(wantKey, " "+haveKey)
+}
+
+// MentionedIn returns the ingredients from the list that are referred to in a piece of text, such
+// as a step of a recipe's instructions. An ingredient is mentioned if its full name appears in the
+// text, or if the last word of its name does, so "2 cups all-purpose flour" is mentioned by "Whisk
+// the flour and salt".
+func MentionedIn(text string, list []Ingredient) []Ingredient {
+	words := keyWords(text)
+
+	var mentioned []Ingredient
+	for _, ingredient := range list {
+		name := keyWords(ingredient.Name)
+		if len(name) == 0 {
+			continue
+		}
+
+		if containsWords(words, name) || containsWords(words, name[len(name)-1:]) {
+			mentioned = append(mentioned, ingredient)
+		}
+	}
+
+	return mentioned
+}
+
+// keyWords splits text into individual words normalized with Key.
+func keyWords(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	words := make([]string, 0, len(fields))
+	for _, field := range fields {
+		words = append(words, Key(field))
+	}
+
+	return words
+}
+
+// containsWords reports if the phrase appears as consecutive words in the text.
+func containsWords(text, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(text); i++ {
+		if slices.Equal(text[i:i+len(phrase)], phrase) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,136 @@
+package ingredients_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/ingredients"
+)
+
+func TestKey(t *testing.T) {
+	testCases := []struct {
+		name string
+		want string
+	}{
+		{name: "Eggs", want: "egg"},
+		{name: "egg", want: "egg"},
+		{name: "  Tomatoes ", want: "tomato"},
+		{name: "olive   oil", want: "olive oil"},
+		{name: "grass", want: "grass"},
+		{name: "gas", want: "gas"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ingredients.Key(tt.name))
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	cup, _ := ingredients.LookupUnit("cup")
+	tbsp, _ := ingredients.LookupUnit("tbsp")
+	gram, _ := ingredients.LookupUnit("g")
+	clove, _ := ingredients.LookupUnit("clove")
+	can, _ := ingredients.LookupUnit("can")
+
+	q, ok := ingredients.Convert(1, cup, tbsp)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "16", ingredients.FormatQuantity(q))
+
+	_, ok = ingredients.Convert(1, cup, gram)
+	assert.Equal(t, false, ok)
+
+	_, ok = ingredients.Convert(1, clove, can)
+	assert.Equal(t, false, ok)
+
+	q, ok = ingredients.Convert(2, clove, clove)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 2.0, q)
+}
+
+func TestAggregate(t *testing.T) {
+	list := ingredients.ParseList(`2 cups milk
+250 ml milk
+2 eggs
+1 cup flour
+100 g flour
+salt, to taste
+1 tsp salt
+3 Eggs, beaten
+1 clove garlic
+1 can garlic`)
+
+	got := ingredients.Aggregate(list)
+
+	want := []string{
+		"3.06 cups milk",
+		"5 eggs",
+		"1 cup flour",
+		"100 g flour",
+		"1 tsp salt",
+		"1 clove garlic",
+		"1 can garlic",
+	}
+
+	assert.Equal(t, len(want), len(got))
+	for i := range min(len(want), len(got)) {
+		assert.Equal(t, want[i], got[i].String())
+	}
+}
+
+func TestMatches(t *testing.T) {
+	testCases := []struct {
+		have string
+		want string
+		ok   bool
+	}{
+		{have: "flour", want: "flour", ok: true},
+		{have: "Eggs", want: "egg", ok: true},
+		{have: "flour", want: "all-purpose flour", ok: true},
+		{have: "flour", want: "whole wheat flour", ok: true},
+		{have: "all-purpose flour", want: "flour", ok: false},
+		{have: "oil", want: "olive oil", ok: true},
+		{have: "oil", want: "foil", ok: false},
+		{have: "milk", want: "milk chocolate", ok: false},
+		{have: "", want: "milk", ok: false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.have+" for "+tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.ok, ingredients.Matches(tt.have, tt.want))
+		})
+	}
+}
+
+func TestMentionedIn(t *testing.T) {
+	list := ingredients.ParseList("2 cups all-purpose flour\n3 large eggs\n1 tsp salt\n2 tbsp olive oil\n4 tomatoes, diced\n1 cup milk")
+
+	testCases := []struct {
+		step string
+		want string
+	}{
+		{step: "Whisk the flour and salt together.", want: "all-purpose flour|salt"},
+		{step: "Beat the eggs.", want: "large eggs"},
+		{step: "Beat the egg.", want: "large eggs"},
+		{step: "Heat the olive oil in a skillet.", want: "olive oil"},
+		{step: "Heat the oil in a skillet.", want: "olive oil"},
+		{step: "Add the tomato and cook down.", want: "tomatoes"},
+		{step: "Cover with foil.", want: ""},
+		{step: "Pour in the MILK.", want: "milk"},
+		{step: "Salted water should boil.", want: ""},
+		{step: "Preheat the oven.", want: ""},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.step, func(t *testing.T) {
+			var names []string
+			for _, ingredient := range ingredients.MentionedIn(tt.step, list) {
+				names = append(names, ingredient.Name)
+			}
+
+			assert.Equal(t, tt.want, strings.Join(names, "|"))
+		})
+	}
+}
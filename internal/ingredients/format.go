@@ -0,0 +1,70 @@
+package ingredients
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// fractionTolerance is how close a quantity must be to a common kitchen fraction to be displayed
+// as that fraction.
+const fractionTolerance = 0.01
+
+var displayFractions = []struct {
+	value float64
+	text  string
+}{
+	{1.0 / 8, "1/8"},
+	{1.0 / 4, "1/4"},
+	{1.0 / 3, "1/3"},
+	{3.0 / 8, "3/8"},
+	{1.0 / 2, "1/2"},
+	{5.0 / 8, "5/8"},
+	{2.0 / 3, "2/3"},
+	{3.0 / 4, "3/4"},
+	{7.0 / 8, "7/8"},
+}
+
+// FormatQuantity formats a quantity for display, using common kitchen fractions where possible.
+// Other quantities are rounded to two decimal places. Zero is formatted as an empty string.
+func FormatQuantity(quantity float64) string {
+	if quantity <= 0 {
+		return ""
+	}
+
+	whole, frac := math.Modf(quantity)
+	if frac < fractionTolerance {
+		return strconv.FormatFloat(whole, 'f', -1, 64)
+	}
+
+	if 1-frac < fractionTolerance {
+		return strconv.FormatFloat(whole+1, 'f', -1, 64)
+	}
+
+	for _, f := range displayFractions {
+		if math.Abs(frac-f.value) < fractionTolerance {
+			if whole == 0 {
+				return f.text
+			}
+
+			return strconv.FormatFloat(whole, 'f', -1, 64) + " " + f.text
+		}
+	}
+
+	s := strconv.FormatFloat(quantity, 'f', 2, 64)
+	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+}
+
+// FormatAmount formats a quantity along with its unit, such as "1 1/2 cups".
+func FormatAmount(quantity float64, unit Unit) string {
+	q := FormatQuantity(quantity)
+	if q == "" {
+		return ""
+	}
+
+	if label := unit.Label(quantity); label != "" {
+		return q + " " + label
+	}
+
+	return q
+}
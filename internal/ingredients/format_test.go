@@ -0,0 +1,40 @@
+package ingredients_test
+
+import (
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/ingredients"
+)
+
+func TestFormatQuantity(t *testing.T) {
+	testCases := []struct {
+		quantity float64
+		want     string
+	}{
+		{quantity: 0, want: ""},
+		{quantity: 1, want: "1"},
+		{quantity: 0.5, want: "1/2"},
+		{quantity: 1.0 / 3, want: "1/3"},
+		{quantity: 2.75, want: "2 3/4"},
+		{quantity: 0.999, want: "1"},
+		{quantity: 3.06, want: "3.06"},
+		{quantity: 723.18, want: "723.18"},
+		{quantity: 1.1, want: "1.1"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, ingredients.FormatQuantity(tt.quantity))
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	cup, _ := ingredients.LookupUnit("cup")
+
+	assert.Equal(t, "1 cup", ingredients.FormatAmount(1, cup))
+	assert.Equal(t, "1 1/2 cups", ingredients.FormatAmount(1.5, cup))
+	assert.Equal(t, "2", ingredients.FormatAmount(2, ingredients.Unit{}))
+	assert.Equal(t, "", ingredients.FormatAmount(0, cup))
+}
@@ -0,0 +1,206 @@
+// Package ingredients parses free-form ingredient lines such as "1 1/2 cups flour, sifted" into
+// structured amounts that can be scaled, converted, and combined.
+package ingredients
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Ingredient is a single parsed line of an ingredient list.
+type Ingredient struct {
+	// Raw is the original text of the line.
+	Raw string
+	// Quantity is the amount of the ingredient, or zero if no amount was given.
+	Quantity float64
+	// Unit is the unit the quantity is measured in. The zero value indicates a bare count, such as
+	// "2 eggs".
+	Unit Unit
+	// Name identifies the ingredient, such as "flour".
+	Name string
+	// Note holds any preparation details following the name, such as "sifted".
+	Note string
+}
+
+// HasQuantity reports if an amount was provided for the ingredient.
+func (i Ingredient) HasQuantity() bool {
+	return i.Quantity > 0
+}
+
+// Amount returns the formatted quantity and unit of the ingredient, such as "1 1/2 cups".
+func (i Ingredient) Amount() string {
+	return FormatAmount(i.Quantity, i.Unit)
+}
+
+// Scale returns a copy of the ingredient with its quantity multiplied by the provided factor.
+func (i Ingredient) Scale(factor float64) Ingredient {
+	i.Quantity *= factor
+	return i
+}
+
+// String returns the ingredient formatted as it would appear in an ingredient list.
+func (i Ingredient) String() string {
+	parts := make([]string, 0, 2)
+	if amount := i.Amount(); amount != "" {
+		parts = append(parts, amount)
+	}
+
+	if i.Name != "" {
+		parts = append(parts, i.Name)
+	}
+
+	s := strings.Join(parts, " ")
+	if i.Note != "" {
+		s += ", " + i.Note
+	}
+
+	return s
+}
+
+var unicodeFractions = map[rune]float64{
+	'¼': 0.25,
+	'½': 0.5,
+	'¾': 0.75,
+	'⅓': 1.0 / 3,
+	'⅔': 2.0 / 3,
+	'⅛': 0.125,
+	'⅜': 0.375,
+	'⅝': 0.625,
+	'⅞': 0.875,
+}
+
+// quantityRX matches a mixed number, fraction, decimal, or whole number optionally followed by a
+// unicode fraction.
+var quantityRX = regexp.MustCompile(`^(?:(\d+)\s+(\d+)/(\d+)|(\d+)/(\d+)|(\d*\.?\d+)?([¼½¾⅓⅔⅛⅜⅝⅞])?)`)
+
+// rangeRX matches the separator between the bounds of a quantity range, such as "2-3" or "2 to 3".
+var rangeRX = regexp.MustCompile(`^\s*(?:-|–|to\s)\s*`)
+
+// parseQuantity consumes a quantity from the start of the string, returning the quantity and the
+// remaining text. If no quantity is present, zero is returned along with the unmodified string.
+func parseQuantity(s string) (float64, string) {
+	m := quantityRX.FindStringSubmatchIndex(s)
+	if m == nil || m[1] == 0 {
+		return 0, s
+	}
+
+	group := func(n int) string {
+		if m[2*n] < 0 {
+			return ""
+		}
+
+		return s[m[2*n]:m[2*n+1]]
+	}
+
+	var quantity float64
+	switch {
+	case group(1) != "":
+		whole, _ := strconv.ParseFloat(group(1), 64)
+		quantity = whole + fraction(group(2), group(3))
+	case group(4) != "":
+		quantity = fraction(group(4), group(5))
+	default:
+		quantity, _ = strconv.ParseFloat(group(6), 64)
+		if f := group(7); f != "" {
+			quantity += unicodeFractions[[]rune(f)[0]]
+		} else if rest := strings.TrimLeft(s[m[1]:], " "); rest != "" {
+			// Allow a space between a whole number and a unicode fraction, as in "1 ½".
+			r := []rune(rest)[0]
+			if f, ok := unicodeFractions[r]; ok {
+				return quantity + f, rest[len(string(r)):]
+			}
+		}
+	}
+
+	return quantity, s[m[1]:]
+}
+
+func fraction(numerator, denominator string) float64 {
+	n, _ := strconv.ParseFloat(numerator, 64)
+	d, _ := strconv.ParseFloat(denominator, 64)
+	if d == 0 {
+		return 0
+	}
+
+	return n / d
+}
+
+// Parse interprets a single line of an ingredient list. Lines that cannot be interpreted are
+// returned with their whole text as the name.
+func Parse(line string) Ingredient {
+	line = strings.TrimSpace(line)
+	ingredient := Ingredient{Raw: line}
+
+	// Bullets are commonly left over when ingredient lists are copied from elsewhere.
+	rest := strings.TrimLeft(line, "-*• \t")
+
+	ingredient.Quantity, rest = parseQuantity(rest)
+	if ingredient.HasQuantity() {
+		// Shopping and scaling both err on the side of having enough, so ranges use their upper
+		// bound.
+		if loc := rangeRX.FindStringIndex(rest); loc != nil {
+			if upper, after := parseQuantity(rest[loc[1]:]); upper > 0 {
+				ingredient.Quantity, rest = upper, after
+			}
+		}
+	}
+
+	rest = strings.TrimSpace(rest)
+
+	// A parenthetical directly after the quantity usually describes a package size, as in
+	// "1 (15 oz) can black beans".
+	var packageSize string
+	if strings.HasPrefix(rest, "(") {
+		if end := strings.Index(rest, ")"); end > 0 {
+			packageSize = strings.TrimSpace(rest[1:end])
+			rest = strings.TrimSpace(rest[end+1:])
+		}
+	}
+
+	if ingredient.HasQuantity() {
+		ingredient.Unit, rest = parseUnit(rest)
+	}
+
+	rest = strings.TrimPrefix(rest, "of ")
+
+	name, note, _ := strings.Cut(rest, ",")
+	ingredient.Name = strings.TrimSpace(name)
+	ingredient.Note = strings.TrimSpace(note)
+
+	if packageSize != "" {
+		ingredient.Note = strings.TrimPrefix(strings.Join([]string{ingredient.Note, packageSize}, "; "), "; ")
+	}
+
+	return ingredient
+}
+
+// parseUnit consumes a unit from the start of the string. If the string does not start with a
+// known unit, the zero Unit is returned along with the unmodified string.
+func parseUnit(s string) (Unit, string) {
+	// Try two word units such as "fl oz" before single words.
+	words := strings.FieldsFunc(s, unicode.IsSpace)
+	for n := min(2, len(words)); n > 0; n-- {
+		candidate := strings.Join(words[:n], " ")
+		if u, ok := LookupUnit(candidate); ok {
+			return u, strings.TrimSpace(strings.Join(words[n:], " "))
+		}
+	}
+
+	return Unit{}, s
+}
+
+// ParseList parses each non-blank line of an ingredient list.
+func ParseList(text string) []Ingredient {
+	var list []Ingredient
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		list = append(list, Parse(line))
+	}
+
+	return list
+}
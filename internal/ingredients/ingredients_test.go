@@ -0,0 +1,84 @@
+package ingredients_test
+
+import (
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/ingredients"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		line         string
+		wantQuantity string
+		wantUnit     string
+		wantName     string
+		wantNote     string
+	}{
+		{line: "2 cups milk", wantQuantity: "2", wantUnit: "cup", wantName: "milk"},
+		{line: "1 1/2 cups flour, sifted", wantQuantity: "1 1/2", wantUnit: "cup", wantName: "flour", wantNote: "sifted"},
+		{line: "3/4 tsp salt", wantQuantity: "3/4", wantUnit: "tsp", wantName: "salt"},
+		{line: "½ cup sugar", wantQuantity: "1/2", wantUnit: "cup", wantName: "sugar"},
+		{line: "1½ cups water", wantQuantity: "1 1/2", wantUnit: "cup", wantName: "water"},
+		{line: "1 ½ cups water", wantQuantity: "1 1/2", wantUnit: "cup", wantName: "water"},
+		{line: "0.5 kg potatoes", wantQuantity: "1/2", wantUnit: "kg", wantName: "potatoes"},
+		{line: "250 ml milk", wantQuantity: "250", wantUnit: "ml", wantName: "milk"},
+		{line: "2 T butter", wantQuantity: "2", wantUnit: "tbsp", wantName: "butter"},
+		{line: "2 t vanilla", wantQuantity: "2", wantUnit: "tsp", wantName: "vanilla"},
+		{line: "1 Tbsp. olive oil", wantQuantity: "1", wantUnit: "tbsp", wantName: "olive oil"},
+		{line: "8 fl oz stock", wantQuantity: "8", wantUnit: "fl oz", wantName: "stock"},
+		{line: "2-3 cloves garlic, minced", wantQuantity: "3", wantUnit: "clove", wantName: "garlic", wantNote: "minced"},
+		{line: "2 to 3 lbs chicken thighs", wantQuantity: "3", wantUnit: "lb", wantName: "chicken thighs"},
+		{line: "1 (15 oz) can black beans, drained", wantQuantity: "1", wantUnit: "can", wantName: "black beans", wantNote: "drained; 15 oz"},
+		{line: "3 large eggs", wantQuantity: "3", wantName: "large eggs"},
+		{line: "1 tomato", wantQuantity: "1", wantName: "tomato"},
+		{line: "- 2 cups of rice", wantQuantity: "2", wantUnit: "cup", wantName: "rice"},
+		{line: "1 batch Pizza Dough", wantQuantity: "1", wantUnit: "batch", wantName: "Pizza Dough"},
+		{line: "2 batches of marinara", wantQuantity: "2", wantUnit: "batch", wantName: "marinara"},
+		{line: "Salt and pepper, to taste", wantName: "Salt and pepper", wantNote: "to taste"},
+		{line: "  ", wantName: ""},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.line, func(t *testing.T) {
+			got := ingredients.Parse(tt.line)
+
+			assert.Equal(t, tt.wantQuantity, ingredients.FormatQuantity(got.Quantity))
+			assert.Equal(t, tt.wantUnit, got.Unit.Name)
+			assert.Equal(t, tt.wantName, got.Name)
+			assert.Equal(t, tt.wantNote, got.Note)
+		})
+	}
+}
+
+func TestParseList(t *testing.T) {
+	list := ingredients.ParseList("2 eggs\n\n  \n1 cup flour\r\n")
+
+	assert.Equal(t, 2, len(list))
+	assert.Equal(t, "eggs", list[0].Name)
+	assert.Equal(t, "flour", list[1].Name)
+}
+
+func TestIngredient_String(t *testing.T) {
+	testCases := []struct {
+		line string
+		want string
+	}{
+		{line: "1 1/2 cups flour, sifted", want: "1 1/2 cups flour, sifted"},
+		{line: "1 cup flour", want: "1 cup flour"},
+		{line: "2 eggs", want: "2 eggs"},
+		{line: "salt", want: "salt"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.line, func(t *testing.T) {
+			assert.Equal(t, tt.want, ingredients.Parse(tt.line).String())
+		})
+	}
+}
+
+func TestIngredient_Scale(t *testing.T) {
+	scaled := ingredients.Parse("3/4 cup sugar").Scale(2)
+
+	assert.Equal(t, "1 1/2 cups sugar", scaled.String())
+}
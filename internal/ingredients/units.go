@@ -0,0 +1,91 @@
+package ingredients
+
+import "strings"
+
+// Dimension is the physical quantity a unit measures. Amounts may only be converted between units
+// of the same dimension.
+type Dimension string
+
+const (
+	// Discrete units, such as cloves or cans, can only be combined with the same unit.
+	Discrete Dimension = ""
+	Volume   Dimension = "volume"
+	Mass     Dimension = "mass"
+)
+
+// Unit is a unit of measurement used in ingredient lists.
+type Unit struct {
+	Name      string
+	Plural    string
+	Dimension Dimension
+
+	// Factor converts an amount in this unit to the base unit of its dimension, which is milliliters
+	// for volumes and grams for masses.
+	Factor float64
+}
+
+// Label returns the name of the unit appropriate for the provided quantity.
+func (u Unit) Label(quantity float64) string {
+	if quantity > 1 && u.Plural != "" {
+		return u.Plural
+	}
+
+	return u.Name
+}
+
+var units = []struct {
+	unit    Unit
+	aliases []string
+}{
+	{Unit{"tsp", "tsp", Volume, 4.92892}, []string{"teaspoon", "teaspoons", "tsp", "tsps"}},
+	{Unit{"tbsp", "tbsp", Volume, 14.7868}, []string{"tablespoon", "tablespoons", "tbsp", "tbsps", "tbs", "tbl"}},
+	{Unit{"fl oz", "fl oz", Volume, 29.5735}, []string{"fl oz", "fl. oz", "fluid ounce", "fluid ounces"}},
+	{Unit{"cup", "cups", Volume, 236.588}, []string{"cup", "cups", "c"}},
+	{Unit{"pint", "pints", Volume, 473.176}, []string{"pint", "pints", "pt"}},
+	{Unit{"quart", "quarts", Volume, 946.353}, []string{"quart", "quarts", "qt"}},
+	{Unit{"gallon", "gallons", Volume, 3785.41}, []string{"gallon", "gallons", "gal"}},
+	{Unit{"ml", "ml", Volume, 1}, []string{"ml", "milliliter", "milliliters", "millilitre", "millilitres"}},
+	{Unit{"l", "l", Volume, 1000}, []string{"l", "liter", "liters", "litre", "litres"}},
+	{Unit{"g", "g", Mass, 1}, []string{"g", "gram", "grams", "gr"}},
+	{Unit{"kg", "kg", Mass, 1000}, []string{"kg", "kilogram", "kilograms", "kilo", "kilos"}},
+	{Unit{"oz", "oz", Mass, 28.3495}, []string{"oz", "ounce", "ounces"}},
+	{Unit{"lb", "lbs", Mass, 453.592}, []string{"lb", "lbs", "pound", "pounds"}},
+	{Unit{"clove", "cloves", Discrete, 1}, []string{"clove", "cloves"}},
+	{Unit{"can", "cans", Discrete, 1}, []string{"can", "cans"}},
+	{Unit{"package", "packages", Discrete, 1}, []string{"package", "packages", "pkg"}},
+	{Unit{"bunch", "bunches", Discrete, 1}, []string{"bunch", "bunches"}},
+	{Unit{"pinch", "pinches", Discrete, 1}, []string{"pinch", "pinches"}},
+	{Unit{"slice", "slices", Discrete, 1}, []string{"slice", "slices"}},
+	{Unit{"stick", "sticks", Discrete, 1}, []string{"stick", "sticks"}},
+	// Batches measure ingredients that are made from another recipe, such as "1 batch pizza dough".
+	{Unit{"batch", "batches", Discrete, 1}, []string{"batch", "batches"}},
+}
+
+// caseSensitiveUnits holds abbreviations whose meaning depends on their capitalization.
+var caseSensitiveUnits = map[string]string{
+	"T": "tbsp",
+	"t": "tsp",
+}
+
+var unitsByAlias = func() map[string]Unit {
+	byAlias := make(map[string]Unit)
+	for _, u := range units {
+		for _, alias := range u.aliases {
+			byAlias[alias] = u.unit
+		}
+	}
+
+	return byAlias
+}()
+
+// LookupUnit returns the unit with the provided name or abbreviation.
+func LookupUnit(name string) (Unit, bool) {
+	name = strings.TrimSuffix(name, ".")
+
+	if canonical, ok := caseSensitiveUnits[name]; ok {
+		return unitsByAlias[canonical], true
+	}
+
+	u, ok := unitsByAlias[strings.ToLower(name)]
+	return u, ok
+}
@@ -0,0 +1,83 @@
+package models
+
+import "fmt"
+
+// HouseholdRole is the level of access a member has to a household's recipes and categories.
+type HouseholdRole string
+
+const (
+	// RoleViewer members may only view the household's recipes.
+	RoleViewer HouseholdRole = "viewer"
+	// RoleEditor members may additionally create, edit, and delete the household's recipes.
+	RoleEditor HouseholdRole = "editor"
+	// RoleAdmin members may additionally manage the household's members and invitations.
+	RoleAdmin HouseholdRole = "admin"
+)
+
+// HouseholdRoles lists every role in order of increasing privilege.
+var HouseholdRoles = []HouseholdRole{RoleViewer, RoleEditor, RoleAdmin}
+
+// CanEdit reports if the role allows modifying the household's recipes and categories.
+func (r HouseholdRole) CanEdit() bool {
+	return r == RoleEditor || r == RoleAdmin
+}
+
+// CanManage reports if the role allows managing the household's membership.
+func (r HouseholdRole) CanManage() bool {
+	return r == RoleAdmin
+}
+
+// visibleTo returns a SQL condition matching the rows of the aliased table that may be viewed by
+// the user passed as the first query parameter. Rows belonging to a household are visible to all
+// of its members, and other rows are only visible to their owner.
+func visibleTo(alias string) string {
+	return fmt.Sprintf(`((%[1]s.household IS NULL AND %[1]s.owner = $1)
+		OR EXISTS (SELECT 1 FROM household_members AS hm
+			WHERE hm.household = %[1]s.household AND hm.member = $1))`, alias)
+}
+
+// editableBy returns a SQL condition matching the rows of the aliased table that may be modified by
+// the user passed as the first query parameter.
+func editableBy(alias string) string {
+	return fmt.Sprintf(`((%[1]s.household IS NULL AND %[1]s.owner = $1)
+		OR EXISTS (SELECT 1 FROM household_members AS hm
+			WHERE hm.household = %[1]s.household AND hm.member = $1 AND hm."role" IN ('editor', 'admin')))`, alias)
+}
+
+// householdWritable returns a SQL condition that is true if the household ID in the given query
+// parameter is either null or a household the user passed as the first query parameter may add
+// objects to.
+func householdWritable(param string) string {
+	return fmt.Sprintf(`(%[1]s::uuid IS NULL
+		OR EXISTS (SELECT 1 FROM household_members AS hm
+			WHERE hm.household = %[1]s AND hm.member = $1 AND hm."role" IN ('editor', 'admin')))`, param)
+}
+
+// categoryVisible returns a SQL condition that is true if the category ID in the given query
+// parameter is either null or a category visible to the user passed as the first query parameter.
+func categoryVisible(param string) string {
+	return fmt.Sprintf(`(%[1]s::uuid IS NULL
+		OR EXISTS (SELECT 1 FROM categories AS c WHERE c.id = %[1]s AND %[2]s))`, param, visibleTo("c"))
+}
+
+// householdChangeableBy returns a SQL condition matching the rows of the aliased table whose
+// household may be changed to the household ID in the given query parameter by the user passed as
+// the first query parameter. Only the row's owner or an admin of its household may move it, since
+// moving a row changes who can see it.
+func householdChangeableBy(alias string, param string) string {
+	return fmt.Sprintf(`(%[1]s.household IS NOT DISTINCT FROM %[2]s::uuid
+		OR %[1]s.owner = $1
+		OR EXISTS (SELECT 1 FROM household_members AS hm
+			WHERE hm.household = %[1]s.household AND hm.member = $1 AND hm."role" = 'admin'))`, alias, param)
+}
+
+// categoryAssignable returns a SQL condition that is true if the category ID in the first query
+// parameter given is either null or a category that a recipe in the household ID in the second
+// parameter may be filed in. Household recipes must use the household's categories, and other
+// recipes must use the personal categories of the recipe's owner.
+func categoryAssignable(categoryParam string, householdParam string, owner string) string {
+	return fmt.Sprintf(`(%[1]s::uuid IS NULL
+		OR EXISTS (SELECT 1 FROM categories AS c
+			WHERE c.id = %[1]s AND c.household IS NOT DISTINCT FROM %[2]s::uuid
+				AND (c.household IS NOT NULL OR c.owner = %[3]s)))`, categoryParam, householdParam, owner)
+}
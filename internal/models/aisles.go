@@ -0,0 +1,178 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/ingredients"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StoreAisle is an aisle of the user's store. Aisles are ordered by position, which matches the
+// order they are walked through while shopping.
+type StoreAisle struct {
+	ID        uuid.UUID `db:"id"`
+	Owner     string    `db:"owner"`
+	Name      string    `db:"name"`
+	Position  int       `db:"position"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type AisleModel struct {
+	DB     *pgxpool.Pool
+	Logger *slog.Logger
+}
+
+// Assign remembers the aisle an ingredient is found in. A nil aisle forgets the ingredient's aisle.
+// The aisle must belong to the user, or ErrNotFound is returned.
+func (model *AisleModel) Assign(ctx context.Context, userID string, ingredient string, aisle *uuid.UUID) error {
+	key := ingredients.Key(ingredient)
+
+	if aisle == nil {
+		query := `DELETE FROM ingredient_aisles WHERE owner = $1 AND ingredient = $2`
+		if _, err := model.DB.Exec(ctx, query, userID, key); err != nil {
+			return fmt.Errorf("failed to clear ingredient aisle: %w", err)
+		}
+
+		return nil
+	}
+
+	query := `INSERT INTO ingredient_aisles (owner, ingredient, aisle)
+		SELECT $1::text, $2::text, a.id FROM store_aisles AS a WHERE a.owner = $1 AND a.id = $3
+		ON CONFLICT (owner, ingredient) DO UPDATE SET aisle = EXCLUDED.aisle`
+	result, err := model.DB.Exec(ctx, query, userID, key, aisle)
+	if err != nil {
+		return fmt.Errorf("failed to assign ingredient aisle: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Assignments returns the aisle of each ingredient the user has assigned one to, keyed by the
+// ingredient's normalized name.
+func (model *AisleModel) Assignments(ctx context.Context, userID string) (map[string]uuid.UUID, error) {
+	rows, err := model.DB.Query(ctx, `SELECT ingredient, aisle FROM ingredient_aisles WHERE owner = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingredient aisles: %w", err)
+	}
+
+	assignments := make(map[string]uuid.UUID)
+
+	var (
+		ingredient string
+		aisle      uuid.UUID
+	)
+	_, err = pgx.ForEachRow(rows, []any{&ingredient, &aisle}, func() error {
+		assignments[ingredient] = aisle
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to map ingredient aisle rows: %w", err)
+	}
+
+	return assignments, nil
+}
+
+// Create adds a new aisle after the user's existing aisles.
+func (model *AisleModel) Create(ctx context.Context, aisle StoreAisle) error {
+	query := `INSERT INTO store_aisles (id, owner, name, position)
+		SELECT $2, $1::text, $3, COALESCE(max(position) + 1, 0) FROM store_aisles WHERE owner = $1`
+	if _, err := model.DB.Exec(ctx, query, aisle.Owner, aisle.ID, aisle.Name); err != nil {
+		return fmt.Errorf("failed to insert store aisle: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Created store aisle.", "id", aisle.ID)
+
+	return nil
+}
+
+// Delete removes one of the user's aisles. Ingredients assigned to the aisle become unassigned.
+func (model *AisleModel) Delete(ctx context.Context, userID string, id uuid.UUID) error {
+	result, err := model.DB.Exec(ctx, `DELETE FROM store_aisles WHERE owner = $1 AND id = $2`, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete store aisle: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Deleted store aisle.", "id", id)
+
+	return nil
+}
+
+// List returns the user's aisles in store order.
+func (model *AisleModel) List(ctx context.Context, userID string) ([]StoreAisle, error) {
+	query := `SELECT id, owner, name, position, created_at FROM store_aisles WHERE owner = $1 ORDER BY position`
+	rows, err := model.DB.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list store aisles: %w", err)
+	}
+	defer rows.Close()
+
+	aisles, err := pgx.CollectRows(rows, pgx.RowToStructByName[StoreAisle])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map store aisle rows to struct: %w", err)
+	}
+
+	return aisles, nil
+}
+
+// Move swaps the position of one of the user's aisles with the aisle before it if offset is
+// negative, or after it if offset is positive. Moving the first aisle up or the last aisle down has
+// no effect.
+func (model *AisleModel) Move(ctx context.Context, userID string, id uuid.UUID, offset int) error {
+	err := pgx.BeginFunc(ctx, model.DB, func(tx pgx.Tx) error {
+		var position int
+		query := `SELECT position FROM store_aisles WHERE owner = $1 AND id = $2 FOR UPDATE`
+		if err := tx.QueryRow(ctx, query, userID, id).Scan(&position); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+
+			return err
+		}
+
+		neighborQuery := `SELECT id, position FROM store_aisles
+			WHERE owner = $1 AND position < $2 ORDER BY position DESC LIMIT 1 FOR UPDATE`
+		if offset > 0 {
+			neighborQuery = `SELECT id, position FROM store_aisles
+				WHERE owner = $1 AND position > $2 ORDER BY position LIMIT 1 FOR UPDATE`
+		}
+
+		var (
+			neighbor         uuid.UUID
+			neighborPosition int
+		)
+		if err := tx.QueryRow(ctx, neighborQuery, userID, position).Scan(&neighbor, &neighborPosition); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+
+			return err
+		}
+
+		query = `UPDATE store_aisles SET position = $2 WHERE id = $1`
+		if _, err := tx.Exec(ctx, query, id, neighborPosition); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, query, neighbor, position)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to move store aisle: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// MaxBulkRecipes is the most recipes a single bulk action may be applied to.
+const MaxBulkRecipes = 100
+
+// NormalizeTag returns the canonical form of a tag, which is lowercase with single spaces between
+// words.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// ListByIDs returns the recipes with the given IDs that the user is allowed to view, ordered by
+// title. If any of the recipes do not exist or are not visible to the user, ErrNotFound is
+// returned.
+func (model *RecipeModel) ListByIDs(ctx context.Context, userID string, ids []uuid.UUID) ([]Recipe, error) {
+	query := recipeSelect + ` WHERE r.id = ANY($2) AND ` + visibleTo("r") + ` ORDER BY r.title`
+
+	ids = uniqueIDs(ids)
+	rows, err := model.DB.Query(ctx, query, userID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recipes by ID: %w", err)
+	}
+	defer rows.Close()
+
+	recipes, err := pgx.CollectRows(rows, pgx.RowToStructByName[Recipe])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map recipe rows to struct: %w", err)
+	}
+
+	if len(recipes) != len(ids) {
+		return nil, ErrNotFound
+	}
+
+	return recipes, nil
+}
+
+// BulkDelete removes all the recipes with the given IDs. Either every recipe is deleted or, if the
+// user is not allowed to edit any one of them, none are and ErrNotFound is returned.
+func (model *RecipeModel) BulkDelete(ctx context.Context, userID string, ids []uuid.UUID) error {
+	err := model.bulkEdit(ctx, userID, ids, func(tx pgx.Tx, ids []uuid.UUID) error {
+		_, err := tx.Exec(ctx, `DELETE FROM recipes WHERE id = ANY($1)`, ids)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	model.Logger.InfoContext(ctx, "Deleted recipes in bulk.", "ids", ids)
+
+	return nil
+}
+
+// BulkMove moves all the recipes with the given IDs into a category, or removes them from their
+// categories if the category is nil. The category must be visible to the user, and each recipe must
+// be allowed in it, as with Update. Either every recipe is moved or none are and ErrNotFound is
+// returned.
+func (model *RecipeModel) BulkMove(ctx context.Context, userID string, ids []uuid.UUID, category *uuid.UUID) error {
+	err := model.bulkEdit(ctx, userID, ids, func(tx pgx.Tx, ids []uuid.UUID) error {
+		var allowed bool
+		query := `SELECT ` + categoryVisible("$2") + ` AND NOT EXISTS (
+			SELECT 1 FROM recipes AS r
+			WHERE r.id = ANY($3) AND NOT ` + categoryAssignable("$2", "r.household", "r.owner") + `
+		)`
+		if err := tx.QueryRow(ctx, query, userID, category, ids).Scan(&allowed); err != nil {
+			return err
+		}
+
+		if !allowed {
+			return ErrNotFound
+		}
+
+		_, err := tx.Exec(ctx, `UPDATE recipes SET category = $2 WHERE id = ANY($1)`, ids, category)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	model.Logger.InfoContext(ctx, "Moved recipes in bulk.", "ids", ids, "category", category)
+
+	return nil
+}
+
+// BulkTag adds a tag to all the recipes with the given IDs. Recipes that already have the tag are
+// left as they are. Either every recipe is tagged or none are and ErrNotFound is returned.
+func (model *RecipeModel) BulkTag(ctx context.Context, userID string, ids []uuid.UUID, tag string) error {
+	err := model.bulkEdit(ctx, userID, ids, func(tx pgx.Tx, ids []uuid.UUID) error {
+		query := `INSERT INTO recipe_tags (recipe, tag)
+			SELECT id, $2 FROM unnest($1::uuid[]) AS id
+			ON CONFLICT (recipe, tag) DO NOTHING`
+		_, err := tx.Exec(ctx, query, ids, tag)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	model.Logger.InfoContext(ctx, "Tagged recipes in bulk.", "ids", ids, "tag", tag)
+
+	return nil
+}
+
+// BulkUntag removes a tag from all the recipes with the given IDs. Either the tag is removed from
+// every recipe or, if the user is not allowed to edit any one of them, from none and ErrNotFound
+// is returned.
+func (model *RecipeModel) BulkUntag(ctx context.Context, userID string, ids []uuid.UUID, tag string) error {
+	err := model.bulkEdit(ctx, userID, ids, func(tx pgx.Tx, ids []uuid.UUID) error {
+		_, err := tx.Exec(ctx, `DELETE FROM recipe_tags WHERE recipe = ANY($1) AND tag = $2`, ids, tag)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	model.Logger.InfoContext(ctx, "Untagged recipes in bulk.", "ids", ids, "tag", tag)
+
+	return nil
+}
+
+// bulkEdit runs an edit of several recipes in a single transaction. The recipes are locked before
+// the edit is run, and if the user is not allowed to edit every one of them, the transaction is
+// rolled back and ErrNotFound is returned.
+func (model *RecipeModel) bulkEdit(ctx context.Context, userID string, ids []uuid.UUID, edit func(pgx.Tx, []uuid.UUID) error) error {
+	ids = uniqueIDs(ids)
+
+	err := pgx.BeginFunc(ctx, model.DB, func(tx pgx.Tx) error {
+		query := `SELECT r.id FROM recipes AS r WHERE r.id = ANY($2) AND ` + editableBy("r") + ` FOR UPDATE`
+		rows, err := tx.Query(ctx, query, userID, ids)
+		if err != nil {
+			return err
+		}
+
+		locked, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+		if err != nil {
+			return err
+		}
+
+		if len(locked) != len(ids) {
+			return ErrNotFound
+		}
+
+		return edit(tx, ids)
+	})
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return err
+		}
+
+		return fmt.Errorf("failed to edit recipes in bulk: %w", err)
+	}
+
+	return nil
+}
+
+// uniqueIDs returns the IDs with any duplicates removed.
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return unique
+}
@@ -2,21 +2,48 @@ package models
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Category struct {
-	ID        uuid.UUID `db:"id"`
-	Owner     string    `db:"owner"`
-	Name      string    `db:"name"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID        uuid.UUID  `db:"id"`
+	Owner     string     `db:"owner"`
+	Household *uuid.UUID `db:"household"`
+	Name      string     `db:"name"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+
+	HouseholdName pgtype.Text `db:"household_name"`
+}
+
+// categorySelect selects all the columns required to populate a Category from the categories table
+// aliased as "c".
+const categorySelect = `SELECT c.id, c.owner, c.household, c.name, c.created_at, c.updated_at, h.name AS household_name
+		FROM categories AS c
+			LEFT JOIN households AS h
+				ON c.household = h.id`
+
+// categoryListLimit is the most categories returned by CategoryModel.List.
+const categoryListLimit = 100
+
+// isDuplicateName reports if the error was caused by a category with the same name already
+// existing in the same household, or among the owner's personal categories.
+func isDuplicateName(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	return pgErr.ConstraintName == "categories_unq_household_name" || pgErr.ConstraintName == "categories_unq_owner_name"
 }
 
 type CategoryModel struct {
@@ -24,25 +51,79 @@ type CategoryModel struct {
 	Logger *slog.Logger
 }
 
+// Create persists a new category. If the category belongs to a household, its owner must be
+// allowed to edit the household's recipes. Otherwise ErrNotFound is returned. If the household, or
+// for a personal category its owner, already has a category with the same name, ErrDuplicate is
+// returned.
 func (model *CategoryModel) Create(ctx context.Context, category Category) error {
-	query := `INSERT INTO categories (id, owner, name) VALUES ($1, $2, $3)`
-	_, err := model.DB.Exec(ctx, query, category.ID, category.Owner, category.Name)
+	query := `INSERT INTO categories (id, owner, household, name)
+		SELECT $2::uuid, $1::text, $3::uuid, $4::text
+		WHERE ` + householdWritable("$3")
+	result, err := model.DB.Exec(ctx, query, category.Owner, category.ID, category.Household, category.Name)
 	if err != nil {
+		if isDuplicateName(err) {
+			return ErrDuplicate
+		}
+
 		return err
 	}
 
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
 	model.Logger.InfoContext(ctx, "Inserted new category.", "id", category.ID)
 
 	return nil
 }
 
-func (model *CategoryModel) List(ctx context.Context, owner string) ([]Category, error) {
-	query := `SELECT id, owner, name, created_at, updated_at
-		FROM categories
-		WHERE owner = $1
-		ORDER BY name
-		LIMIT 100`
-	rows, err := model.DB.Query(ctx, query, owner)
+// Delete removes a category the user is allowed to edit. Recipes in the category become
+// uncategorized.
+func (model *CategoryModel) Delete(ctx context.Context, userID string, id uuid.UUID) error {
+	query := `DELETE FROM categories AS c WHERE c.id = $2 AND ` + editableBy("c")
+	result, err := model.DB.Exec(ctx, query, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Deleted category.", "id", id)
+
+	return nil
+}
+
+// Get returns a category the user is allowed to view.
+func (model *CategoryModel) Get(ctx context.Context, userID string, id uuid.UUID) (Category, error) {
+	rows, err := model.DB.Query(ctx, categorySelect+` WHERE c.id = $2 AND `+visibleTo("c"), userID, id)
+	if err != nil {
+		return Category{}, fmt.Errorf("failed to query for category: %w", err)
+	}
+
+	category, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[Category])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Category{}, ErrNotFound
+		}
+
+		return Category{}, fmt.Errorf("failed to map category row to struct: %w", err)
+	}
+
+	return category, nil
+}
+
+// List returns the categories the user is allowed to view.
+func (model *CategoryModel) List(ctx context.Context, userID string) ([]Category, error) {
+	return model.ListPage(ctx, userID, categoryListLimit, 0)
+}
+
+// ListPage returns the categories the user is allowed to view, ordered by name, skipping the first
+// offset categories and returning at most limit categories.
+func (model *CategoryModel) ListPage(ctx context.Context, userID string, limit int, offset int) ([]Category, error) {
+	query := categorySelect + ` WHERE ` + visibleTo("c") + ` ORDER BY c.name, c.id LIMIT $2 OFFSET $3`
+	rows, err := model.DB.Query(ctx, query, userID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list categories: %w", err)
 	}
@@ -57,3 +138,26 @@ func (model *CategoryModel) List(ctx context.Context, owner string) ([]Category,
 
 	return categories, nil
 }
+
+// Update renames a category the user is allowed to edit. If the category's household, or for a
+// personal category its owner, already has another category with the same name, ErrDuplicate is
+// returned.
+func (model *CategoryModel) Update(ctx context.Context, userID string, category Category) error {
+	query := `UPDATE categories AS c SET name = $3 WHERE c.id = $2 AND ` + editableBy("c")
+	result, err := model.DB.Exec(ctx, query, userID, category.ID, category.Name)
+	if err != nil {
+		if isDuplicateName(err) {
+			return ErrDuplicate
+		}
+
+		return fmt.Errorf("failed to update category: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Updated category.", "id", category.ID)
+
+	return nil
+}
@@ -0,0 +1,301 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Collection is a named, ordered list of recipes, such as a holiday menu. A recipe may belong to
+// any number of collections.
+type Collection struct {
+	ID          uuid.UUID `db:"id"`
+	Owner       string    `db:"owner"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	// ShareToken allows anyone to view the collection if it is set.
+	ShareToken pgtype.Text `db:"share_token"`
+	CreatedAt  time.Time   `db:"created_at"`
+	UpdatedAt  time.Time   `db:"updated_at"`
+
+	RecipeCount int `db:"recipe_count"`
+}
+
+// URL returns the URL of the collection's page.
+func (c Collection) URL() string {
+	return "/collections/" + c.ID.String()
+}
+
+// ShareURL returns the public URL of the collection, or a blank string if it is not shared.
+func (c Collection) ShareURL() string {
+	if !c.ShareToken.Valid {
+		return ""
+	}
+
+	return "/c/" + c.ShareToken.String
+}
+
+// FeedURL returns the URL of the collection's public Atom feed, or a blank string if it is not
+// shared.
+func (c Collection) FeedURL() string {
+	if !c.ShareToken.Valid {
+		return ""
+	}
+
+	return c.ShareURL() + "/feed"
+}
+
+// collectionSelect selects all the columns required to populate a Collection from the collections
+// table aliased as "col".
+const collectionSelect = `SELECT col.id, col.owner, col.name, col.description, col.share_token,
+			col.created_at, col.updated_at,
+			(SELECT count(*) FROM collection_recipes AS cr WHERE cr.collection = col.id) AS recipe_count
+		FROM collections AS col`
+
+type CollectionModel struct {
+	DB     *pgxpool.Pool
+	Logger *slog.Logger
+}
+
+// AddRecipe appends a recipe the user is allowed to view to the end of one of the user's
+// collections. Adding a recipe that is already in the collection has no effect.
+func (model *CollectionModel) AddRecipe(ctx context.Context, userID string, id uuid.UUID, recipeID uuid.UUID) error {
+	query := `INSERT INTO collection_recipes (collection, recipe, position)
+		SELECT col.id, r.id,
+			COALESCE((SELECT max(cr.position) + 1 FROM collection_recipes AS cr WHERE cr.collection = col.id), 0)
+		FROM collections AS col, recipes AS r
+		WHERE col.id = $2 AND col.owner = $1 AND r.id = $3 AND ` + visibleTo("r") + `
+		ON CONFLICT (collection, recipe) DO UPDATE SET position = collection_recipes.position`
+	result, err := model.DB.Exec(ctx, query, userID, id, recipeID)
+	if err != nil {
+		return fmt.Errorf("failed to add recipe to collection: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Added recipe to collection.", "id", id, "recipe", recipeID)
+
+	return nil
+}
+
+// Create persists a new collection.
+func (model *CollectionModel) Create(ctx context.Context, collection Collection) error {
+	query := `INSERT INTO collections (id, owner, name, description) VALUES ($1, $2, $3, $4)`
+	_, err := model.DB.Exec(ctx, query, collection.ID, collection.Owner, collection.Name, collection.Description)
+	if err != nil {
+		return fmt.Errorf("failed to insert collection: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Created collection.", "id", collection.ID)
+
+	return nil
+}
+
+// Delete removes one of the user's collections. The recipes in the collection are not affected.
+func (model *CollectionModel) Delete(ctx context.Context, userID string, id uuid.UUID) error {
+	result, err := model.DB.Exec(ctx, `DELETE FROM collections WHERE owner = $1 AND id = $2`, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Deleted collection.", "id", id)
+
+	return nil
+}
+
+// Get returns one of the user's collections.
+func (model *CollectionModel) Get(ctx context.Context, userID string, id uuid.UUID) (Collection, error) {
+	return model.getOne(ctx, collectionSelect+` WHERE col.owner = $1 AND col.id = $2`, userID, id)
+}
+
+// GetShared returns the collection with the provided share token.
+func (model *CollectionModel) GetShared(ctx context.Context, token string) (Collection, error) {
+	return model.getOne(ctx, collectionSelect+` WHERE col.share_token = $1`, token)
+}
+
+func (model *CollectionModel) getOne(ctx context.Context, query string, args ...any) (Collection, error) {
+	rows, err := model.DB.Query(ctx, query, args...)
+	if err != nil {
+		return Collection{}, fmt.Errorf("failed to query for collection: %w", err)
+	}
+
+	collection, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[Collection])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Collection{}, ErrNotFound
+		}
+
+		return Collection{}, fmt.Errorf("failed to map collection row to struct: %w", err)
+	}
+
+	return collection, nil
+}
+
+// List returns the user's collections ordered by name.
+func (model *CollectionModel) List(ctx context.Context, userID string) ([]Collection, error) {
+	return model.list(ctx, collectionSelect+` WHERE col.owner = $1 ORDER BY col.name`, userID)
+}
+
+// ListForRecipe returns the user's collections that contain the recipe, ordered by name.
+func (model *CollectionModel) ListForRecipe(ctx context.Context, userID string, recipeID uuid.UUID) ([]Collection, error) {
+	query := collectionSelect + `
+		WHERE col.owner = $1
+			AND EXISTS (SELECT 1 FROM collection_recipes AS cr WHERE cr.collection = col.id AND cr.recipe = $2)
+		ORDER BY col.name`
+
+	return model.list(ctx, query, userID, recipeID)
+}
+
+func (model *CollectionModel) list(ctx context.Context, query string, args ...any) ([]Collection, error) {
+	rows, err := model.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	defer rows.Close()
+
+	collections, err := pgx.CollectRows(rows, pgx.RowToStructByName[Collection])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map collection rows to struct: %w", err)
+	}
+
+	return collections, nil
+}
+
+// Recipes returns the recipes in one of the user's collections, in order. Recipes the user is no
+// longer allowed to view are omitted.
+func (model *CollectionModel) Recipes(ctx context.Context, userID string, id uuid.UUID) ([]Recipe, error) {
+	query := recipeSelect + `
+			JOIN collection_recipes AS cr
+				ON cr.recipe = r.id
+			JOIN collections AS col
+				ON cr.collection = col.id
+		WHERE col.id = $2 AND col.owner = $1 AND ` + visibleTo("r") + `
+		ORDER BY cr.position, cr.created_at`
+	rows, err := model.DB.Query(ctx, query, userID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection recipes: %w", err)
+	}
+	defer rows.Close()
+
+	recipes, err := pgx.CollectRows(rows, pgx.RowToStructByName[Recipe])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map recipe rows to struct: %w", err)
+	}
+
+	return recipes, nil
+}
+
+// RemoveRecipe removes a recipe from one of the user's collections.
+func (model *CollectionModel) RemoveRecipe(ctx context.Context, userID string, id uuid.UUID, recipeID uuid.UUID) error {
+	query := `DELETE FROM collection_recipes AS cr
+		USING collections AS col
+		WHERE cr.collection = col.id AND col.owner = $1 AND col.id = $2 AND cr.recipe = $3`
+	result, err := model.DB.Exec(ctx, query, userID, id, recipeID)
+	if err != nil {
+		return fmt.Errorf("failed to remove recipe from collection: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Removed recipe from collection.", "id", id, "recipe", recipeID)
+
+	return nil
+}
+
+// Reorder arranges the recipes of one of the user's collections in the order of the provided
+// recipe IDs. Recipes in the collection that are not listed keep their relative order after the
+// listed recipes, and IDs of recipes that are not in the collection are ignored.
+func (model *CollectionModel) Reorder(ctx context.Context, userID string, id uuid.UUID, recipeIDs []uuid.UUID) error {
+	err := pgx.BeginFunc(ctx, model.DB, func(tx pgx.Tx) error {
+		// Lock the collection so that concurrent reorders are applied one after the other.
+		query := `SELECT id FROM collections WHERE owner = $1 AND id = $2 FOR UPDATE`
+		if err := tx.QueryRow(ctx, query, userID, id).Scan(&id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+
+			return err
+		}
+
+		query = `UPDATE collection_recipes AS cr
+			SET position = ranked.position
+			FROM (
+				SELECT c.recipe, row_number() OVER (ORDER BY o.position NULLS LAST, c.position, c.created_at) - 1 AS position
+				FROM collection_recipes AS c
+					LEFT JOIN unnest($2::uuid[]) WITH ORDINALITY AS o (recipe, position)
+						ON o.recipe = c.recipe
+				WHERE c.collection = $1
+			) AS ranked
+			WHERE cr.collection = $1 AND cr.recipe = ranked.recipe`
+		_, err := tx.Exec(ctx, query, id, recipeIDs)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return err
+		}
+
+		return fmt.Errorf("failed to reorder collection: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Reordered collection.", "id", id)
+
+	return nil
+}
+
+// Share allows anyone with the provided token to view one of the user's collections, replacing
+// any previous token.
+func (model *CollectionModel) Share(ctx context.Context, userID string, id uuid.UUID, token string) error {
+	return model.setShareToken(ctx, userID, id, pgtype.Text{String: token, Valid: true})
+}
+
+// Unshare stops sharing one of the user's collections. Previously shared links stop working.
+func (model *CollectionModel) Unshare(ctx context.Context, userID string, id uuid.UUID) error {
+	return model.setShareToken(ctx, userID, id, pgtype.Text{})
+}
+
+func (model *CollectionModel) setShareToken(ctx context.Context, userID string, id uuid.UUID, token pgtype.Text) error {
+	query := `UPDATE collections SET share_token = $3 WHERE owner = $1 AND id = $2`
+	result, err := model.DB.Exec(ctx, query, userID, id, token)
+	if err != nil {
+		return fmt.Errorf("failed to update collection share token: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Updated collection sharing.", "id", id, "shared", token.Valid)
+
+	return nil
+}
+
+// Update modifies the name and description of one of the user's collections.
+func (model *CollectionModel) Update(ctx context.Context, userID string, collection Collection) error {
+	query := `UPDATE collections SET name = $3, description = $4 WHERE owner = $1 AND id = $2`
+	result, err := model.DB.Exec(ctx, query, userID, collection.ID, collection.Name, collection.Description)
+	if err != nil {
+		return fmt.Errorf("failed to update collection: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
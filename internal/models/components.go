@@ -0,0 +1,177 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cdriehuys/recipes/internal/ingredients"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// RecipeComponent identifies a recipe that is used by another recipe, such as a sauce or dough.
+type RecipeComponent struct {
+	ID    uuid.UUID `db:"id"`
+	Title string    `db:"title"`
+}
+
+// URL returns the URL of the component recipe's detail view.
+func (c RecipeComponent) URL() string {
+	return "/recipes/" + c.ID.String()
+}
+
+// ComponentKey normalizes a recipe title or ingredient name so that references to a recipe match
+// regardless of capitalization or spacing.
+func ComponentKey(title string) string {
+	return strings.Join(strings.Fields(strings.ToLower(title)), " ")
+}
+
+// IsComponentReference reports if an ingredient may refer to another recipe by its title. Only
+// ingredients measured in batches, as in "1 batch Pizza Dough", or bare counts, as in "Pizza
+// Dough", may refer to a recipe, since the amount a recipe makes in other units is unknown.
+func IsComponentReference(ingredient ingredients.Ingredient) bool {
+	return ingredient.Name != "" && (ingredient.Unit.Name == "" || ingredient.Unit.Name == "batch")
+}
+
+// ComponentTitles returns the normalized names of the recipe's ingredients that may refer to other
+// recipes.
+func (r Recipe) ComponentTitles() []string {
+	var titles []string
+	for _, ingredient := range r.IngredientList() {
+		if key := ComponentKey(ingredient.Name); IsComponentReference(ingredient) && !slices.Contains(titles, key) {
+			titles = append(titles, key)
+		}
+	}
+
+	return titles
+}
+
+// normalizedTitle is the SQL equivalent of ComponentKey for the title of the recipe aliased as "r".
+const normalizedTitle = `lower(regexp_replace(trim(r.title), '\s+', ' ', 'g'))`
+
+// FindByTitles returns the recipes visible to the user whose titles match any of the provided
+// normalized titles. If several recipes share a title, the oldest is returned. Matches are only
+// suggestions for linking ingredients to recipes, which is done with SetComponents.
+func (model *RecipeModel) FindByTitles(ctx context.Context, userID string, titles []string) ([]RecipeComponent, error) {
+	if len(titles) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT DISTINCT ON (` + normalizedTitle + `) r.id AS id, r.title AS title
+		FROM recipes AS r
+		WHERE ` + visibleTo("r") + ` AND ` + normalizedTitle + ` = ANY($2)
+		ORDER BY ` + normalizedTitle + `, r.created_at`
+
+	rows, err := model.DB.Query(ctx, query, userID, titles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recipes by title: %w", err)
+	}
+	defer rows.Close()
+
+	components, err := pgx.CollectRows(rows, pgx.RowToStructByName[RecipeComponent])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map recipe component rows to struct: %w", err)
+	}
+
+	return components, nil
+}
+
+// Components returns the recipes linked to the ingredients of a recipe the user can view, keyed by
+// the normalized name of the ingredient. Linked recipes the user can't view are omitted.
+func (model *RecipeModel) Components(ctx context.Context, userID string, recipeID uuid.UUID) (map[string]RecipeComponent, error) {
+	query := `SELECT rc.ingredient, r.id, r.title
+		FROM recipe_components AS rc
+			JOIN recipes AS p ON p.id = rc.recipe
+			JOIN recipes AS r ON r.id = rc.component
+		WHERE rc.recipe = $2 AND ` + visibleTo("p") + ` AND ` + visibleTo("r")
+	rows, err := model.DB.Query(ctx, query, userID, recipeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query for recipe components: %w", err)
+	}
+
+	components := make(map[string]RecipeComponent)
+	var ingredient string
+	var component RecipeComponent
+	_, err = pgx.ForEachRow(rows, []any{&ingredient, &component.ID, &component.Title}, func() error {
+		components[ingredient] = component
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recipe components: %w", err)
+	}
+
+	return components, nil
+}
+
+// SetComponents replaces the links between the ingredients of a recipe the user can edit and the
+// recipes they are made from. The links are keyed by the normalized name of the ingredient, and
+// links to recipes the user can't view are ignored. ErrNotFound is returned if the recipe does not
+// exist or the user may not edit it.
+func (model *RecipeModel) SetComponents(ctx context.Context, userID string, recipeID uuid.UUID, components map[string]uuid.UUID) error {
+	err := pgx.BeginFunc(ctx, model.DB, func(tx pgx.Tx) error {
+		var found bool
+		query := `SELECT EXISTS (SELECT 1 FROM recipes AS r WHERE r.id = $2 AND ` + editableBy("r") + `)`
+		if err := tx.QueryRow(ctx, query, userID, recipeID).Scan(&found); err != nil {
+			return err
+		}
+
+		if !found {
+			return ErrNotFound
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM recipe_components WHERE recipe = $1`, recipeID); err != nil {
+			return err
+		}
+
+		batch := &pgx.Batch{}
+		for ingredient, component := range components {
+			batch.Queue(
+				`INSERT INTO recipe_components (recipe, ingredient, component)
+					SELECT $2, $3, r.id FROM recipes AS r WHERE r.id = $4 AND r.id <> $2 AND `+visibleTo("r"),
+				userID,
+				recipeID,
+				ingredient,
+				component,
+			)
+		}
+
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return err
+		}
+
+		return fmt.Errorf("failed to set recipe components: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Updated recipe components.", "recipe", recipeID, "count", len(components))
+
+	return nil
+}
+
+// ReferencedBy returns the recipes visible to the user that use the provided recipe as an
+// ingredient.
+func (model *RecipeModel) ReferencedBy(ctx context.Context, userID string, recipe Recipe) ([]RecipeComponent, error) {
+	query := `SELECT DISTINCT r.id AS id, r.title AS title
+		FROM recipe_components AS rc
+			JOIN recipes AS r ON r.id = rc.recipe
+		WHERE rc.component = $2 AND ` + visibleTo("r") + `
+		ORDER BY r.title, r.id`
+
+	rows, err := model.DB.Query(ctx, query, userID, recipe.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query for recipes referencing %s: %w", recipe.ID, err)
+	}
+	defer rows.Close()
+
+	parents, err := pgx.CollectRows(rows, pgx.RowToStructByName[RecipeComponent])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map recipe component rows to struct: %w", err)
+	}
+
+	return parents, nil
+}
@@ -0,0 +1,17 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models"
+)
+
+func TestRecipe_ComponentTitles(t *testing.T) {
+	recipe := models.Recipe{
+		Ingredients: "1 batch Pizza  Dough\n2 cups Marinara Sauce\nPesto\n1/2 batch pizza dough\n2 eggs",
+	}
+
+	assert.Equal(t, "pizza dough|pesto|eggs", strings.Join(recipe.ComponentTitles(), "|"))
+}
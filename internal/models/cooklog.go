@@ -0,0 +1,98 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CookLogEntry records a single occasion on which a recipe was cooked.
+type CookLogEntry struct {
+	ID        uuid.UUID   `db:"id"`
+	Recipe    uuid.UUID   `db:"recipe"`
+	Owner     string      `db:"owner"`
+	OwnerName string      `db:"owner_name"`
+	CookedOn  time.Time   `db:"cooked_on"`
+	Notes     string      `db:"notes"`
+	Rating    pgtype.Int2 `db:"rating"`
+	CreatedAt time.Time   `db:"created_at"`
+}
+
+type CookLogModel struct {
+	DB     *pgxpool.Pool
+	Logger *slog.Logger
+}
+
+// Add records a new entry for a recipe the entry's owner is allowed to view.
+func (model *CookLogModel) Add(ctx context.Context, entry CookLogEntry) error {
+	query := `INSERT INTO cook_log_entries (id, recipe, owner, cooked_on, notes, rating)
+		SELECT $2::uuid, r.id, $1::text, $4::date, $5::text, $6::smallint
+		FROM recipes AS r
+		WHERE r.id = $3 AND ` + visibleTo("r")
+	result, err := model.DB.Exec(
+		ctx,
+		query,
+		entry.Owner,
+		entry.ID,
+		entry.Recipe,
+		entry.CookedOn,
+		entry.Notes,
+		entry.Rating,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert cook log entry: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Added cook log entry.", "id", entry.ID, "recipe", entry.Recipe)
+
+	return nil
+}
+
+// Delete removes one of the user's own entries.
+func (model *CookLogModel) Delete(ctx context.Context, userID string, id uuid.UUID) error {
+	query := `DELETE FROM cook_log_entries WHERE owner = $1 AND id = $2`
+	result, err := model.DB.Exec(ctx, query, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete cook log entry: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Deleted cook log entry.", "id", id)
+
+	return nil
+}
+
+// ListForRecipe returns every entry for a recipe the user is allowed to view, most recent first.
+func (model *CookLogModel) ListForRecipe(ctx context.Context, userID string, recipeID uuid.UUID) ([]CookLogEntry, error) {
+	query := `SELECT e.id, e.recipe, e.owner, u.name AS owner_name, e.cooked_on, e.notes, e.rating, e.created_at
+		FROM cook_log_entries AS e
+			JOIN recipes AS r ON e.recipe = r.id
+			JOIN "users" AS u ON e.owner = u.id
+		WHERE r.id = $2 AND ` + visibleTo("r") + `
+		ORDER BY e.cooked_on DESC, e.created_at DESC`
+	rows, err := model.DB.Query(ctx, query, userID, recipeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cook log entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[CookLogEntry])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map cook log rows to struct: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RecipeDraft holds the unsaved contents of a recipe form so that they can be resumed later.
+type RecipeDraft struct {
+	ID    uuid.UUID `db:"id"`
+	Owner string    `db:"owner"`
+	// Recipe is the recipe being edited, or nil if the draft is of a new recipe.
+	Recipe *uuid.UUID `db:"recipe"`
+	// Values are the values of the form's fields, keyed by field name.
+	Values    map[string]string `db:"form_values"`
+	CreatedAt time.Time         `db:"created_at"`
+	UpdatedAt time.Time         `db:"updated_at"`
+
+	RecipeTitle pgtype.Text `db:"recipe_title"`
+}
+
+// Title returns the title entered in the draft, or a placeholder if no title has been entered yet.
+func (d RecipeDraft) Title() string {
+	if title := d.Values["title"]; title != "" {
+		return title
+	}
+
+	return "Untitled recipe"
+}
+
+// URL returns the URL of the form to resume the draft in.
+func (d RecipeDraft) URL() string {
+	if d.Recipe == nil {
+		return "/new-recipe?draft=" + d.ID.String()
+	}
+
+	return "/recipes/" + d.Recipe.String() + "/edit?draft=" + d.ID.String()
+}
+
+// draftSelect selects all the columns required to populate a RecipeDraft from the recipe_drafts
+// table aliased as "d".
+const draftSelect = `SELECT d.id, d.owner, d.recipe, d.form_values, d.created_at, d.updated_at,
+			r.title AS recipe_title
+		FROM recipe_drafts AS d
+			LEFT JOIN recipes AS r
+				ON d.recipe = r.id`
+
+type DraftModel struct {
+	DB     *pgxpool.Pool
+	Logger *slog.Logger
+}
+
+// Delete discards one of the user's drafts.
+func (model *DraftModel) Delete(ctx context.Context, userID string, id uuid.UUID) error {
+	result, err := model.DB.Exec(ctx, `DELETE FROM recipe_drafts WHERE owner = $1 AND id = $2`, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete recipe draft: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Deleted recipe draft.", "id", id)
+
+	return nil
+}
+
+// Get returns one of the user's drafts.
+func (model *DraftModel) Get(ctx context.Context, userID string, id uuid.UUID) (RecipeDraft, error) {
+	rows, err := model.DB.Query(ctx, draftSelect+` WHERE d.owner = $1 AND d.id = $2`, userID, id)
+	if err != nil {
+		return RecipeDraft{}, fmt.Errorf("failed to query for recipe draft: %w", err)
+	}
+
+	draft, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[RecipeDraft])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return RecipeDraft{}, ErrNotFound
+		}
+
+		return RecipeDraft{}, fmt.Errorf("failed to map recipe draft row to struct: %w", err)
+	}
+
+	return draft, nil
+}
+
+// List returns the user's drafts, most recently saved first.
+func (model *DraftModel) List(ctx context.Context, userID string) ([]RecipeDraft, error) {
+	rows, err := model.DB.Query(ctx, draftSelect+` WHERE d.owner = $1 ORDER BY d.updated_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recipe drafts: %w", err)
+	}
+	defer rows.Close()
+
+	drafts, err := pgx.CollectRows(rows, pgx.RowToStructByName[RecipeDraft])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map recipe draft rows to struct: %w", err)
+	}
+
+	return drafts, nil
+}
+
+// Save creates or replaces a draft. Drafts of existing recipes may only be saved by users allowed to
+// edit the recipe, and a draft may only be replaced by its owner. Otherwise ErrNotFound is
+// returned.
+func (model *DraftModel) Save(ctx context.Context, draft RecipeDraft) error {
+	query := `INSERT INTO recipe_drafts (id, owner, recipe, form_values)
+		SELECT $2::uuid, $1::text, $3::uuid, $4::jsonb
+		WHERE $3::uuid IS NULL OR EXISTS (SELECT 1 FROM recipes AS r WHERE r.id = $3 AND ` + editableBy("r") + `)
+		ON CONFLICT (id) DO UPDATE SET form_values = EXCLUDED.form_values
+			WHERE recipe_drafts.owner = EXCLUDED.owner AND recipe_drafts.recipe IS NOT DISTINCT FROM EXCLUDED.recipe`
+	result, err := model.DB.Exec(ctx, query, draft.Owner, draft.ID, draft.Recipe, draft.Values)
+	if err != nil {
+		return fmt.Errorf("failed to save recipe draft: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.DebugContext(ctx, "Saved recipe draft.", "id", draft.ID)
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Duration is a possibly null length of time stored in an interval column.
+type Duration struct {
+	Duration time.Duration
+	Valid    bool
+}
+
+// DurationFromMinutes returns a valid duration of the provided number of minutes.
+func DurationFromMinutes(minutes int) Duration {
+	return Duration{Duration: time.Duration(minutes) * time.Minute, Valid: true}
+}
+
+// Minutes returns the duration rounded to whole minutes.
+func (d Duration) Minutes() int {
+	return int(d.Duration.Round(time.Minute) / time.Minute)
+}
+
+// String formats the duration for display, such as "1 hr 15 min". Null durations are blank.
+func (d Duration) String() string {
+	if !d.Valid {
+		return ""
+	}
+
+	hours, minutes := d.Minutes()/60, d.Minutes()%60
+
+	parts := make([]string, 0, 2)
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%d hr", hours))
+	}
+
+	if minutes > 0 || hours == 0 {
+		parts = append(parts, fmt.Sprintf("%d min", minutes))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// ISO8601 formats the duration as used by schema.org metadata, such as "PT1H15M". Null durations
+// are blank.
+func (d Duration) ISO8601() string {
+	if !d.Valid {
+		return ""
+	}
+
+	hours, minutes := d.Minutes()/60, d.Minutes()%60
+
+	s := "PT"
+	if hours > 0 {
+		s += fmt.Sprintf("%dH", hours)
+	}
+
+	if minutes > 0 || hours == 0 {
+		s += fmt.Sprintf("%dM", minutes)
+	}
+
+	return s
+}
+
+// Add returns the sum of two durations, which is null only if both durations are null.
+func (d Duration) Add(other Duration) Duration {
+	return Duration{Duration: d.Duration + other.Duration, Valid: d.Valid || other.Valid}
+}
+
+// ScanInterval implements pgtype.IntervalScanner. Months are treated as 30 days.
+func (d *Duration) ScanInterval(v pgtype.Interval) error {
+	if !v.Valid {
+		*d = Duration{}
+		return nil
+	}
+
+	days := int64(v.Days) + 30*int64(v.Months)
+	*d = Duration{
+		Duration: time.Duration(v.Microseconds)*time.Microsecond + time.Duration(days)*24*time.Hour,
+		Valid:    true,
+	}
+
+	return nil
+}
+
+// IntervalValue implements pgtype.IntervalValuer.
+func (d Duration) IntervalValue() (pgtype.Interval, error) {
+	return pgtype.Interval{Microseconds: d.Duration.Microseconds(), Valid: d.Valid}, nil
+}
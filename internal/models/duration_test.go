@@ -0,0 +1,87 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestDuration_String(t *testing.T) {
+	testCases := []struct {
+		name     string
+		duration models.Duration
+		want     string
+		wantISO  string
+	}{
+		{name: "null", duration: models.Duration{}, want: "", wantISO: ""},
+		{name: "zero", duration: models.DurationFromMinutes(0), want: "0 min", wantISO: "PT0M"},
+		{name: "minutes", duration: models.DurationFromMinutes(45), want: "45 min", wantISO: "PT45M"},
+		{name: "whole hours", duration: models.DurationFromMinutes(120), want: "2 hr", wantISO: "PT2H"},
+		{name: "hours and minutes", duration: models.DurationFromMinutes(75), want: "1 hr 15 min", wantISO: "PT1H15M"},
+		{
+			name:     "rounds seconds",
+			duration: models.Duration{Duration: 90 * time.Second, Valid: true},
+			want:     "2 min",
+			wantISO:  "PT2M",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.duration.String())
+			assert.Equal(t, tt.wantISO, tt.duration.ISO8601())
+		})
+	}
+}
+
+func TestDuration_Add(t *testing.T) {
+	assert.Equal(t, models.Duration{}, models.Duration{}.Add(models.Duration{}))
+	assert.Equal(t, models.DurationFromMinutes(10), models.Duration{}.Add(models.DurationFromMinutes(10)))
+	assert.Equal(t, models.DurationFromMinutes(25), models.DurationFromMinutes(10).Add(models.DurationFromMinutes(15)))
+}
+
+func TestDuration_ScanInterval(t *testing.T) {
+	testCases := []struct {
+		name     string
+		interval pgtype.Interval
+		want     models.Duration
+	}{
+		{name: "null", interval: pgtype.Interval{}, want: models.Duration{}},
+		{
+			name:     "microseconds",
+			interval: pgtype.Interval{Microseconds: int64(90 * time.Minute / time.Microsecond), Valid: true},
+			want:     models.DurationFromMinutes(90),
+		},
+		{
+			name:     "days",
+			interval: pgtype.Interval{Days: 1, Microseconds: int64(time.Hour / time.Microsecond), Valid: true},
+			want:     models.DurationFromMinutes(25 * 60),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var got models.Duration
+			if err := got.ScanInterval(tt.interval); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, got)
+
+			value, err := got.IntervalValue()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var roundTrip models.Duration
+			if err := roundTrip.ScanInterval(value); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, got, roundTrip)
+		})
+	}
+}
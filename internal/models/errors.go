@@ -4,3 +4,13 @@ import "errors"
 
 // ErrNotFound indicates the object targeted by the query could not be found.
 var ErrNotFound = errors.New("object not found")
+
+// ErrLastAdmin indicates that an operation would leave a household without any admins.
+var ErrLastAdmin = errors.New("household must have at least one admin")
+
+// ErrConflict indicates that an object was modified by someone else since it was retrieved.
+var ErrConflict = errors.New("object was modified concurrently")
+
+// ErrDuplicate indicates that an object could not be saved because another object already has the
+// same unique values, such as a name.
+var ErrDuplicate = errors.New("object already exists")
@@ -0,0 +1,378 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Household is a group of users sharing a collection of recipes and categories.
+type Household struct {
+	ID        uuid.UUID `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+
+	// Role is the role of the user the household was retrieved for.
+	Role HouseholdRole `db:"role"`
+}
+
+// URL returns the URL of the household's detail view.
+func (h Household) URL() string {
+	return "/households/" + h.ID.String()
+}
+
+type HouseholdMember struct {
+	Household uuid.UUID     `db:"household"`
+	Member    string        `db:"member"`
+	Name      string        `db:"name"`
+	Role      HouseholdRole `db:"role"`
+	CreatedAt time.Time     `db:"created_at"`
+}
+
+// HouseholdInvitation allows whoever holds its token to join a household with a particular role.
+type HouseholdInvitation struct {
+	ID            uuid.UUID     `db:"id"`
+	Household     uuid.UUID     `db:"household"`
+	HouseholdName string        `db:"household_name"`
+	Token         string        `db:"token"`
+	Role          HouseholdRole `db:"role"`
+	CreatedBy     string        `db:"created_by"`
+	CreatedAt     time.Time     `db:"created_at"`
+	ExpiresAt     time.Time     `db:"expires_at"`
+}
+
+// URL returns the URL used to accept the invitation.
+func (i HouseholdInvitation) URL() string {
+	return "/invitations/" + i.Token
+}
+
+type HouseholdModel struct {
+	DB     *pgxpool.Pool
+	Logger *slog.Logger
+}
+
+// Create persists a new household with the creator as its only admin.
+func (model *HouseholdModel) Create(ctx context.Context, creator string, household Household) error {
+	err := pgx.BeginFunc(ctx, model.DB, func(tx pgx.Tx) error {
+		query := `INSERT INTO households (id, name) VALUES ($1, $2)`
+		if _, err := tx.Exec(ctx, query, household.ID, household.Name); err != nil {
+			return err
+		}
+
+		query = `INSERT INTO household_members (household, member, "role") VALUES ($1, $2, $3)`
+		_, err := tx.Exec(ctx, query, household.ID, creator, RoleAdmin)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create household: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Created household.", "id", household.ID)
+
+	return nil
+}
+
+// Get returns a household the user is a member of.
+func (model *HouseholdModel) Get(ctx context.Context, userID string, id uuid.UUID) (Household, error) {
+	query := `SELECT h.id, h.name, h.created_at, h.updated_at, m."role"
+		FROM households AS h
+			JOIN household_members AS m ON h.id = m.household
+		WHERE m.member = $1 AND h.id = $2`
+	rows, err := model.DB.Query(ctx, query, userID, id)
+	if err != nil {
+		return Household{}, fmt.Errorf("failed to query for household: %w", err)
+	}
+
+	household, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[Household])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Household{}, ErrNotFound
+		}
+
+		return Household{}, fmt.Errorf("failed to query for household: %w", err)
+	}
+
+	return household, nil
+}
+
+// ListForUser returns the households the user is a member of.
+func (model *HouseholdModel) ListForUser(ctx context.Context, userID string) ([]Household, error) {
+	query := `SELECT h.id, h.name, h.created_at, h.updated_at, m."role"
+		FROM households AS h
+			JOIN household_members AS m ON h.id = m.household
+		WHERE m.member = $1
+		ORDER BY h.name`
+	rows, err := model.DB.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list households: %w", err)
+	}
+	defer rows.Close()
+
+	households, err := pgx.CollectRows(rows, pgx.RowToStructByName[Household])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map household rows to struct: %w", err)
+	}
+
+	return households, nil
+}
+
+// ListMembers returns the members of a household the user belongs to.
+func (model *HouseholdModel) ListMembers(ctx context.Context, userID string, id uuid.UUID) ([]HouseholdMember, error) {
+	query := `SELECT m.household, m.member, u.name, m."role", m.created_at
+		FROM household_members AS m
+			JOIN "users" AS u ON m.member = u.id
+		WHERE m.household = $2
+			AND EXISTS (SELECT 1 FROM household_members WHERE household = $2 AND member = $1)
+		ORDER BY u.name`
+	rows, err := model.DB.Query(ctx, query, userID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list household members: %w", err)
+	}
+	defer rows.Close()
+
+	members, err := pgx.CollectRows(rows, pgx.RowToStructByName[HouseholdMember])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map household member rows to struct: %w", err)
+	}
+
+	return members, nil
+}
+
+// SetMemberRole changes the role of a household member. The acting user must be an admin of the
+// household, and the household must still have an admin after the change.
+func (model *HouseholdModel) SetMemberRole(
+	ctx context.Context,
+	actor string,
+	household uuid.UUID,
+	member string,
+	role HouseholdRole,
+) error {
+	err := pgx.BeginFunc(ctx, model.DB, func(tx pgx.Tx) error {
+		if err := lockHousehold(ctx, tx, household); err != nil {
+			return err
+		}
+
+		if err := requireHouseholdAdmin(ctx, tx, actor, household); err != nil {
+			return err
+		}
+
+		query := `UPDATE household_members SET "role" = $3 WHERE household = $1 AND member = $2`
+		result, err := tx.Exec(ctx, query, household, member, role)
+		if err != nil {
+			return err
+		}
+
+		if result.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+
+		return requireRemainingAdmin(ctx, tx, household)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update household member role: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Updated household member role.", "household", household, "member", member, "role", role)
+
+	return nil
+}
+
+// RemoveMember removes a member from a household. Admins may remove any member, and other members
+// may only remove themselves. The household must still have an admin after the removal.
+func (model *HouseholdModel) RemoveMember(ctx context.Context, actor string, household uuid.UUID, member string) error {
+	err := pgx.BeginFunc(ctx, model.DB, func(tx pgx.Tx) error {
+		if err := lockHousehold(ctx, tx, household); err != nil {
+			return err
+		}
+
+		if actor != member {
+			if err := requireHouseholdAdmin(ctx, tx, actor, household); err != nil {
+				return err
+			}
+		}
+
+		query := `DELETE FROM household_members WHERE household = $1 AND member = $2`
+		result, err := tx.Exec(ctx, query, household, member)
+		if err != nil {
+			return err
+		}
+
+		if result.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+
+		return requireRemainingAdmin(ctx, tx, household)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove household member: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Removed household member.", "household", household, "member", member)
+
+	return nil
+}
+
+// CreateInvitation persists a new invitation to a household the acting user is an admin of.
+func (model *HouseholdModel) CreateInvitation(ctx context.Context, actor string, invitation HouseholdInvitation) error {
+	query := `INSERT INTO household_invitations (id, household, token, "role", created_by, expires_at)
+		SELECT $2::uuid, m.household, $4::text, $5::text, m.member, $6::timestamptz
+		FROM household_members AS m
+		WHERE m.member = $1 AND m.household = $3 AND m."role" = 'admin'`
+	result, err := model.DB.Exec(
+		ctx,
+		query,
+		actor,
+		invitation.ID,
+		invitation.Household,
+		invitation.Token,
+		invitation.Role,
+		invitation.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert household invitation: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Created household invitation.", "id", invitation.ID, "household", invitation.Household)
+
+	return nil
+}
+
+// ListInvitations returns the pending invitations for a household the acting user is an admin of.
+func (model *HouseholdModel) ListInvitations(ctx context.Context, actor string, household uuid.UUID) ([]HouseholdInvitation, error) {
+	query := `SELECT i.id, i.household, h.name AS household_name, i.token, i."role", i.created_by, i.created_at, i.expires_at
+		FROM household_invitations AS i
+			JOIN households AS h ON i.household = h.id
+			JOIN household_members AS m ON i.household = m.household
+		WHERE m.member = $1
+			AND m."role" = 'admin'
+			AND i.household = $2
+			AND i.accepted_at IS NULL
+			AND i.expires_at > now()
+		ORDER BY i.created_at`
+	rows, err := model.DB.Query(ctx, query, actor, household)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list household invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations, err := pgx.CollectRows(rows, pgx.RowToStructByName[HouseholdInvitation])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map household invitation rows to struct: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// GetInvitation returns the pending invitation with the provided token.
+func (model *HouseholdModel) GetInvitation(ctx context.Context, token string) (HouseholdInvitation, error) {
+	query := `SELECT i.id, i.household, h.name AS household_name, i.token, i."role", i.created_by, i.created_at, i.expires_at
+		FROM household_invitations AS i
+			JOIN households AS h ON i.household = h.id
+		WHERE i.token = $1 AND i.accepted_at IS NULL AND i.expires_at > now()`
+	rows, err := model.DB.Query(ctx, query, token)
+	if err != nil {
+		return HouseholdInvitation{}, fmt.Errorf("failed to query for household invitation: %w", err)
+	}
+
+	invitation, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[HouseholdInvitation])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return HouseholdInvitation{}, ErrNotFound
+		}
+
+		return HouseholdInvitation{}, fmt.Errorf("failed to query for household invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+// AcceptInvitation adds the user to the household referenced by a pending invitation and returns
+// the household's ID. Users who are already members of the household keep their existing role.
+func (model *HouseholdModel) AcceptInvitation(ctx context.Context, userID string, token string) (uuid.UUID, error) {
+	var household uuid.UUID
+	err := pgx.BeginFunc(ctx, model.DB, func(tx pgx.Tx) error {
+		query := `UPDATE household_invitations
+			SET accepted_by = $1, accepted_at = now()
+			WHERE token = $2 AND accepted_at IS NULL AND expires_at > now()
+			RETURNING household, "role"`
+
+		var role HouseholdRole
+		if err := tx.QueryRow(ctx, query, userID, token).Scan(&household, &role); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+
+			return err
+		}
+
+		query = `INSERT INTO household_members (household, member, "role") VALUES ($1, $2, $3)
+			ON CONFLICT (household, member) DO NOTHING`
+		_, err := tx.Exec(ctx, query, household, userID, role)
+
+		return err
+	})
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("failed to accept household invitation: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Accepted household invitation.", "household", household, "member", userID)
+
+	return household, nil
+}
+
+// lockHousehold locks the household's row for the duration of the transaction so that concurrent
+// membership changes cannot remove every admin.
+func lockHousehold(ctx context.Context, tx pgx.Tx, household uuid.UUID) error {
+	query := `SELECT id FROM households WHERE id = $1 FOR UPDATE`
+	if _, err := tx.Exec(ctx, query, household); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// requireHouseholdAdmin returns ErrNotFound unless the user is an admin of the household.
+func requireHouseholdAdmin(ctx context.Context, tx pgx.Tx, userID string, household uuid.UUID) error {
+	query := `SELECT EXISTS(
+		SELECT 1 FROM household_members WHERE household = $1 AND member = $2 AND "role" = 'admin'
+	)`
+
+	var isAdmin bool
+	if err := tx.QueryRow(ctx, query, household, userID).Scan(&isAdmin); err != nil {
+		return err
+	}
+
+	if !isAdmin {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// requireRemainingAdmin returns ErrLastAdmin if the household has no admins.
+func requireRemainingAdmin(ctx context.Context, tx pgx.Tx, household uuid.UUID) error {
+	query := `SELECT EXISTS(SELECT 1 FROM household_members WHERE household = $1 AND "role" = 'admin')`
+
+	var hasAdmin bool
+	if err := tx.QueryRow(ctx, query, household).Scan(&hasAdmin); err != nil {
+		return err
+	}
+
+	if !hasAdmin {
+		return ErrLastAdmin
+	}
+
+	return nil
+}
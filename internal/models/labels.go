@@ -0,0 +1,57 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/cdriehuys/recipes/internal/dietary"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LabelModel stores manual corrections to the allergen and diet labels detected for recipes.
+type LabelModel struct {
+	DB     *pgxpool.Pool
+	Logger *slog.Logger
+}
+
+// SetOverrides replaces the label overrides of a recipe the user can edit. ErrNotFound is returned
+// if the recipe does not exist or the user may not edit it.
+func (model *LabelModel) SetOverrides(ctx context.Context, userID string, recipeID uuid.UUID, overrides dietary.Overrides) error {
+	err := pgx.BeginFunc(ctx, model.DB, func(tx pgx.Tx) error {
+		var found bool
+		query := `SELECT EXISTS (SELECT 1 FROM recipes AS r WHERE r.id = $2 AND ` + editableBy("r") + `)`
+		if err := tx.QueryRow(ctx, query, userID, recipeID).Scan(&found); err != nil {
+			return err
+		}
+
+		if !found {
+			return ErrNotFound
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM recipe_label_overrides WHERE recipe = $1`, recipeID); err != nil {
+			return err
+		}
+
+		batch := &pgx.Batch{}
+		for label, present := range overrides {
+			batch.Queue(
+				`INSERT INTO recipe_label_overrides (recipe, label, present) VALUES ($1, $2, $3)`,
+				recipeID,
+				label,
+				present,
+			)
+		}
+
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set recipe label overrides: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Updated recipe label overrides.", "recipe", recipeID, "count", len(overrides))
+
+	return nil
+}
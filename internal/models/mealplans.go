@@ -0,0 +1,175 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MealSlot is the meal of the day a planned item is eaten at.
+type MealSlot string
+
+const (
+	SlotBreakfast MealSlot = "breakfast"
+	SlotLunch     MealSlot = "lunch"
+	SlotDinner    MealSlot = "dinner"
+	SlotSnack     MealSlot = "snack"
+)
+
+// MealSlots lists every slot in the order they occur during the day.
+var MealSlots = []MealSlot{SlotBreakfast, SlotLunch, SlotDinner, SlotSnack}
+
+// Label returns the human readable name of the slot.
+func (s MealSlot) Label() string {
+	switch s {
+	case SlotBreakfast:
+		return "Breakfast"
+	case SlotLunch:
+		return "Lunch"
+	case SlotDinner:
+		return "Dinner"
+	case SlotSnack:
+		return "Snack"
+	default:
+		return string(s)
+	}
+}
+
+// MealPlanItem is a recipe or free-text item planned for a meal on a specific day.
+type MealPlanItem struct {
+	ID        uuid.UUID  `db:"id"`
+	Owner     string     `db:"owner"`
+	PlannedOn time.Time  `db:"planned_on"`
+	Slot      MealSlot   `db:"slot"`
+	Recipe    *uuid.UUID `db:"recipe"`
+	Note      string     `db:"note"`
+	CreatedAt time.Time  `db:"created_at"`
+
+	// RecipeTitle and Servings are only populated if the item references a recipe the user is still
+	// allowed to view.
+	RecipeTitle pgtype.Text `db:"recipe_title"`
+	Servings    pgtype.Int2 `db:"servings"`
+}
+
+// DisplayName returns the text describing the planned item.
+func (i MealPlanItem) DisplayName() string {
+	if i.Recipe == nil {
+		return i.Note
+	}
+
+	if i.RecipeTitle.Valid {
+		return i.RecipeTitle.String
+	}
+
+	return "Unavailable recipe"
+}
+
+type MealPlanModel struct {
+	DB     *pgxpool.Pool
+	Logger *slog.Logger
+}
+
+// Add plans a new item. If the item references a recipe, the item's owner must be allowed to view
+// it. Otherwise ErrNotFound is returned.
+func (model *MealPlanModel) Add(ctx context.Context, item MealPlanItem) error {
+	query := `INSERT INTO meal_plan_items (id, owner, planned_on, slot, recipe, note)
+		SELECT $2::uuid, $1::text, $3::date, $4::text, $5::uuid, $6::text
+		WHERE $5::uuid IS NULL
+			OR EXISTS (SELECT 1 FROM recipes AS r WHERE r.id = $5 AND ` + visibleTo("r") + `)`
+	result, err := model.DB.Exec(
+		ctx,
+		query,
+		item.Owner,
+		item.ID,
+		item.PlannedOn,
+		item.Slot,
+		item.Recipe,
+		item.Note,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert meal plan item: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Added meal plan item.", "id", item.ID, "plannedOn", item.PlannedOn)
+
+	return nil
+}
+
+// CopyWeek copies the user's items planned in the week starting on "from" to the same days and
+// slots of the week starting on "to". Items referencing recipes the user may no longer view are
+// skipped. The number of copied items is returned.
+func (model *MealPlanModel) CopyWeek(ctx context.Context, userID string, from, to time.Time) (int64, error) {
+	query := `INSERT INTO meal_plan_items (id, owner, planned_on, slot, recipe, note)
+		SELECT gen_random_uuid(), m.owner, m.planned_on + ($3::date - $2::date), m.slot, m.recipe, m.note
+		FROM meal_plan_items AS m
+		WHERE m.owner = $1
+			AND m.planned_on >= $2::date AND m.planned_on < $2::date + 7
+			AND (m.recipe IS NULL
+				OR EXISTS (SELECT 1 FROM recipes AS r WHERE r.id = m.recipe AND ` + visibleTo("r") + `))`
+	result, err := model.DB.Exec(ctx, query, userID, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy meal plan week: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Copied meal plan week.", "from", from, "to", to, "items", result.RowsAffected())
+
+	return result.RowsAffected(), nil
+}
+
+// Delete removes one of the user's planned items.
+func (model *MealPlanModel) Delete(ctx context.Context, userID string, id uuid.UUID) error {
+	query := `DELETE FROM meal_plan_items WHERE owner = $1 AND id = $2`
+	result, err := model.DB.Exec(ctx, query, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete meal plan item: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Deleted meal plan item.", "id", id)
+
+	return nil
+}
+
+// ListRange returns the user's items planned on or after start and before end, ordered by day.
+func (model *MealPlanModel) ListRange(ctx context.Context, userID string, start, end time.Time) ([]MealPlanItem, error) {
+	query := `SELECT
+			m.id,
+			m.owner,
+			m.planned_on,
+			m.slot,
+			m.recipe,
+			m.note,
+			m.created_at,
+			r.title AS recipe_title,
+			r.servings AS servings
+		FROM meal_plan_items AS m
+			LEFT JOIN recipes AS r
+				ON m.recipe = r.id AND ` + visibleTo("r") + `
+		WHERE m.owner = $1 AND m.planned_on >= $2::date AND m.planned_on < $3::date
+		ORDER BY m.planned_on, m.created_at`
+	rows, err := model.DB.Query(ctx, query, userID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list meal plan items: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := pgx.CollectRows(rows, pgx.RowToStructByName[MealPlanItem])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map meal plan rows to struct: %w", err)
+	}
+
+	return items, nil
+}
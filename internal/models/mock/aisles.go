@@ -0,0 +1,62 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+)
+
+// Aisle is the user's only store aisle. The mock shopping list's item is assigned to it.
+var Aisle = models.StoreAisle{
+	ID:    uuid.New(),
+	Owner: TestUserNormal,
+	Name:  "Dairy",
+}
+
+type AisleModel struct {
+	LastAssignedAisle      *uuid.UUID
+	LastAssignedIngredient string
+	LastCreatedAisle       models.StoreAisle
+	LastDeletedAisle       uuid.UUID
+	LastMovedAisle         uuid.UUID
+	LastMoveOffset         int
+}
+
+func (model *AisleModel) Assign(_ context.Context, _ string, ingredient string, aisle *uuid.UUID) error {
+	if aisle != nil && *aisle != Aisle.ID {
+		return models.ErrNotFound
+	}
+
+	model.LastAssignedIngredient = ingredient
+	model.LastAssignedAisle = aisle
+
+	return nil
+}
+
+func (model *AisleModel) Assignments(context.Context, string) (map[string]uuid.UUID, error) {
+	return map[string]uuid.UUID{"milk": Aisle.ID}, nil
+}
+
+func (model *AisleModel) Create(_ context.Context, aisle models.StoreAisle) error {
+	model.LastCreatedAisle = aisle
+
+	return nil
+}
+
+func (model *AisleModel) Delete(_ context.Context, _ string, id uuid.UUID) error {
+	model.LastDeletedAisle = id
+
+	return nil
+}
+
+func (model *AisleModel) List(context.Context, string) ([]models.StoreAisle, error) {
+	return []models.StoreAisle{Aisle}, nil
+}
+
+func (model *AisleModel) Move(_ context.Context, _ string, id uuid.UUID, offset int) error {
+	model.LastMovedAisle = id
+	model.LastMoveOffset = offset
+
+	return nil
+}
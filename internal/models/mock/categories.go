@@ -25,14 +25,45 @@ var ListedCategories = []models.Category{
 
 type CategoryModel struct {
 	LastCreatedCategory models.Category
+	LastDeletedCategory uuid.UUID
+	LastUpdatedCategory models.Category
 }
 
+// Create reports a duplicate if the category has the same name as a listed category.
 func (model *CategoryModel) Create(_ context.Context, category models.Category) error {
+	for _, listed := range ListedCategories {
+		if listed.Name == category.Name {
+			return models.ErrDuplicate
+		}
+	}
+
 	model.LastCreatedCategory = category
 
 	return nil
 }
 
+func (model *CategoryModel) Delete(ctx context.Context, owner string, id uuid.UUID) error {
+	if _, err := model.Get(ctx, owner, id); err != nil {
+		return err
+	}
+
+	model.LastDeletedCategory = id
+
+	return nil
+}
+
+// Get returns one of the listed categories, or the last created category.
+func (model *CategoryModel) Get(_ context.Context, owner string, id uuid.UUID) (models.Category, error) {
+	for _, category := range append(ListedCategories, model.LastCreatedCategory) {
+		if category.ID == id {
+			category.Owner = owner
+			return category, nil
+		}
+	}
+
+	return models.Category{}, models.ErrNotFound
+}
+
 func (model *CategoryModel) List(_ context.Context, owner string) ([]models.Category, error) {
 	categories := make([]models.Category, len(ListedCategories))
 	for index, category := range ListedCategories {
@@ -42,3 +73,31 @@ func (model *CategoryModel) List(_ context.Context, owner string) ([]models.Cate
 
 	return categories, nil
 }
+
+func (model *CategoryModel) ListPage(ctx context.Context, owner string, limit int, offset int) ([]models.Category, error) {
+	categories, err := model.List(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	categories = categories[min(offset, len(categories)):]
+
+	return categories[:min(limit, len(categories))], nil
+}
+
+// Update reports a duplicate if the category is renamed to the name of another listed category.
+func (model *CategoryModel) Update(ctx context.Context, owner string, category models.Category) error {
+	if _, err := model.Get(ctx, owner, category.ID); err != nil {
+		return err
+	}
+
+	for _, listed := range ListedCategories {
+		if listed.ID != category.ID && listed.Name == category.Name {
+			return models.ErrDuplicate
+		}
+	}
+
+	model.LastUpdatedCategory = category
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const ValidCollectionShareToken = "valid-collection-share-token"
+
+// Collection is the user's only collection. It contains SubRecipe and ParentRecipe, in that order,
+// and is shared with ValidCollectionShareToken.
+var Collection = models.Collection{
+	ID:          uuid.MustParse("0b7d3c52-2f0e-4a53-8a61-3f4f1c9e2b01"),
+	Owner:       TestUserNormal,
+	Name:        "Pizza Night",
+	Description: "Everything for a homemade pizza party.",
+	ShareToken:  pgtype.Text{String: ValidCollectionShareToken, Valid: true},
+	RecipeCount: 2,
+}
+
+type CollectionModel struct {
+	LastAddedRecipe        uuid.UUID
+	LastCreatedCollection  models.Collection
+	LastDeletedCollection  uuid.UUID
+	LastRemovedRecipe      uuid.UUID
+	LastReorderedRecipes   []uuid.UUID
+	LastShareToken         string
+	LastUnsharedCollection uuid.UUID
+	LastUpdatedCollection  models.Collection
+}
+
+func (model *CollectionModel) AddRecipe(_ context.Context, _ string, id uuid.UUID, recipeID uuid.UUID) error {
+	if id != Collection.ID {
+		return models.ErrNotFound
+	}
+
+	model.LastAddedRecipe = recipeID
+
+	return nil
+}
+
+func (model *CollectionModel) Create(_ context.Context, collection models.Collection) error {
+	model.LastCreatedCollection = collection
+
+	return nil
+}
+
+func (model *CollectionModel) Delete(_ context.Context, _ string, id uuid.UUID) error {
+	if id != Collection.ID {
+		return models.ErrNotFound
+	}
+
+	model.LastDeletedCollection = id
+
+	return nil
+}
+
+func (model *CollectionModel) Get(_ context.Context, _ string, id uuid.UUID) (models.Collection, error) {
+	if id != Collection.ID {
+		return models.Collection{}, models.ErrNotFound
+	}
+
+	return Collection, nil
+}
+
+func (model *CollectionModel) GetShared(_ context.Context, token string) (models.Collection, error) {
+	if token != ValidCollectionShareToken {
+		return models.Collection{}, models.ErrNotFound
+	}
+
+	return Collection, nil
+}
+
+func (model *CollectionModel) List(context.Context, string) ([]models.Collection, error) {
+	return []models.Collection{Collection}, nil
+}
+
+func (model *CollectionModel) ListForRecipe(_ context.Context, _ string, recipeID uuid.UUID) ([]models.Collection, error) {
+	if recipeID == SubRecipe.ID || recipeID == ParentRecipe.ID {
+		return []models.Collection{Collection}, nil
+	}
+
+	return nil, nil
+}
+
+func (model *CollectionModel) Recipes(_ context.Context, _ string, id uuid.UUID) ([]models.Recipe, error) {
+	if id != Collection.ID {
+		return nil, models.ErrNotFound
+	}
+
+	return []models.Recipe{SubRecipe, ParentRecipe}, nil
+}
+
+func (model *CollectionModel) RemoveRecipe(_ context.Context, _ string, id uuid.UUID, recipeID uuid.UUID) error {
+	if id != Collection.ID {
+		return models.ErrNotFound
+	}
+
+	model.LastRemovedRecipe = recipeID
+
+	return nil
+}
+
+func (model *CollectionModel) Reorder(_ context.Context, _ string, id uuid.UUID, recipeIDs []uuid.UUID) error {
+	if id != Collection.ID {
+		return models.ErrNotFound
+	}
+
+	model.LastReorderedRecipes = recipeIDs
+
+	return nil
+}
+
+func (model *CollectionModel) Share(_ context.Context, _ string, id uuid.UUID, token string) error {
+	if id != Collection.ID {
+		return models.ErrNotFound
+	}
+
+	model.LastShareToken = token
+
+	return nil
+}
+
+func (model *CollectionModel) Unshare(_ context.Context, _ string, id uuid.UUID) error {
+	if id != Collection.ID {
+		return models.ErrNotFound
+	}
+
+	model.LastUnsharedCollection = id
+
+	return nil
+}
+
+func (model *CollectionModel) Update(_ context.Context, _ string, collection models.Collection) error {
+	if collection.ID != Collection.ID {
+		return models.ErrNotFound
+	}
+
+	model.LastUpdatedCollection = collection
+
+	return nil
+}
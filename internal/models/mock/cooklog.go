@@ -0,0 +1,41 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+)
+
+var CookLogEntry = models.CookLogEntry{
+	ID:        uuid.New(),
+	Owner:     TestUserNormal,
+	OwnerName: "Test User",
+	CookedOn:  time.Date(2024, time.May, 4, 0, 0, 0, 0, time.UTC),
+	Notes:     "Needed more salt.",
+}
+
+type CookLogModel struct {
+	LastAddedEntry   models.CookLogEntry
+	LastDeletedEntry uuid.UUID
+}
+
+func (model *CookLogModel) Add(_ context.Context, entry models.CookLogEntry) error {
+	model.LastAddedEntry = entry
+
+	return nil
+}
+
+func (model *CookLogModel) Delete(_ context.Context, _ string, id uuid.UUID) error {
+	model.LastDeletedEntry = id
+
+	return nil
+}
+
+func (model *CookLogModel) ListForRecipe(_ context.Context, _ string, recipeID uuid.UUID) ([]models.CookLogEntry, error) {
+	entry := CookLogEntry
+	entry.Recipe = recipeID
+
+	return []models.CookLogEntry{entry}, nil
+}
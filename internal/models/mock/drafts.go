@@ -0,0 +1,52 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+)
+
+// Draft is the user's only draft. It is a draft of a new recipe.
+var Draft = models.RecipeDraft{
+	ID:    uuid.MustParse("2c4e8f0a-7b1d-4d3e-9f5a-1b2c3d4e5f01"),
+	Owner: TestUserNormal,
+	Values: map[string]string{
+		"title":        "Half-Written Stew",
+		"ingredients":  "2 carrots",
+		"instructions": "Chop the carrots.",
+	},
+}
+
+type DraftModel struct {
+	LastDeletedDraft uuid.UUID
+	LastSavedDraft   models.RecipeDraft
+}
+
+func (model *DraftModel) Delete(_ context.Context, _ string, id uuid.UUID) error {
+	model.LastDeletedDraft = id
+
+	if id != Draft.ID {
+		return models.ErrNotFound
+	}
+
+	return nil
+}
+
+func (model *DraftModel) Get(_ context.Context, _ string, id uuid.UUID) (models.RecipeDraft, error) {
+	if id != Draft.ID {
+		return models.RecipeDraft{}, models.ErrNotFound
+	}
+
+	return Draft, nil
+}
+
+func (model *DraftModel) List(context.Context, string) ([]models.RecipeDraft, error) {
+	return []models.RecipeDraft{Draft}, nil
+}
+
+func (model *DraftModel) Save(_ context.Context, draft models.RecipeDraft) error {
+	model.LastSavedDraft = draft
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+)
+
+const ValidInvitationToken = "valid-invitation-token"
+
+// Household is a household that TestUserNormal is an admin of.
+var Household = models.Household{
+	ID:        uuid.New(),
+	Name:      "The Test Family",
+	CreatedAt: time.Now(),
+	UpdatedAt: time.Now(),
+	Role:      models.RoleAdmin,
+}
+
+// ViewableHousehold is a household that TestUserNormal may only view.
+var ViewableHousehold = models.Household{
+	ID:        uuid.New(),
+	Name:      "The Neighbors",
+	CreatedAt: time.Now(),
+	UpdatedAt: time.Now(),
+	Role:      models.RoleViewer,
+}
+
+type HouseholdModel struct {
+	AcceptedInvitation   string
+	LastCreatedHousehold models.Household
+	LastInvitation       models.HouseholdInvitation
+	LastRemovedMember    string
+	LastRoleChange       models.HouseholdRole
+}
+
+func (model *HouseholdModel) AcceptInvitation(_ context.Context, _ string, token string) (uuid.UUID, error) {
+	if token != ValidInvitationToken {
+		return uuid.UUID{}, models.ErrNotFound
+	}
+
+	model.AcceptedInvitation = token
+
+	return Household.ID, nil
+}
+
+func (model *HouseholdModel) Create(_ context.Context, _ string, household models.Household) error {
+	model.LastCreatedHousehold = household
+
+	return nil
+}
+
+func (model *HouseholdModel) CreateInvitation(_ context.Context, _ string, invitation models.HouseholdInvitation) error {
+	model.LastInvitation = invitation
+
+	return nil
+}
+
+func (model *HouseholdModel) Get(_ context.Context, _ string, id uuid.UUID) (models.Household, error) {
+	for _, household := range []models.Household{Household, ViewableHousehold} {
+		if household.ID == id {
+			return household, nil
+		}
+	}
+
+	return models.Household{}, models.ErrNotFound
+}
+
+func (model *HouseholdModel) GetInvitation(_ context.Context, token string) (models.HouseholdInvitation, error) {
+	if token != ValidInvitationToken {
+		return models.HouseholdInvitation{}, models.ErrNotFound
+	}
+
+	invitation := models.HouseholdInvitation{
+		ID:            uuid.New(),
+		Household:     Household.ID,
+		HouseholdName: Household.Name,
+		Token:         token,
+		Role:          models.RoleEditor,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}
+
+	return invitation, nil
+}
+
+func (model *HouseholdModel) ListForUser(context.Context, string) ([]models.Household, error) {
+	return []models.Household{Household, ViewableHousehold}, nil
+}
+
+func (model *HouseholdModel) ListInvitations(context.Context, string, uuid.UUID) ([]models.HouseholdInvitation, error) {
+	return nil, nil
+}
+
+func (model *HouseholdModel) ListMembers(_ context.Context, userID string, id uuid.UUID) ([]models.HouseholdMember, error) {
+	member := models.HouseholdMember{
+		Household: id,
+		Member:    userID,
+		Name:      "Test User",
+		Role:      models.RoleAdmin,
+	}
+
+	return []models.HouseholdMember{member}, nil
+}
+
+func (model *HouseholdModel) RemoveMember(_ context.Context, _ string, _ uuid.UUID, member string) error {
+	model.LastRemovedMember = member
+
+	return nil
+}
+
+func (model *HouseholdModel) SetMemberRole(
+	_ context.Context,
+	actor string,
+	_ uuid.UUID,
+	member string,
+	role models.HouseholdRole,
+) error {
+	if actor == member && role != models.RoleAdmin {
+		return models.ErrLastAdmin
+	}
+
+	model.LastRoleChange = role
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/cdriehuys/recipes/internal/dietary"
+	"github.com/google/uuid"
+)
+
+type LabelModel struct {
+	LastSetOverrides dietary.Overrides
+	LastSetRecipe    uuid.UUID
+}
+
+func (model *LabelModel) SetOverrides(_ context.Context, _ string, recipeID uuid.UUID, overrides dietary.Overrides) error {
+	model.LastSetRecipe = recipeID
+	model.LastSetOverrides = overrides
+
+	return nil
+}
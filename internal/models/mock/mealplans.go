@@ -0,0 +1,55 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var mealPlanRecipe = uuid.New()
+
+// MealPlanItem is a planned recipe returned on the first day of any listed range.
+var MealPlanItem = models.MealPlanItem{
+	ID:          uuid.New(),
+	Owner:       TestUserNormal,
+	Slot:        models.SlotDinner,
+	Recipe:      &mealPlanRecipe,
+	RecipeTitle: pgtype.Text{String: "Weeknight Chili", Valid: true},
+	Servings:    pgtype.Int2{Int16: 6, Valid: true},
+}
+
+type MealPlanModel struct {
+	LastAddedItem   models.MealPlanItem
+	LastCopiedFrom  time.Time
+	LastCopiedTo    time.Time
+	LastDeletedItem uuid.UUID
+}
+
+func (model *MealPlanModel) Add(_ context.Context, item models.MealPlanItem) error {
+	model.LastAddedItem = item
+
+	return nil
+}
+
+func (model *MealPlanModel) CopyWeek(_ context.Context, _ string, from, to time.Time) (int64, error) {
+	model.LastCopiedFrom = from
+	model.LastCopiedTo = to
+
+	return 1, nil
+}
+
+func (model *MealPlanModel) Delete(_ context.Context, _ string, id uuid.UUID) error {
+	model.LastDeletedItem = id
+
+	return nil
+}
+
+func (model *MealPlanModel) ListRange(_ context.Context, _ string, start, _ time.Time) ([]models.MealPlanItem, error) {
+	item := MealPlanItem
+	item.PlannedOn = start
+
+	return []models.MealPlanItem{item}, nil
+}
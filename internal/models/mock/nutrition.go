@@ -0,0 +1,24 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/cdriehuys/recipes/internal/nutrition"
+	"github.com/google/uuid"
+)
+
+type NutritionModel struct {
+	LastSetMatches map[string]string
+	LastSetRecipe  uuid.UUID
+}
+
+func (model *NutritionModel) Matches(context.Context, string, uuid.UUID) (map[string]string, error) {
+	return map[string]string{"egg": nutrition.Ignore}, nil
+}
+
+func (model *NutritionModel) SetMatches(_ context.Context, _ string, recipeID uuid.UUID, matches map[string]string) error {
+	model.LastSetRecipe = recipeID
+	model.LastSetMatches = matches
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PantryItem is the only item in the mock pantry. It expires tomorrow.
+var PantryItem = models.PantryItem{
+	ID:        uuid.New(),
+	Owner:     TestUserNormal,
+	Name:      "milk",
+	Quantity:  pgtype.Float8{Float64: 1, Valid: true},
+	Unit:      "quart",
+	ExpiresOn: pgtype.Date{Time: time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1), Valid: true},
+}
+
+type PantryModel struct {
+	LastAddedItem   models.PantryItem
+	LastDeletedItem uuid.UUID
+}
+
+func (model *PantryModel) Add(_ context.Context, item models.PantryItem) error {
+	model.LastAddedItem = item
+
+	return nil
+}
+
+func (model *PantryModel) Delete(_ context.Context, _ string, id uuid.UUID) error {
+	model.LastDeletedItem = id
+
+	return nil
+}
+
+func (model *PantryModel) List(context.Context, string) ([]models.PantryItem, error) {
+	return []models.PantryItem{PantryItem}, nil
+}
@@ -0,0 +1,33 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type RatingModel struct {
+	Favorite  bool
+	Rating    pgtype.Int2
+	MakeAgain pgtype.Bool
+}
+
+func (model *RatingModel) SetFavorite(_ context.Context, _ string, _ uuid.UUID, favorite bool) error {
+	model.Favorite = favorite
+
+	return nil
+}
+
+func (model *RatingModel) Rate(
+	_ context.Context,
+	_ string,
+	_ uuid.UUID,
+	rating pgtype.Int2,
+	makeAgain pgtype.Bool,
+) error {
+	model.Rating = rating
+	model.MakeAgain = makeAgain
+
+	return nil
+}
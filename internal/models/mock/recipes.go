@@ -2,13 +2,61 @@ package mock
 
 import (
 	"context"
+	"slices"
+	"time"
 
 	"github.com/cdriehuys/recipes/internal/models"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// Recipe is returned, with the requested ID, for any recipe lookup. It is also the only recipe
+// listed.
+var Recipe = models.Recipe{
+	Title:        "Mock Recipe",
+	Ingredients:  "2 cups milk\n250 ml milk\n2 eggs",
+	Instructions: "Mix it all together.",
+	PrepTime:     models.DurationFromMinutes(5),
+	CookTime:     models.DurationFromMinutes(60),
+	TotalTime:    models.DurationFromMinutes(65),
+	SourceName:   "Mock Kitchen",
+	SourceURL:    "https://example.com/mock-recipe",
+	Tags:         []string{"breakfast", "weeknight"},
+	UpdatedAt:    time.Date(2024, 5, 19, 12, 30, 0, 0, time.UTC),
+}
+
+// SubRecipe is a recipe used as an ingredient of ParentRecipe.
+var SubRecipe = models.Recipe{
+	ID:           uuid.MustParse("6f1f2a52-54a3-4c1e-9b8a-6c0d7f8e1a01"),
+	Title:        "Pizza Dough",
+	Ingredients:  "3 cups flour\n1 cup water",
+	Instructions: "Knead for 10 minutes.",
+}
+
+// ParentRecipe uses two batches of SubRecipe.
+var ParentRecipe = models.Recipe{
+	ID:           uuid.MustParse("6f1f2a52-54a3-4c1e-9b8a-6c0d7f8e1a02"),
+	Title:        "Pizza",
+	Ingredients:  "2 batches pizza dough\n1 cup mozzarella",
+	Instructions: "Stretch the pizza dough and top it.",
+}
+
+// DuplicateRecipeID is the ID of every recipe created by duplicating another.
+var DuplicateRecipeID = uuid.MustParse("6f1f2a52-54a3-4c1e-9b8a-6c0d7f8e1a03")
+
 type RecipeModel struct {
+	// LastBulkAction is the name of the last bulk method called, such as "BulkMove", and
+	// LastBulkRecipes are the recipes it was called with.
+	LastBulkAction   string
+	LastBulkCategory *uuid.UUID
+	LastBulkRecipes  []uuid.UUID
+	LastBulkTag      string
+
+	LastComponents    map[string]uuid.UUID
 	LastCreatedRecipe models.Recipe
+	LastDuplicatedID  uuid.UUID
+	LastListOptions   models.RecipeListOptions
+	LastUpdatedRecipe models.Recipe
 }
 
 func (model *RecipeModel) Add(_ context.Context, recipe models.Recipe) error {
@@ -17,18 +65,138 @@ func (model *RecipeModel) Add(_ context.Context, recipe models.Recipe) error {
 	return nil
 }
 
+func (model *RecipeModel) BulkDelete(_ context.Context, _ string, ids []uuid.UUID) error {
+	model.LastBulkAction = "BulkDelete"
+	model.LastBulkRecipes = ids
+
+	return nil
+}
+
+func (model *RecipeModel) BulkMove(_ context.Context, _ string, ids []uuid.UUID, category *uuid.UUID) error {
+	model.LastBulkAction = "BulkMove"
+	model.LastBulkRecipes = ids
+	model.LastBulkCategory = category
+
+	return nil
+}
+
+func (model *RecipeModel) BulkTag(_ context.Context, _ string, ids []uuid.UUID, tag string) error {
+	model.LastBulkAction = "BulkTag"
+	model.LastBulkRecipes = ids
+	model.LastBulkTag = tag
+
+	return nil
+}
+
+func (model *RecipeModel) BulkUntag(_ context.Context, _ string, ids []uuid.UUID, tag string) error {
+	model.LastBulkAction = "BulkUntag"
+	model.LastBulkRecipes = ids
+	model.LastBulkTag = tag
+
+	return nil
+}
+
+// Components reports that ParentRecipe's pizza dough is linked to SubRecipe.
+func (model *RecipeModel) Components(_ context.Context, _ string, recipeID uuid.UUID) (map[string]models.RecipeComponent, error) {
+	components := make(map[string]models.RecipeComponent)
+	if recipeID == ParentRecipe.ID {
+		components[models.ComponentKey(SubRecipe.Title)] = models.RecipeComponent{ID: SubRecipe.ID, Title: SubRecipe.Title}
+	}
+
+	return components, nil
+}
+
 func (model *RecipeModel) Delete(context.Context, string, uuid.UUID) error {
 	return nil
 }
 
-func (model *RecipeModel) GetByID(context.Context, string, uuid.UUID) (models.Recipe, error) {
-	return models.Recipe{}, nil
+func (model *RecipeModel) Duplicate(_ context.Context, _ string, id uuid.UUID) (uuid.UUID, error) {
+	model.LastDuplicatedID = id
+
+	return DuplicateRecipeID, nil
+}
+
+// FindByTitles finds SubRecipe by its title.
+func (model *RecipeModel) FindByTitles(_ context.Context, _ string, titles []string) ([]models.RecipeComponent, error) {
+	var components []models.RecipeComponent
+	if slices.Contains(titles, models.ComponentKey(SubRecipe.Title)) {
+		components = append(components, models.RecipeComponent{ID: SubRecipe.ID, Title: SubRecipe.Title})
+	}
+
+	return components, nil
+}
+
+func (model *RecipeModel) GetByID(_ context.Context, _ string, id uuid.UUID) (models.Recipe, error) {
+	switch id {
+	case SubRecipe.ID:
+		return SubRecipe, nil
+	case ParentRecipe.ID:
+		return ParentRecipe, nil
+	case DuplicateRecipeID:
+		recipe := SubRecipe
+		recipe.ID = DuplicateRecipeID
+		recipe.Title = "Copy of " + SubRecipe.Title
+		recipe.ForkedFrom = &SubRecipe.ID
+		recipe.ForkedFromTitle = pgtype.Text{String: SubRecipe.Title, Valid: true}
+
+		return recipe, nil
+	}
+
+	recipe := Recipe
+	recipe.ID = id
+
+	return recipe, nil
+}
+
+func (model *RecipeModel) List(_ context.Context, _ string, opts models.RecipeListOptions) ([]models.Recipe, error) {
+	model.LastListOptions = opts
+
+	return []models.Recipe{Recipe}, nil
 }
 
-func (model *RecipeModel) List(context.Context, string) ([]models.Recipe, error) {
+// ListByIDs returns the recipe GetByID returns for each ID.
+func (model *RecipeModel) ListByIDs(ctx context.Context, userID string, ids []uuid.UUID) ([]models.Recipe, error) {
+	recipes := make([]models.Recipe, 0, len(ids))
+	for _, id := range ids {
+		recipe, err := model.GetByID(ctx, userID, id)
+		if err != nil {
+			return nil, err
+		}
+
+		recipes = append(recipes, recipe)
+	}
+
+	return recipes, nil
+}
+
+// ReferencedBy reports that SubRecipe is used by ParentRecipe.
+func (model *RecipeModel) ReferencedBy(_ context.Context, _ string, recipe models.Recipe) ([]models.RecipeComponent, error) {
+	if recipe.ID == SubRecipe.ID {
+		return []models.RecipeComponent{{ID: ParentRecipe.ID, Title: ParentRecipe.Title}}, nil
+	}
+
 	return nil, nil
 }
 
-func (model *RecipeModel) Update(context.Context, models.Recipe) error {
+func (model *RecipeModel) SetComponents(_ context.Context, _ string, _ uuid.UUID, components map[string]uuid.UUID) error {
+	model.LastComponents = components
+
+	return nil
+}
+
+// Update reports a conflict if the recipe's version does not match the version returned by
+// GetByID.
+func (model *RecipeModel) Update(ctx context.Context, userID string, recipe models.Recipe) error {
+	current, err := model.GetByID(ctx, userID, recipe.ID)
+	if err != nil {
+		return err
+	}
+
+	if !recipe.UpdatedAt.IsZero() && !recipe.UpdatedAt.Equal(current.UpdatedAt) {
+		return models.ErrConflict
+	}
+
+	model.LastUpdatedRecipe = recipe
+
 	return nil
 }
@@ -0,0 +1,47 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+)
+
+const ValidShareToken = "valid-share-token"
+
+var SharedRecipe = models.Recipe{
+	ID:           uuid.New(),
+	Title:        "Shared Recipe",
+	Instructions: "Share and enjoy.",
+}
+
+type ShareModel struct {
+	LastCreatedShare       models.RecipeShare
+	LastRevokedShare       uuid.UUID
+	LastRevokedShareRecipe uuid.UUID
+}
+
+func (model *ShareModel) Create(_ context.Context, _ string, share models.RecipeShare) error {
+	model.LastCreatedShare = share
+
+	return nil
+}
+
+func (model *ShareModel) GetRecipe(_ context.Context, token string) (models.Recipe, error) {
+	if token == ValidShareToken {
+		return SharedRecipe, nil
+	}
+
+	return models.Recipe{}, models.ErrNotFound
+}
+
+func (model *ShareModel) ListForRecipe(context.Context, string, uuid.UUID) ([]models.RecipeShare, error) {
+	return nil, nil
+}
+
+func (model *ShareModel) Revoke(_ context.Context, _ string, recipeID uuid.UUID, id uuid.UUID) error {
+	model.LastRevokedShare = id
+	model.LastRevokedShareRecipe = recipeID
+
+	return nil
+}
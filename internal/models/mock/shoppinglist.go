@@ -0,0 +1,70 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ShoppingListItem is the only item on the mock shopping list.
+var ShoppingListItem = models.ShoppingListItem{
+	ID:       uuid.New(),
+	Owner:    TestUserNormal,
+	Name:     "milk",
+	Quantity: pgtype.Float8{Float64: 2, Valid: true},
+	Unit:     "cup",
+}
+
+type ShoppingListModel struct {
+	AddedItems      []models.ShoppingListItem
+	ClearedChecked  bool
+	LastCheckedItem uuid.UUID
+	LastChecked     bool
+	LastDeletedItem uuid.UUID
+	LastUpdatedItem models.ShoppingListItem
+}
+
+func (model *ShoppingListModel) Add(_ context.Context, _ string, items []models.ShoppingListItem) error {
+	model.AddedItems = append(model.AddedItems, items...)
+
+	return nil
+}
+
+func (model *ShoppingListModel) Delete(_ context.Context, _ string, id uuid.UUID) error {
+	model.LastDeletedItem = id
+
+	return nil
+}
+
+func (model *ShoppingListModel) DeleteChecked(context.Context, string) error {
+	model.ClearedChecked = true
+
+	return nil
+}
+
+func (model *ShoppingListModel) Get(_ context.Context, _ string, id uuid.UUID) (models.ShoppingListItem, error) {
+	if id == ShoppingListItem.ID {
+		return ShoppingListItem, nil
+	}
+
+	return models.ShoppingListItem{}, models.ErrNotFound
+}
+
+func (model *ShoppingListModel) List(context.Context, string) ([]models.ShoppingListItem, error) {
+	return []models.ShoppingListItem{ShoppingListItem}, nil
+}
+
+func (model *ShoppingListModel) SetChecked(_ context.Context, _ string, id uuid.UUID, checked bool) error {
+	model.LastCheckedItem = id
+	model.LastChecked = checked
+
+	return nil
+}
+
+func (model *ShoppingListModel) Update(_ context.Context, _ string, item models.ShoppingListItem) error {
+	model.LastUpdatedItem = item
+
+	return nil
+}
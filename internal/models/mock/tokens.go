@@ -0,0 +1,58 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+)
+
+// Secrets of access tokens accepted by the mock token model.
+const (
+	ValidAccessToken    = "rcp_valid-access-token"
+	ReadOnlyAccessToken = "rcp_read-only-access-token"
+)
+
+type TokenModel struct {
+	LastCreatedToken  models.AccessToken
+	LastCreatedSecret string
+	LastRevokedToken  uuid.UUID
+}
+
+func (model *TokenModel) Authenticate(_ context.Context, secret string) (models.AccessToken, error) {
+	switch secret {
+	case ValidAccessToken:
+		return models.AccessToken{
+			ID:     uuid.New(),
+			Owner:  TestUserNormal,
+			Name:   "Valid",
+			Scopes: []string{models.ScopeRead, models.ScopeWrite},
+		}, nil
+	case ReadOnlyAccessToken:
+		return models.AccessToken{
+			ID:     uuid.New(),
+			Owner:  TestUserNormal,
+			Name:   "Read only",
+			Scopes: []string{models.ScopeRead},
+		}, nil
+	default:
+		return models.AccessToken{}, models.ErrNotFound
+	}
+}
+
+func (model *TokenModel) Create(_ context.Context, token models.AccessToken, secret string) error {
+	model.LastCreatedToken = token
+	model.LastCreatedSecret = secret
+
+	return nil
+}
+
+func (model *TokenModel) List(context.Context, string) ([]models.AccessToken, error) {
+	return nil, nil
+}
+
+func (model *TokenModel) Revoke(_ context.Context, _ string, id uuid.UUID) error {
+	model.LastRevokedToken = id
+
+	return nil
+}
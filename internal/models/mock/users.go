@@ -1,12 +1,40 @@
 package mock
 
-import "context"
+import (
+	"context"
+
+	"github.com/cdriehuys/recipes/internal/models"
+)
 
 const (
 	TestUserNormal = "standard-user"
+
+	// ValidFeedToken is the token of TestUserNormal's recipe feed.
+	ValidFeedToken = "valid-feed-token"
 )
 
-type UserModel struct{}
+type UserModel struct {
+	// LastFeedToken is the token most recently passed to SetFeedToken.
+	LastFeedToken *string
+}
+
+func (model *UserModel) FeedOwner(_ context.Context, token string) (string, error) {
+	if token != ValidFeedToken {
+		return "", models.ErrNotFound
+	}
+
+	return TestUserNormal, nil
+}
+
+func (model *UserModel) FeedEnabled(context.Context, string) (bool, error) {
+	return true, nil
+}
+
+func (model *UserModel) SetFeedToken(_ context.Context, _ string, token string) error {
+	model.LastFeedToken = &token
+
+	return nil
+}
 
 func (model *UserModel) Exists(_ context.Context, id string) (bool, error) {
 	switch id {
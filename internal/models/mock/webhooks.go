@@ -0,0 +1,64 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+)
+
+var Webhook = models.Webhook{
+	ID:     uuid.New(),
+	Owner:  TestUserNormal,
+	URL:    "https://example.com/hooks/recipes",
+	Secret: "webhook-secret",
+	Events: []string{models.EventRecipeCreated},
+}
+
+// EnqueuedWebhookEvent is an event passed to WebhookModel.Enqueue.
+type EnqueuedWebhookEvent struct {
+	Owner     string
+	Household *uuid.UUID
+	Event     string
+	Payload   []byte
+}
+
+type WebhookModel struct {
+	Enqueued           []EnqueuedWebhookEvent
+	LastCreatedWebhook models.Webhook
+	LastDeletedWebhook uuid.UUID
+}
+
+func (model *WebhookModel) Create(_ context.Context, webhook models.Webhook) error {
+	model.LastCreatedWebhook = webhook
+
+	return nil
+}
+
+func (model *WebhookModel) Delete(_ context.Context, _ string, id uuid.UUID) error {
+	model.LastDeletedWebhook = id
+
+	return nil
+}
+
+func (model *WebhookModel) Deliveries(context.Context, string, uuid.UUID, int) ([]models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (model *WebhookModel) Enqueue(_ context.Context, owner string, household *uuid.UUID, event string, payload []byte) error {
+	model.Enqueued = append(model.Enqueued, EnqueuedWebhookEvent{Owner: owner, Household: household, Event: event, Payload: payload})
+
+	return nil
+}
+
+func (model *WebhookModel) Get(_ context.Context, _ string, id uuid.UUID) (models.Webhook, error) {
+	if id == Webhook.ID {
+		return Webhook, nil
+	}
+
+	return models.Webhook{}, models.ErrNotFound
+}
+
+func (model *WebhookModel) List(context.Context, string) ([]models.Webhook, error) {
+	return []models.Webhook{Webhook}, nil
+}
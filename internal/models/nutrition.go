@@ -0,0 +1,82 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NutritionModel stores the foods recipe ingredients were matched to by hand when calculating
+// nutrition facts.
+type NutritionModel struct {
+	DB     *pgxpool.Pool
+	Logger *slog.Logger
+}
+
+// Matches returns the manual matches of a recipe the user can view, keyed by the normalized name of
+// the ingredient.
+func (model *NutritionModel) Matches(ctx context.Context, userID string, recipeID uuid.UUID) (map[string]string, error) {
+	query := `SELECT m.ingredient, m.food
+		FROM recipe_nutrition_matches AS m
+			JOIN recipes AS r ON r.id = m.recipe
+		WHERE m.recipe = $2 AND ` + visibleTo("r")
+	rows, err := model.DB.Query(ctx, query, userID, recipeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query for nutrition matches: %w", err)
+	}
+
+	matches := make(map[string]string)
+	var ingredient, food string
+	_, err = pgx.ForEachRow(rows, []any{&ingredient, &food}, func() error {
+		matches[ingredient] = food
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nutrition matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+// SetMatches replaces the manual matches of a recipe the user can edit. ErrNotFound is returned if
+// the recipe does not exist or the user may not edit it.
+func (model *NutritionModel) SetMatches(ctx context.Context, userID string, recipeID uuid.UUID, matches map[string]string) error {
+	err := pgx.BeginFunc(ctx, model.DB, func(tx pgx.Tx) error {
+		var found bool
+		query := `SELECT EXISTS (SELECT 1 FROM recipes AS r WHERE r.id = $2 AND ` + editableBy("r") + `)`
+		if err := tx.QueryRow(ctx, query, userID, recipeID).Scan(&found); err != nil {
+			return err
+		}
+
+		if !found {
+			return ErrNotFound
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM recipe_nutrition_matches WHERE recipe = $1`, recipeID); err != nil {
+			return err
+		}
+
+		batch := &pgx.Batch{}
+		for ingredient, food := range matches {
+			batch.Queue(
+				`INSERT INTO recipe_nutrition_matches (recipe, ingredient, food) VALUES ($1, $2, $3)`,
+				recipeID,
+				ingredient,
+				food,
+			)
+		}
+
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set nutrition matches: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Updated nutrition matches.", "recipe", recipeID, "count", len(matches))
+
+	return nil
+}
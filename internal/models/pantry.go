@@ -0,0 +1,130 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/ingredients"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PantryExpiringDays is how many days before its expiry date a pantry item is considered to be
+// expiring soon.
+const PantryExpiringDays = 3
+
+// PantryItem is an ingredient the user has on hand.
+type PantryItem struct {
+	ID       uuid.UUID     `db:"id"`
+	Owner    string        `db:"owner"`
+	Name     string        `db:"name"`
+	Quantity pgtype.Float8 `db:"quantity"`
+	// Unit is the canonical name of the unit the quantity is measured in, or blank for a bare count.
+	Unit      string      `db:"unit"`
+	ExpiresOn pgtype.Date `db:"expires_on"`
+	CreatedAt time.Time   `db:"created_at"`
+}
+
+// NewPantryItem creates a pantry item for the user from a parsed ingredient.
+func NewPantryItem(userID string, ingredient ingredients.Ingredient, expiresOn pgtype.Date) PantryItem {
+	item := PantryItem{
+		ID:        uuid.New(),
+		Owner:     userID,
+		Name:      ingredient.Name,
+		Unit:      ingredient.Unit.Name,
+		ExpiresOn: expiresOn,
+	}
+
+	if ingredient.HasQuantity() {
+		item.Quantity = pgtype.Float8{Float64: ingredient.Quantity, Valid: true}
+	}
+
+	return item
+}
+
+// Ingredient returns the item as an ingredient so it may be formatted or compared with others.
+func (i PantryItem) Ingredient() ingredients.Ingredient {
+	unit, _ := ingredients.LookupUnit(i.Unit)
+
+	return ingredients.Ingredient{
+		Quantity: i.Quantity.Float64,
+		Unit:     unit,
+		Name:     i.Name,
+	}
+}
+
+// String returns the item formatted as it would appear in an ingredient list.
+func (i PantryItem) String() string {
+	return i.Ingredient().String()
+}
+
+// Expired reports if the item's expiry date is before the provided day.
+func (i PantryItem) Expired(today time.Time) bool {
+	return i.ExpiresOn.Valid && i.ExpiresOn.Time.Before(today)
+}
+
+// ExpiringSoon reports if the item has not expired yet, but will within PantryExpiringDays of the
+// provided day.
+func (i PantryItem) ExpiringSoon(today time.Time) bool {
+	return i.ExpiresOn.Valid &&
+		!i.Expired(today) &&
+		i.ExpiresOn.Time.Before(today.AddDate(0, 0, PantryExpiringDays+1))
+}
+
+type PantryModel struct {
+	DB     *pgxpool.Pool
+	Logger *slog.Logger
+}
+
+// Add stores a new item in the user's pantry.
+func (model *PantryModel) Add(ctx context.Context, item PantryItem) error {
+	query := `INSERT INTO pantry_items (id, owner, name, quantity, unit, expires_on)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := model.DB.Exec(ctx, query, item.ID, item.Owner, item.Name, item.Quantity, item.Unit, item.ExpiresOn)
+	if err != nil {
+		return fmt.Errorf("failed to insert pantry item: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Added pantry item.", "id", item.ID)
+
+	return nil
+}
+
+// Delete removes an item from the user's pantry.
+func (model *PantryModel) Delete(ctx context.Context, userID string, id uuid.UUID) error {
+	query := `DELETE FROM pantry_items WHERE owner = $1 AND id = $2`
+	result, err := model.DB.Exec(ctx, query, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete pantry item: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// List returns the items in the user's pantry, with those expiring first listed first.
+func (model *PantryModel) List(ctx context.Context, userID string) ([]PantryItem, error) {
+	query := `SELECT id, owner, name, quantity, unit, expires_on, created_at
+		FROM pantry_items
+		WHERE owner = $1
+		ORDER BY expires_on NULLS LAST, lower(name)`
+	rows, err := model.DB.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pantry items: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := pgx.CollectRows(rows, pgx.RowToStructByName[PantryItem])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map pantry rows to struct: %w", err)
+	}
+
+	return items, nil
+}
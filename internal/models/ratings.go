@@ -0,0 +1,63 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RatingModel stores each user's personal opinion of the recipes they can view.
+type RatingModel struct {
+	DB     *pgxpool.Pool
+	Logger *slog.Logger
+}
+
+// SetFavorite marks or unmarks a recipe as one of the user's favorites.
+func (model *RatingModel) SetFavorite(ctx context.Context, userID string, recipeID uuid.UUID, favorite bool) error {
+	query := `INSERT INTO recipe_ratings (recipe, owner, favorite)
+		SELECT r.id, $1::text, $3::boolean FROM recipes AS r WHERE r.id = $2 AND ` + visibleTo("r") + `
+		ON CONFLICT (recipe, owner) DO UPDATE SET favorite = EXCLUDED.favorite`
+	result, err := model.DB.Exec(ctx, query, userID, recipeID, favorite)
+	if err != nil {
+		return fmt.Errorf("failed to update favorite recipe: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Updated favorite recipe.", "recipe", recipeID, "favorite", favorite)
+
+	return nil
+}
+
+// Rate records the user's rating of a recipe. Both the rating and the "would make again" flag are
+// optional.
+func (model *RatingModel) Rate(
+	ctx context.Context,
+	userID string,
+	recipeID uuid.UUID,
+	rating pgtype.Int2,
+	makeAgain pgtype.Bool,
+) error {
+	query := `INSERT INTO recipe_ratings (recipe, owner, rating, make_again)
+		SELECT r.id, $1::text, $3::smallint, $4::boolean FROM recipes AS r WHERE r.id = $2 AND ` + visibleTo("r") + `
+		ON CONFLICT (recipe, owner) DO UPDATE
+		SET rating = EXCLUDED.rating, make_again = EXCLUDED.make_again`
+	result, err := model.DB.Exec(ctx, query, userID, recipeID, rating, makeAgain)
+	if err != nil {
+		return fmt.Errorf("failed to rate recipe: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Rated recipe.", "recipe", recipeID)
+
+	return nil
+}
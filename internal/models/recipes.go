@@ -2,10 +2,15 @@ package models
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"strconv"
 	"time"
 
+	"github.com/cdriehuys/recipes/internal/dietary"
+	"github.com/cdriehuys/recipes/internal/ingredients"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -13,15 +18,47 @@ import (
 )
 
 type Recipe struct {
-	ID           uuid.UUID  `db:"id"`
-	Owner        string     `db:"owner"`
-	Category     *uuid.UUID `db:"category"`
-	Title        string     `db:"title"`
-	Instructions string     `db:"instructions"`
-	CreatedAt    time.Time  `db:"created_at"`
-	UpdatedAt    time.Time  `db:"updated_at"`
+	ID           uuid.UUID   `db:"id"`
+	Owner        string      `db:"owner"`
+	Household    *uuid.UUID  `db:"household"`
+	Category     *uuid.UUID  `db:"category"`
+	Title        string      `db:"title"`
+	Ingredients  string      `db:"ingredients"`
+	Instructions string      `db:"instructions"`
+	Servings     pgtype.Int2 `db:"servings"`
+	CreatedAt    time.Time   `db:"created_at"`
+	UpdatedAt    time.Time   `db:"updated_at"`
 
-	CategoryName pgtype.Text `db:"category_name"`
+	PrepTime Duration `db:"prep_time"`
+	CookTime Duration `db:"cook_time"`
+	// TotalTime includes any time beyond preparing and cooking, such as resting or chilling.
+	TotalTime Duration `db:"total_time"`
+
+	// SourceName and SourceURL credit where the recipe came from. Either may be blank.
+	SourceName string `db:"source_name"`
+	SourceURL  string `db:"source_url"`
+
+	CategoryName  pgtype.Text `db:"category_name"`
+	HouseholdName pgtype.Text `db:"household_name"`
+
+	// ForkedFrom is the recipe this recipe was copied from, if any. ForkedFromTitle is only
+	// populated if the original still exists and is visible to the user.
+	ForkedFrom      *uuid.UUID  `db:"forked_from"`
+	ForkedFromTitle pgtype.Text `db:"forked_from_title"`
+
+	// Tags are the recipe's tags in alphabetical order.
+	Tags []string `db:"tags"`
+
+	// LabelOverrides are the manual corrections to the recipe's detected allergen and diet labels.
+	LabelOverrides dietary.Overrides `db:"label_overrides"`
+
+	// The following fields are specific to the user the recipe was retrieved for.
+	Favorite  bool        `db:"favorite"`
+	Rating    pgtype.Int2 `db:"rating"`
+	MakeAgain pgtype.Bool `db:"make_again"`
+
+	// LastCooked is the most recent date anyone with access to the recipe logged cooking it.
+	LastCooked pgtype.Date `db:"last_cooked"`
 }
 
 func (r Recipe) CategoryDisplayName() string {
@@ -32,80 +69,327 @@ func (r Recipe) CategoryDisplayName() string {
 	return "Uncategorized"
 }
 
+// IngredientList returns the parsed lines of the recipe's ingredient list.
+func (r Recipe) IngredientList() []ingredients.Ingredient {
+	return ingredients.ParseList(r.Ingredients)
+}
+
+// Labels returns the recipe's allergen and diet labels, as detected from its ingredients and then
+// corrected by hand.
+func (r Recipe) Labels() dietary.Labels {
+	return dietary.Detect(r.IngredientList()).Apply(r.LabelOverrides)
+}
+
 // EditURL returns the URL to the recipe's edit view.
 func (r Recipe) EditURL() string {
 	return "/recipes/" + r.ID.String() + "/edit"
 }
 
+// ForkedFromURL returns the URL of the recipe this recipe was copied from, or a blank string if it
+// was not copied.
+func (r Recipe) ForkedFromURL() string {
+	if r.ForkedFrom == nil {
+		return ""
+	}
+
+	return "/recipes/" + r.ForkedFrom.String()
+}
+
+// recipeSelect selects all the columns required to populate a Recipe from the recipes table aliased
+// as "r".
+var recipeSelect = `SELECT
+			r.id AS id,
+			r.owner AS owner,
+			r.household AS household,
+			r.category AS category,
+			r.title AS title,
+			r.ingredients AS ingredients,
+			r.instructions AS instructions,
+			r.servings AS servings,
+			r.created_at AS created_at,
+			r.updated_at AS updated_at,
+			r.prep_time AS prep_time,
+			r.cook_time AS cook_time,
+			r.total_time AS total_time,
+			r.source_name AS source_name,
+			r.source_url AS source_url,
+			c.name AS category_name,
+			h.name AS household_name,
+			r.forked_from AS forked_from,
+			(SELECT f.title FROM recipes AS f WHERE f.id = r.forked_from AND ` + visibleTo("f") + `) AS forked_from_title,
+			ARRAY(SELECT t.tag FROM recipe_tags AS t WHERE t.recipe = r.id ORDER BY t.tag) AS tags,
+			COALESCE(
+				(SELECT jsonb_object_agg(o.label, o.present) FROM recipe_label_overrides AS o WHERE o.recipe = r.id),
+				'{}'::jsonb
+			) AS label_overrides,
+			COALESCE(rr.favorite, false) AS favorite,
+			rr.rating AS rating,
+			rr.make_again AS make_again,
+			(SELECT max(cooked_on) FROM cook_log_entries WHERE recipe = r.id) AS last_cooked
+		FROM recipes AS r
+			LEFT JOIN categories AS c
+				ON r.category = c.id
+			LEFT JOIN households AS h
+				ON r.household = h.id
+			LEFT JOIN recipe_ratings AS rr
+				ON r.id = rr.recipe AND rr.owner = $1`
+
+// RecipeSort is an ordering that may be applied to a list of recipes.
+type RecipeSort string
+
+const (
+	// SortTitle orders recipes alphabetically by title.
+	SortTitle RecipeSort = "title"
+	// SortRating orders recipes from highest to lowest rating, with unrated recipes last.
+	SortRating RecipeSort = "rating"
+	// SortLastCooked orders recipes from least to most recently cooked, with recipes that have never
+	// been cooked first.
+	SortLastCooked RecipeSort = "last-cooked"
+	// SortTotalTime orders recipes from quickest to slowest, with recipes without a total time last.
+	SortTotalTime RecipeSort = "total-time"
+	// SortRecent orders recipes from most to least recently added or updated.
+	SortRecent RecipeSort = "recent"
+)
+
+// RecipeSorts lists all the supported recipe orderings.
+var RecipeSorts = []RecipeSort{SortTitle, SortRating, SortLastCooked, SortTotalTime, SortRecent}
+
+// MaxTotalMinutes lists the supported limits on the total time of listed recipes.
+var MaxTotalMinutes = []int{15, 30, 60}
+
+// orderBy returns the SQL ORDER BY expression for the sort.
+func (s RecipeSort) orderBy() string {
+	switch s {
+	case SortRating:
+		return "rr.rating DESC NULLS LAST, r.title"
+	case SortLastCooked:
+		return "last_cooked ASC NULLS FIRST, r.title"
+	case SortTotalTime:
+		return "r.total_time ASC NULLS LAST, r.title"
+	case SortRecent:
+		return "r.updated_at DESC, r.title"
+	default:
+		return "r.title"
+	}
+}
+
+// RecipeListOptions controls which recipes are returned from RecipeModel.List and in what order.
+type RecipeListOptions struct {
+	// FavoritesOnly limits the list to the user's favorite recipes.
+	FavoritesOnly bool
+	Sort          RecipeSort
+
+	// ExcludeAllergens omits recipes labelled as containing any of the allergens.
+	ExcludeAllergens dietary.AllergenSet
+	// Diet limits the list to recipes labelled as suitable for the diet, if provided.
+	Diet dietary.Diet
+
+	// MaxTotalMinutes limits the list to recipes that take at most this many minutes in total, if
+	// greater than zero. Recipes without a total time are omitted.
+	MaxTotalMinutes int
+
+	// Tag limits the list to recipes with the tag, if provided.
+	Tag string
+
+	// Limit is the most recipes to return. It defaults to, and may not exceed, 100. Offset is the
+	// number of matching recipes to skip before the first one returned.
+	Limit  int
+	Offset int
+}
+
+// WithSort returns a copy of the options using a different sort.
+func (o RecipeListOptions) WithSort(sort RecipeSort) RecipeListOptions {
+	o.Sort = sort
+	return o
+}
+
+// WithFavoritesOnly returns a copy of the options with the favorites filter changed.
+func (o RecipeListOptions) WithFavoritesOnly(favoritesOnly bool) RecipeListOptions {
+	o.FavoritesOnly = favoritesOnly
+	return o
+}
+
+// WithMaxTotalMinutes returns a copy of the options with the total time limit changed.
+func (o RecipeListOptions) WithMaxTotalMinutes(minutes int) RecipeListOptions {
+	o.MaxTotalMinutes = minutes
+	return o
+}
+
+// WithTag returns a copy of the options with the tag filter changed.
+func (o RecipeListOptions) WithTag(tag string) RecipeListOptions {
+	o.Tag = tag
+	return o
+}
+
+// URL returns the path of the recipe list with the options encoded in its query string.
+func (o RecipeListOptions) URL() string {
+	query := url.Values{}
+	if o.FavoritesOnly {
+		query.Set("favorites", "1")
+	}
+
+	query.Set("sort", string(o.Sort))
+
+	for _, allergen := range o.ExcludeAllergens.List() {
+		query.Add("exclude", string(allergen))
+	}
+
+	if o.Diet != "" {
+		query.Set("diet", string(o.Diet))
+	}
+
+	if o.MaxTotalMinutes > 0 {
+		query.Set("time", strconv.Itoa(o.MaxTotalMinutes))
+	}
+
+	if o.Tag != "" {
+		query.Set("tag", o.Tag)
+	}
+
+	return "/recipes?" + query.Encode()
+}
+
+// limit returns the most recipes that may be returned for the options.
+func (o RecipeListOptions) limit() int {
+	if o.Limit <= 0 || o.Limit > recipeListLimit {
+		return recipeListLimit
+	}
+
+	return o.Limit
+}
+
+// filtersLabels reports if the options filter recipes by their allergen and diet labels.
+func (o RecipeListOptions) filtersLabels() bool {
+	return o.ExcludeAllergens != 0 || o.Diet != ""
+}
+
+// includes reports if the recipe passes the label filters of the options.
+func (o RecipeListOptions) includes(recipe Recipe) bool {
+	labels := recipe.Labels()
+
+	return labels.Allergens&o.ExcludeAllergens == 0 && labels.Suits(o.Diet)
+}
+
+// recipeListLimit is the most recipes returned by RecipeModel.List.
+const recipeListLimit = 100
+
 type RecipeModel struct {
 	DB     *pgxpool.Pool
 	Logger *slog.Logger
 }
 
+// Add persists a new recipe. If the recipe belongs to a household, its owner must be allowed to
+// edit the household's recipes, and its category must be one of the household's. Otherwise the
+// category must be one of the owner's own. If not, ErrNotFound is returned.
 func (model *RecipeModel) Add(ctx context.Context, recipe Recipe) error {
 	query := `
-INSERT INTO recipes (id, owner, category, title, instructions)
-VALUES ($1, $2, $3, $4, $5)`
+INSERT INTO recipes (
+	id, owner, household, category, title, instructions, servings, ingredients,
+	prep_time, cook_time, total_time, source_name, source_url
+)
+SELECT $2::uuid, $1::text, $3::uuid, $4::uuid, $5::text, $6::text, $7::smallint, $8::text,
+	$9::interval, $10::interval, $11::interval, $12::text, $13::text
+WHERE ` + householdWritable("$3") + `
+	AND ` + categoryVisible("$4") + `
+	AND ` + categoryAssignable("$4", "$3", "$1")
 
-	_, err := model.DB.Exec(
+	result, err := model.DB.Exec(
 		ctx,
 		query,
-		recipe.ID,
 		recipe.Owner,
+		recipe.ID,
+		recipe.Household,
 		recipe.Category,
 		recipe.Title,
 		recipe.Instructions,
+		recipe.Servings,
+		recipe.Ingredients,
+		recipe.PrepTime,
+		recipe.CookTime,
+		recipe.TotalTime,
+		recipe.SourceName,
+		recipe.SourceURL,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert new recipe: %w", err)
 	}
 
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
 	model.Logger.InfoContext(ctx, "Persisted new recipe.", "id", recipe.ID)
 
 	return nil
 }
 
-func (model *RecipeModel) Delete(ctx context.Context, owner string, id uuid.UUID) error {
-	query := `DELETE FROM recipes WHERE owner = $1 AND id = $2`
-	_, err := model.DB.Exec(ctx, query, owner, id)
+// Delete removes a recipe the user is allowed to edit. If there is no such recipe, ErrNotFound is
+// returned.
+func (model *RecipeModel) Delete(ctx context.Context, userID string, id uuid.UUID) error {
+	query := `DELETE FROM recipes AS r WHERE r.id = $2 AND ` + editableBy("r")
+	result, err := model.DB.Exec(ctx, query, userID, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete recipe with ID %v: %w", id, err)
 	}
 
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
 	model.Logger.InfoContext(ctx, "Deleted recipe.", "id", id)
 
 	return nil
 }
 
-func (model *RecipeModel) GetByID(ctx context.Context, owner string, id uuid.UUID) (Recipe, error) {
-	query := `SELECT title, instructions, created_at, updated_at
-		FROM recipes WHERE owner = $1 AND id = $2`
+// GetByID returns a recipe the user is allowed to view.
+func (model *RecipeModel) GetByID(ctx context.Context, userID string, id uuid.UUID) (Recipe, error) {
+	query := recipeSelect + ` WHERE r.id = $2 AND ` + visibleTo("r")
+
+	rows, err := model.DB.Query(ctx, query, userID, id)
+	if err != nil {
+		return Recipe{}, fmt.Errorf("failed to query for recipe with ID %s: %w", id, err)
+	}
 
-	recipe := Recipe{ID: id, Owner: owner}
-	err := model.DB.QueryRow(ctx, query, owner, id).
-		Scan(&recipe.Title, &recipe.Instructions, &recipe.CreatedAt, &recipe.UpdatedAt)
+	recipe, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[Recipe])
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Recipe{}, ErrNotFound
+		}
+
 		return Recipe{}, fmt.Errorf("failed to query for recipe with ID %s: %w", id, err)
 	}
 
 	return recipe, nil
 }
 
-func (model *RecipeModel) List(ctx context.Context, owner string) ([]Recipe, error) {
-	query := `SELECT
-			r.id AS id,
-			r.owner AS owner,
-			category,
-			title,
-			instructions,
-			r.created_at AS created_at,
-			r.updated_at AS updated_at,
-			c.name AS category_name
-		FROM recipes AS r
-			LEFT JOIN categories AS c
-				ON r.category = c.id
-		WHERE r.owner = $1 ORDER BY title LIMIT 100`
-	rows, err := model.DB.Query(ctx, query, owner)
+// List returns the recipes the user is allowed to view.
+func (model *RecipeModel) List(ctx context.Context, userID string, opts RecipeListOptions) ([]Recipe, error) {
+	query := recipeSelect + ` WHERE ` + visibleTo("r")
+	args := []any{userID}
+
+	if opts.FavoritesOnly {
+		query += ` AND rr.favorite`
+	}
+
+	if opts.MaxTotalMinutes > 0 {
+		args = append(args, DurationFromMinutes(opts.MaxTotalMinutes))
+		query += fmt.Sprintf(` AND r.total_time <= $%d`, len(args))
+	}
+
+	if opts.Tag != "" {
+		args = append(args, opts.Tag)
+		query += fmt.Sprintf(` AND EXISTS (SELECT 1 FROM recipe_tags AS t WHERE t.recipe = r.id AND t.tag = $%d)`, len(args))
+	}
+
+	query += ` ORDER BY ` + opts.Sort.orderBy()
+
+	// Labels are derived from ingredient names outside the database, so every recipe must be
+	// retrieved before they can be filtered.
+	if !opts.filtersLabels() {
+		query += fmt.Sprintf(` LIMIT %d OFFSET %d`, opts.limit(), max(opts.Offset, 0))
+	}
+
+	rows, err := model.DB.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list recipes: %w", err)
 	}
@@ -116,30 +400,186 @@ func (model *RecipeModel) List(ctx context.Context, owner string) ([]Recipe, err
 		return nil, fmt.Errorf("failed to map recipe rows to struct: %w", err)
 	}
 
+	if opts.filtersLabels() {
+		filtered := make([]Recipe, 0, min(len(recipes), opts.limit()))
+		skipped := 0
+		for _, recipe := range recipes {
+			if len(filtered) == opts.limit() {
+				break
+			}
+
+			if !opts.includes(recipe) {
+				continue
+			}
+
+			if skipped < opts.Offset {
+				skipped++
+				continue
+			}
+
+			filtered = append(filtered, recipe)
+		}
+
+		recipes = filtered
+	}
+
 	model.Logger.DebugContext(ctx, "Retrieved recipe list from database.", "recipes", recipes)
 
 	return recipes, nil
 }
 
-func (model *RecipeModel) Update(ctx context.Context, recipe Recipe) error {
-	query := `UPDATE recipes
-		SET title = $3, instructions = $4
-		WHERE owner = $1 AND id = $2`
+// copyPrefix is prepended to the titles of duplicated recipes.
+const copyPrefix = "Copy of "
+
+// Duplicate copies a recipe the user is allowed to view into a new recipe owned by the user, along
+// with its tags, label overrides, nutrition matches, and the components the user can view, and
+// returns the new recipe's ID. The copy stays in the original's household and category if the user
+// may add recipes to the household.
+func (model *RecipeModel) Duplicate(ctx context.Context, userID string, id uuid.UUID) (uuid.UUID, error) {
+	copyID := uuid.New()
+
+	query := `INSERT INTO recipes (
+			id, owner, household, category, title, instructions, servings, ingredients,
+			prep_time, cook_time, total_time, source_name, source_url, forked_from
+		)
+		SELECT $3, $1,
+			CASE WHEN ` + householdWritable("r.household") + ` THEN r.household END,
+			CASE WHEN ` + householdWritable("r.household") + ` AND ` + categoryVisible("r.category") + `
+				THEN r.category END,
+			left($4 || r.title, 200), r.instructions, r.servings, r.ingredients,
+			r.prep_time, r.cook_time, r.total_time, r.source_name, r.source_url, r.id
+		FROM recipes AS r
+		WHERE r.id = $2 AND ` + visibleTo("r")
+
+	err := pgx.BeginFunc(ctx, model.DB, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, query, userID, id, copyID, copyPrefix)
+		if err != nil {
+			return err
+		}
+
+		if result.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+
+		batch := &pgx.Batch{}
+		batch.Queue(
+			`INSERT INTO recipe_label_overrides (recipe, label, present)
+				SELECT $2, label, present FROM recipe_label_overrides WHERE recipe = $1`,
+			id,
+			copyID,
+		)
+		batch.Queue(
+			`INSERT INTO recipe_tags (recipe, tag) SELECT $2, tag FROM recipe_tags WHERE recipe = $1`,
+			id,
+			copyID,
+		)
+		batch.Queue(
+			`INSERT INTO recipe_nutrition_matches (recipe, ingredient, food)
+				SELECT $2, ingredient, food FROM recipe_nutrition_matches WHERE recipe = $1`,
+			id,
+			copyID,
+		)
+		batch.Queue(
+			`INSERT INTO recipe_components (recipe, ingredient, component)
+				SELECT $3, rc.ingredient, rc.component
+				FROM recipe_components AS rc
+					JOIN recipes AS r ON r.id = rc.component
+				WHERE rc.recipe = $2 AND `+visibleTo("r"),
+			userID,
+			id,
+			copyID,
+		)
+
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return uuid.UUID{}, err
+		}
+
+		return uuid.UUID{}, fmt.Errorf("failed to duplicate recipe %s: %w", id, err)
+	}
+
+	model.Logger.InfoContext(ctx, "Duplicated recipe.", "original", id, "id", copyID)
+
+	return copyID, nil
+}
+
+// Update modifies a recipe the user is allowed to edit. The user must also be allowed to edit the
+// recipe's new household, if any, and only the recipe's owner or an admin of its household may
+// change its household. The recipe's category must belong to its new household, or to the recipe's
+// owner if it isn't in a household. Otherwise ErrNotFound is returned.
+//
+// The recipe's UpdatedAt is the version of the recipe the changes were made to. If the recipe has
+// been modified since that version, ErrConflict is returned and nothing is changed. A zero
+// UpdatedAt overwrites the recipe regardless of its version.
+func (model *RecipeModel) Update(ctx context.Context, userID string, recipe Recipe) error {
+	query := `UPDATE recipes AS r
+		SET household = $3, category = $4, title = $5, instructions = $6, servings = $7, ingredients = $8,
+			prep_time = $9, cook_time = $10, total_time = $11, source_name = $12, source_url = $13
+		WHERE r.id = $2
+			AND ($14::timestamptz IS NULL OR r.updated_at = $14)
+			AND ` + editableBy("r") + `
+			AND ` + householdChangeableBy("r", "$3") + `
+			AND ` + householdWritable("$3") + `
+			AND ` + categoryVisible("$4") + `
+			AND ` + categoryAssignable("$4", "$3", "r.owner")
+
+	var version pgtype.Timestamptz
+	if !recipe.UpdatedAt.IsZero() {
+		version = pgtype.Timestamptz{Time: recipe.UpdatedAt, Valid: true}
+	}
+
 	result, err := model.DB.Exec(
 		ctx,
 		query,
-		recipe.Owner,
+		userID,
 		recipe.ID,
+		recipe.Household,
+		recipe.Category,
 		recipe.Title,
 		recipe.Instructions,
+		recipe.Servings,
+		recipe.Ingredients,
+		recipe.PrepTime,
+		recipe.CookTime,
+		recipe.TotalTime,
+		recipe.SourceName,
+		recipe.SourceURL,
+		version,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update recipe: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
+		if version.Valid {
+			return model.updateConflict(ctx, userID, recipe)
+		}
+
 		return ErrNotFound
 	}
 
 	return nil
 }
+
+// updateConflict determines why an update of a specific version of a recipe modified nothing. It
+// returns ErrConflict if the user may still edit the recipe but it has been modified since that
+// version, and ErrNotFound otherwise.
+func (model *RecipeModel) updateConflict(ctx context.Context, userID string, recipe Recipe) error {
+	var updatedAt time.Time
+	query := `SELECT r.updated_at FROM recipes AS r WHERE r.id = $2 AND ` + editableBy("r")
+	if err := model.DB.QueryRow(ctx, query, userID, recipe.ID).Scan(&updatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+
+		return fmt.Errorf("failed to query for recipe version: %w", err)
+	}
+
+	if !updatedAt.Equal(recipe.UpdatedAt) {
+		return ErrConflict
+	}
+
+	return ErrNotFound
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RecipeShare is a revocable link granting read-only access to a single recipe.
+type RecipeShare struct {
+	ID        uuid.UUID          `db:"id"`
+	Recipe    uuid.UUID          `db:"recipe"`
+	Token     string             `db:"token"`
+	CreatedAt time.Time          `db:"created_at"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at"`
+}
+
+// URL returns the public URL for the share.
+func (s RecipeShare) URL() string {
+	return "/s/" + s.Token
+}
+
+type ShareModel struct {
+	DB     *pgxpool.Pool
+	Logger *slog.Logger
+}
+
+// Create persists a new share link. The user must be allowed to edit the recipe being shared, or
+// ErrNotFound is returned.
+func (model *ShareModel) Create(ctx context.Context, userID string, share RecipeShare) error {
+	query := `INSERT INTO recipe_shares (id, recipe, token, expires_at)
+		SELECT $2::uuid, r.id, $4::text, $5::timestamptz FROM recipes AS r
+		WHERE r.id = $3 AND ` + editableBy("r")
+	result, err := model.DB.Exec(ctx, query, userID, share.ID, share.Recipe, share.Token, share.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert recipe share: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Created recipe share.", "id", share.ID, "recipe", share.Recipe)
+
+	return nil
+}
+
+// ListForRecipe returns the active (unrevoked and unexpired) shares for a recipe.
+func (model *ShareModel) ListForRecipe(ctx context.Context, userID string, recipeID uuid.UUID) ([]RecipeShare, error) {
+	query := `SELECT s.id, s.recipe, s.token, s.created_at, s.expires_at
+		FROM recipe_shares AS s
+			JOIN recipes AS r ON s.recipe = r.id
+		WHERE r.id = $2
+			AND ` + editableBy("r") + `
+			AND s.revoked_at IS NULL
+			AND (s.expires_at IS NULL OR s.expires_at > now())
+		ORDER BY s.created_at`
+	rows, err := model.DB.Query(ctx, query, userID, recipeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recipe shares: %w", err)
+	}
+	defer rows.Close()
+
+	shares, err := pgx.CollectRows(rows, pgx.RowToStructByName[RecipeShare])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map share rows to struct: %w", err)
+	}
+
+	return shares, nil
+}
+
+// Revoke disables one of a recipe's share links so that it can no longer be used to view the
+// recipe. ErrNotFound is returned if the share doesn't belong to the recipe.
+func (model *ShareModel) Revoke(ctx context.Context, userID string, recipeID uuid.UUID, id uuid.UUID) error {
+	query := `UPDATE recipe_shares AS s
+		SET revoked_at = now()
+		FROM recipes AS r
+		WHERE s.recipe = r.id AND s.id = $2 AND s.recipe = $3 AND s.revoked_at IS NULL AND ` + editableBy("r")
+	result, err := model.DB.Exec(ctx, query, userID, id, recipeID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke recipe share: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Revoked recipe share.", "id", id, "recipe", recipeID)
+
+	return nil
+}
+
+// GetRecipe returns the recipe referenced by an active share token.
+func (model *ShareModel) GetRecipe(ctx context.Context, token string) (Recipe, error) {
+	query := `SELECT r.id, r.title, r.ingredients, r.instructions, r.servings, r.created_at, r.updated_at,
+			r.prep_time, r.cook_time, r.total_time, r.source_name, r.source_url,
+			COALESCE(
+				(SELECT jsonb_object_agg(o.label, o.present) FROM recipe_label_overrides AS o WHERE o.recipe = r.id),
+				'{}'::jsonb
+			)
+		FROM recipe_shares AS s
+			JOIN recipes AS r ON s.recipe = r.id
+		WHERE s.token = $1
+			AND s.revoked_at IS NULL
+			AND (s.expires_at IS NULL OR s.expires_at > now())`
+
+	var recipe Recipe
+	err := model.DB.QueryRow(ctx, query, token).
+		Scan(
+			&recipe.ID,
+			&recipe.Title,
+			&recipe.Ingredients,
+			&recipe.Instructions,
+			&recipe.Servings,
+			&recipe.CreatedAt,
+			&recipe.UpdatedAt,
+			&recipe.PrepTime,
+			&recipe.CookTime,
+			&recipe.TotalTime,
+			&recipe.SourceName,
+			&recipe.SourceURL,
+			&recipe.LabelOverrides,
+		)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Recipe{}, ErrNotFound
+		}
+
+		return Recipe{}, fmt.Errorf("failed to query for shared recipe: %w", err)
+	}
+
+	return recipe, nil
+}
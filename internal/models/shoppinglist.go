@@ -0,0 +1,180 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/ingredients"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ShoppingListItem is a single entry on a user's shopping list.
+type ShoppingListItem struct {
+	ID       uuid.UUID     `db:"id"`
+	Owner    string        `db:"owner"`
+	Name     string        `db:"name"`
+	Quantity pgtype.Float8 `db:"quantity"`
+	// Unit is the canonical name of the unit the quantity is measured in, or blank for a bare count.
+	Unit      string    `db:"unit"`
+	Checked   bool      `db:"checked"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// NewShoppingListItem creates an unchecked item for the user from a parsed ingredient.
+func NewShoppingListItem(userID string, ingredient ingredients.Ingredient) ShoppingListItem {
+	item := ShoppingListItem{
+		ID:    uuid.New(),
+		Owner: userID,
+		Name:  ingredient.Name,
+		Unit:  ingredient.Unit.Name,
+	}
+
+	if ingredient.HasQuantity() {
+		item.Quantity = pgtype.Float8{Float64: ingredient.Quantity, Valid: true}
+	}
+
+	return item
+}
+
+// Ingredient returns the item as an ingredient so it may be formatted or combined with others.
+func (i ShoppingListItem) Ingredient() ingredients.Ingredient {
+	unit, _ := ingredients.LookupUnit(i.Unit)
+
+	return ingredients.Ingredient{
+		Quantity: i.Quantity.Float64,
+		Unit:     unit,
+		Name:     i.Name,
+	}
+}
+
+// String returns the item formatted as it would appear in an ingredient list.
+func (i ShoppingListItem) String() string {
+	return i.Ingredient().String()
+}
+
+type ShoppingListModel struct {
+	DB     *pgxpool.Pool
+	Logger *slog.Logger
+}
+
+const shoppingListSelect = `SELECT id, owner, name, quantity, unit, checked, created_at FROM shopping_list_items`
+
+// Add appends items to the user's shopping list.
+func (model *ShoppingListModel) Add(ctx context.Context, userID string, items []ShoppingListItem) error {
+	query := `INSERT INTO shopping_list_items (id, owner, name, quantity, unit) VALUES ($1, $2, $3, $4, $5)`
+
+	batch := &pgx.Batch{}
+	for _, item := range items {
+		batch.Queue(query, item.ID, userID, item.Name, item.Quantity, item.Unit)
+	}
+
+	err := pgx.BeginFunc(ctx, model.DB, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert shopping list items: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Added items to shopping list.", "count", len(items))
+
+	return nil
+}
+
+// Delete removes an item from the user's shopping list.
+func (model *ShoppingListModel) Delete(ctx context.Context, userID string, id uuid.UUID) error {
+	query := `DELETE FROM shopping_list_items WHERE owner = $1 AND id = $2`
+	result, err := model.DB.Exec(ctx, query, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete shopping list item: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteChecked removes every checked item from the user's shopping list.
+func (model *ShoppingListModel) DeleteChecked(ctx context.Context, userID string) error {
+	query := `DELETE FROM shopping_list_items WHERE owner = $1 AND checked`
+	result, err := model.DB.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete checked shopping list items: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Cleared checked shopping list items.", "count", result.RowsAffected())
+
+	return nil
+}
+
+// Get returns an item from the user's shopping list.
+func (model *ShoppingListModel) Get(ctx context.Context, userID string, id uuid.UUID) (ShoppingListItem, error) {
+	rows, err := model.DB.Query(ctx, shoppingListSelect+` WHERE owner = $1 AND id = $2`, userID, id)
+	if err != nil {
+		return ShoppingListItem{}, fmt.Errorf("failed to query for shopping list item: %w", err)
+	}
+
+	item, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[ShoppingListItem])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ShoppingListItem{}, ErrNotFound
+		}
+
+		return ShoppingListItem{}, fmt.Errorf("failed to query for shopping list item: %w", err)
+	}
+
+	return item, nil
+}
+
+// List returns the user's shopping list with unchecked items first.
+func (model *ShoppingListModel) List(ctx context.Context, userID string) ([]ShoppingListItem, error) {
+	rows, err := model.DB.Query(ctx, shoppingListSelect+` WHERE owner = $1 ORDER BY checked, created_at, name`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shopping list items: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := pgx.CollectRows(rows, pgx.RowToStructByName[ShoppingListItem])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map shopping list rows to struct: %w", err)
+	}
+
+	return items, nil
+}
+
+// SetChecked marks an item on the user's shopping list as checked off or not.
+func (model *ShoppingListModel) SetChecked(ctx context.Context, userID string, id uuid.UUID, checked bool) error {
+	query := `UPDATE shopping_list_items SET checked = $3 WHERE owner = $1 AND id = $2`
+	result, err := model.DB.Exec(ctx, query, userID, id, checked)
+	if err != nil {
+		return fmt.Errorf("failed to check shopping list item: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Update changes the name and amount of an item on the user's shopping list.
+func (model *ShoppingListModel) Update(ctx context.Context, userID string, item ShoppingListItem) error {
+	query := `UPDATE shopping_list_items SET name = $3, quantity = $4, unit = $5 WHERE owner = $1 AND id = $2`
+	result, err := model.DB.Exec(ctx, query, userID, item.ID, item.Name, item.Quantity, item.Unit)
+	if err != nil {
+		return fmt.Errorf("failed to update shopping list item: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Scopes that may be granted to a personal access token.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// TokenScopes lists every scope that may be granted to a personal access token.
+var TokenScopes = []string{ScopeRead, ScopeWrite}
+
+// AccessToken is a personal access token that authenticates API requests on behalf of its owner.
+// Only a hash of the token's secret is stored, so the secret itself can only be shown when the
+// token is created.
+type AccessToken struct {
+	ID         uuid.UUID          `db:"id"`
+	Owner      string             `db:"owner"`
+	Name       string             `db:"name"`
+	Scopes     []string           `db:"scopes"`
+	CreatedAt  time.Time          `db:"created_at"`
+	LastUsedAt pgtype.Timestamptz `db:"last_used_at"`
+}
+
+// HasScope returns true if the token has been granted the provided scope.
+func (t AccessToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// hashTokenSecret returns the value stored in place of a token's secret.
+func hashTokenSecret(secret string) []byte {
+	hash := sha256.Sum256([]byte(secret))
+
+	return hash[:]
+}
+
+type TokenModel struct {
+	DB     *pgxpool.Pool
+	Logger *slog.Logger
+}
+
+// Create persists a new access token identified by the provided secret.
+func (model *TokenModel) Create(ctx context.Context, token AccessToken, secret string) error {
+	query := `INSERT INTO access_tokens (id, owner, name, token_hash, scopes) VALUES ($1, $2, $3, $4, $5)`
+	_, err := model.DB.Exec(ctx, query, token.ID, token.Owner, token.Name, hashTokenSecret(secret), token.Scopes)
+	if err != nil {
+		return fmt.Errorf("failed to insert access token: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Created access token.", "id", token.ID, "owner", token.Owner)
+
+	return nil
+}
+
+// List returns the user's unrevoked access tokens.
+func (model *TokenModel) List(ctx context.Context, userID string) ([]AccessToken, error) {
+	query := `SELECT id, owner, name, scopes, created_at, last_used_at
+		FROM access_tokens
+		WHERE owner = $1 AND revoked_at IS NULL
+		ORDER BY created_at`
+	rows, err := model.DB.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens, err := pgx.CollectRows(rows, pgx.RowToStructByName[AccessToken])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map access token rows to struct: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Revoke disables one of the user's access tokens so that it can no longer authenticate requests.
+func (model *TokenModel) Revoke(ctx context.Context, userID string, id uuid.UUID) error {
+	query := `UPDATE access_tokens SET revoked_at = now() WHERE id = $2 AND owner = $1 AND revoked_at IS NULL`
+	result, err := model.DB.Exec(ctx, query, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Revoked access token.", "id", id)
+
+	return nil
+}
+
+// Authenticate returns the unrevoked access token identified by the provided secret and records
+// that it was used. If there is no such token, ErrNotFound is returned.
+func (model *TokenModel) Authenticate(ctx context.Context, secret string) (AccessToken, error) {
+	query := `UPDATE access_tokens SET last_used_at = now()
+		WHERE token_hash = $1 AND revoked_at IS NULL
+		RETURNING id, owner, name, scopes, created_at, last_used_at`
+	rows, err := model.DB.Query(ctx, query, hashTokenSecret(secret))
+	if err != nil {
+		return AccessToken{}, fmt.Errorf("failed to authenticate access token: %w", err)
+	}
+
+	token, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[AccessToken])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return AccessToken{}, ErrNotFound
+		}
+
+		return AccessToken{}, fmt.Errorf("failed to map access token row to struct: %w", err)
+	}
+
+	return token, nil
+}
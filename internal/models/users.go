@@ -2,10 +2,12 @@ package models
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -63,3 +65,59 @@ func (model *UserModel) UpdateName(ctx context.Context, id, name string) error {
 
 	return nil
 }
+
+// FeedEnabled reports if the user has a recipe feed. Only a hash of the feed's token is stored, so
+// the feed's URL can't be shown again after it is created.
+func (model *UserModel) FeedEnabled(ctx context.Context, id string) (bool, error) {
+	query := `SELECT feed_token_hash IS NOT NULL FROM "users" WHERE id = $1`
+
+	var enabled bool
+	if err := model.DB.QueryRow(ctx, query, id).Scan(&enabled); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, ErrNotFound
+		}
+
+		return false, fmt.Errorf("failed to query for feed token: %w", err)
+	}
+
+	return enabled, nil
+}
+
+// FeedOwner returns the ID of the user whose recipe feed has the token.
+func (model *UserModel) FeedOwner(ctx context.Context, token string) (string, error) {
+	query := `SELECT id FROM "users" WHERE feed_token_hash = $1`
+
+	var id string
+	if err := model.DB.QueryRow(ctx, query, hashTokenSecret(token)).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotFound
+		}
+
+		return "", fmt.Errorf("failed to query for feed owner: %w", err)
+	}
+
+	return id, nil
+}
+
+// SetFeedToken changes the token of the user's recipe feed. A blank token disables the feed.
+// Either way, the feed's previous URL stops working.
+func (model *UserModel) SetFeedToken(ctx context.Context, id string, token string) error {
+	var hash []byte
+	if token != "" {
+		hash = hashTokenSecret(token)
+	}
+
+	query := `UPDATE "users" SET feed_token_hash = $2 WHERE id = $1`
+	result, err := model.DB.Exec(ctx, query, id, hash)
+	if err != nil {
+		return fmt.Errorf("failed to update feed token: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Updated user feed token.", "id", id, "enabled", token != "")
+
+	return nil
+}
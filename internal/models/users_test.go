@@ -104,3 +104,41 @@ func Test_UserModel_UpdateName(t *testing.T) {
 		})
 	}
 }
+
+func Test_UserModel_FeedToken(t *testing.T) {
+	markAsIntegrationTest(t)
+
+	ctx := context.Background()
+	model := newUserModel(t)
+
+	enabled, err := model.FeedEnabled(ctx, "1")
+	assert.NilError(t, err)
+	assert.Equal(t, false, enabled)
+
+	_, err = model.FeedOwner(ctx, "feed-token")
+	assert.Equal(t, models.ErrNotFound, err)
+
+	assert.NilError(t, model.SetFeedToken(ctx, "1", "feed-token"))
+
+	enabled, err = model.FeedEnabled(ctx, "1")
+	assert.NilError(t, err)
+	assert.Equal(t, true, enabled)
+
+	owner, err := model.FeedOwner(ctx, "feed-token")
+	assert.NilError(t, err)
+	assert.Equal(t, "1", owner)
+
+	assert.NilError(t, model.SetFeedToken(ctx, "1", ""))
+
+	enabled, err = model.FeedEnabled(ctx, "1")
+	assert.NilError(t, err)
+	assert.Equal(t, false, enabled)
+
+	_, err = model.FeedOwner(ctx, "feed-token")
+	assert.Equal(t, models.ErrNotFound, err)
+
+	_, err = model.FeedOwner(ctx, "")
+	assert.Equal(t, models.ErrNotFound, err)
+
+	assert.Equal(t, models.ErrNotFound, model.SetFeedToken(ctx, "2", "other-token"))
+}
@@ -0,0 +1,268 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Events that may be sent to a webhook.
+const (
+	EventRecipeCreated   = "recipe.created"
+	EventRecipeUpdated   = "recipe.updated"
+	EventRecipeDeleted   = "recipe.deleted"
+	EventCategoryCreated = "category.created"
+	EventCategoryUpdated = "category.updated"
+	EventCategoryDeleted = "category.deleted"
+)
+
+// WebhookEvents lists every event that may be sent to a webhook.
+var WebhookEvents = []string{
+	EventRecipeCreated,
+	EventRecipeUpdated,
+	EventRecipeDeleted,
+	EventCategoryCreated,
+	EventCategoryUpdated,
+	EventCategoryDeleted,
+}
+
+// Webhook is a URL that receives a signed request each time one of the subscribed events happens
+// to the owner's recipes or categories.
+type Webhook struct {
+	ID        uuid.UUID `db:"id"`
+	Owner     string    `db:"owner"`
+	URL       string    `db:"url"`
+	Secret    string    `db:"secret"`
+	Events    []string  `db:"events"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// WebhookDelivery is a single event sent, or to be sent, to a webhook.
+type WebhookDelivery struct {
+	ID             uuid.UUID          `db:"id"`
+	Webhook        uuid.UUID          `db:"webhook"`
+	Event          string             `db:"event"`
+	Payload        []byte             `db:"payload"`
+	Attempts       int                `db:"attempts"`
+	NextAttemptAt  pgtype.Timestamptz `db:"next_attempt_at"`
+	DeliveredAt    pgtype.Timestamptz `db:"delivered_at"`
+	ResponseStatus pgtype.Int2        `db:"response_status"`
+	Error          string             `db:"error"`
+	CreatedAt      time.Time          `db:"created_at"`
+}
+
+// Status returns "delivered" for successful deliveries, "failed" for deliveries that will not be
+// retried, and "pending" for all others.
+func (d WebhookDelivery) Status() string {
+	switch {
+	case d.DeliveredAt.Valid:
+		return "delivered"
+	case !d.NextAttemptAt.Valid:
+		return "failed"
+	default:
+		return "pending"
+	}
+}
+
+// PendingWebhookDelivery is a delivery that is due to be sent, along with the details of the
+// webhook required to send it.
+type PendingWebhookDelivery struct {
+	ID       uuid.UUID `db:"id"`
+	Event    string    `db:"event"`
+	Payload  []byte    `db:"payload"`
+	Attempts int       `db:"attempts"`
+	URL      string    `db:"url"`
+	Secret   string    `db:"secret"`
+}
+
+// WebhookAttempt is the outcome of an attempt to send a delivery.
+type WebhookAttempt struct {
+	// ResponseStatus is the status code of the webhook's response, if one was received.
+	ResponseStatus pgtype.Int2
+	// Error describes why the attempt failed.
+	Error string
+	// Delivered is true if the webhook accepted the delivery.
+	Delivered bool
+	// NextAttemptAt is the time to retry a failed attempt. If it is not valid, the delivery is
+	// abandoned.
+	NextAttemptAt pgtype.Timestamptz
+}
+
+type WebhookModel struct {
+	DB     *pgxpool.Pool
+	Logger *slog.Logger
+}
+
+const webhookSelect = `SELECT id, owner, url, secret, events, created_at FROM webhooks`
+
+// Create persists a new webhook.
+func (model *WebhookModel) Create(ctx context.Context, webhook Webhook) error {
+	query := `INSERT INTO webhooks (id, owner, url, secret, events) VALUES ($1, $2, $3, $4, $5)`
+	_, err := model.DB.Exec(ctx, query, webhook.ID, webhook.Owner, webhook.URL, webhook.Secret, webhook.Events)
+	if err != nil {
+		return fmt.Errorf("failed to insert webhook: %w", err)
+	}
+
+	model.Logger.InfoContext(ctx, "Created webhook.", "id", webhook.ID, "owner", webhook.Owner)
+
+	return nil
+}
+
+// Delete removes one of the user's webhooks along with its deliveries.
+func (model *WebhookModel) Delete(ctx context.Context, userID string, id uuid.UUID) error {
+	result, err := model.DB.Exec(ctx, `DELETE FROM webhooks WHERE id = $2 AND owner = $1`, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	model.Logger.InfoContext(ctx, "Deleted webhook.", "id", id)
+
+	return nil
+}
+
+// Get returns one of the user's webhooks.
+func (model *WebhookModel) Get(ctx context.Context, userID string, id uuid.UUID) (Webhook, error) {
+	rows, err := model.DB.Query(ctx, webhookSelect+` WHERE owner = $1 AND id = $2`, userID, id)
+	if err != nil {
+		return Webhook{}, fmt.Errorf("failed to query for webhook: %w", err)
+	}
+
+	webhook, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[Webhook])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Webhook{}, ErrNotFound
+		}
+
+		return Webhook{}, fmt.Errorf("failed to map webhook row to struct: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// List returns the user's webhooks.
+func (model *WebhookModel) List(ctx context.Context, userID string) ([]Webhook, error) {
+	rows, err := model.DB.Query(ctx, webhookSelect+` WHERE owner = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks, err := pgx.CollectRows(rows, pgx.RowToStructByName[Webhook])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map webhook rows to struct: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// Deliveries returns the most recent deliveries to one of the user's webhooks, newest first.
+func (model *WebhookModel) Deliveries(ctx context.Context, userID string, webhookID uuid.UUID, limit int) ([]WebhookDelivery, error) {
+	query := `SELECT d.id, d.webhook, d.event, d.payload, d.attempts, d.next_attempt_at, d.delivered_at,
+			d.response_status, d.error, d.created_at
+		FROM webhook_deliveries AS d
+			JOIN webhooks AS w ON d.webhook = w.id
+		WHERE w.owner = $1 AND w.id = $2
+		ORDER BY d.created_at DESC
+		LIMIT $3`
+	rows, err := model.DB.Query(ctx, query, userID, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries, err := pgx.CollectRows(rows, pgx.RowToStructByName[WebhookDelivery])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map webhook delivery rows to struct: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// Enqueue records a delivery of the event to each webhook subscribed to it whose owner can view
+// the object the event is about, given the object's owner and household. As with visibleTo, an
+// object in a household is visible to each of its members, and any other object only to its owner.
+// The deliveries are sent in the background.
+func (model *WebhookModel) Enqueue(ctx context.Context, owner string, household *uuid.UUID, event string, payload []byte) error {
+	query := `INSERT INTO webhook_deliveries (id, webhook, event, payload)
+		SELECT gen_random_uuid(), w.id, $3, $4::jsonb FROM webhooks AS w
+		WHERE $3 = ANY (w.events)
+			AND (($2::uuid IS NULL AND w.owner = $1)
+				OR EXISTS (SELECT 1 FROM household_members AS hm
+					WHERE hm.household = $2 AND hm.member = w.owner))`
+	result, err := model.DB.Exec(ctx, query, owner, household, event, payload)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+
+	if result.RowsAffected() > 0 {
+		model.Logger.DebugContext(ctx, "Enqueued webhook deliveries.", "event", event, "count", result.RowsAffected())
+	}
+
+	return nil
+}
+
+// ClaimDue returns up to limit deliveries that are due to be sent. Claimed deliveries are not
+// returned again until the lease expires, so an attempt that is interrupted is eventually retried,
+// and several processes may claim deliveries at once.
+func (model *WebhookModel) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]PendingWebhookDelivery, error) {
+	query := `WITH due AS (
+			SELECT id FROM webhook_deliveries
+			WHERE next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries AS d
+		SET next_attempt_at = now() + make_interval(secs => $2)
+		FROM due, webhooks AS w
+		WHERE d.id = due.id AND d.webhook = w.id
+		RETURNING d.id, d.event, d.payload, d.attempts, w.url, w.secret`
+	rows, err := model.DB.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries, err := pgx.CollectRows(rows, pgx.RowToStructByName[PendingWebhookDelivery])
+	if err != nil {
+		return nil, fmt.Errorf("failed to map webhook delivery rows to struct: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RecordAttempt stores the outcome of an attempt to send a delivery.
+func (model *WebhookModel) RecordAttempt(ctx context.Context, id uuid.UUID, attempt WebhookAttempt) error {
+	query := `UPDATE webhook_deliveries
+		SET attempts = attempts + 1,
+			response_status = $2,
+			error = $3,
+			delivered_at = CASE WHEN $4 THEN now() END,
+			next_attempt_at = CASE WHEN $4 THEN NULL ELSE $5::timestamptz END
+		WHERE id = $1`
+	_, err := model.DB.Exec(
+		ctx,
+		query,
+		id,
+		attempt.ResponseStatus,
+		attempt.Error,
+		attempt.Delivered,
+		attempt.NextAttemptAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery attempt: %w", err)
+	}
+
+	return nil
+}
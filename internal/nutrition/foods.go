@@ -0,0 +1,163 @@
+package nutrition
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cdriehuys/recipes/internal/ingredients"
+)
+
+// foodsCSV holds approximate nutrition facts for common ingredients, compiled from publicly
+// available food composition tables. Each row describes one food with values per 100 g.
+//
+//go:embed foods.csv
+var foodsCSV string
+
+// Food is an entry of the bundled nutrition dataset.
+type Food struct {
+	// Name is the canonical name of the food.
+	Name string
+	// Aliases are other names the food commonly appears under in ingredient lists.
+	Aliases []string
+	// GramsPerML is the density used to convert volumes of the food to weights. It is zero if the
+	// food is not measured by volume.
+	GramsPerML float64
+	// GramsPerPiece is the weight of a single piece of the food, such as one egg or one clove of
+	// garlic. It is zero if the food is not counted in pieces.
+	GramsPerPiece float64
+	// Per100g holds the nutrients in 100 g of the food.
+	Per100g Nutrients
+}
+
+// Grams returns the weight of an ingredient's amount of the food. The returned boolean is false if
+// the ingredient has no amount or its unit cannot be converted to a weight for this food.
+func (f Food) Grams(ingredient ingredients.Ingredient) (float64, bool) {
+	if !ingredient.HasQuantity() {
+		return 0, false
+	}
+
+	switch ingredient.Unit.Dimension {
+	case ingredients.Mass:
+		return ingredient.Quantity * ingredient.Unit.Factor, true
+	case ingredients.Volume:
+		if f.GramsPerML > 0 {
+			return ingredient.Quantity * ingredient.Unit.Factor * f.GramsPerML, true
+		}
+	default:
+		// Discrete units, such as cans or cloves, are treated as a number of pieces.
+		if f.GramsPerPiece > 0 {
+			return ingredient.Quantity * f.GramsPerPiece, true
+		}
+	}
+
+	return 0, false
+}
+
+var (
+	foods       []Food
+	foodsByName map[string]Food
+	foodsByKey  map[string]Food
+)
+
+func init() {
+	var err error
+	foods, err = parseFoods(foodsCSV)
+	if err != nil {
+		panic(err)
+	}
+
+	foodsByName = make(map[string]Food, len(foods))
+	foodsByKey = make(map[string]Food)
+	for _, food := range foods {
+		foodsByName[food.Name] = food
+
+		for _, name := range append([]string{food.Name}, food.Aliases...) {
+			foodsByKey[ingredients.Key(name)] = food
+		}
+	}
+}
+
+func parseFoods(data string) ([]Food, error) {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nutrition dataset: %w", err)
+	}
+
+	parsed := make([]Food, 0, len(records))
+	for i, record := range records[1:] {
+		values := make([]float64, 0, len(record)-2)
+		for _, field := range record[2:] {
+			value, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value on row %d of nutrition dataset: %w", i+2, err)
+			}
+
+			values = append(values, value)
+		}
+
+		var aliases []string
+		if record[1] != "" {
+			aliases = strings.Split(record[1], "|")
+		}
+
+		parsed = append(parsed, Food{
+			Name:          record[0],
+			Aliases:       aliases,
+			GramsPerML:    values[0],
+			GramsPerPiece: values[1],
+			Per100g: Nutrients{
+				Calories:      values[2],
+				Protein:       values[3],
+				Fat:           values[4],
+				Carbohydrates: values[5],
+				Fiber:         values[6],
+				Sugar:         values[7],
+				Sodium:        values[8],
+				Potassium:     values[9],
+				Calcium:       values[10],
+				Iron:          values[11],
+			},
+		})
+	}
+
+	sort.Slice(parsed, func(i, j int) bool { return parsed[i].Name < parsed[j].Name })
+
+	return parsed, nil
+}
+
+// Foods returns every food in the dataset, sorted by name.
+func Foods() []Food {
+	return foods
+}
+
+// FoodByName returns the food with the provided canonical name.
+func FoodByName(name string) (Food, bool) {
+	food, ok := foodsByName[name]
+	return food, ok
+}
+
+// Lookup finds the food best matching an ingredient name. Exact matches of a food's name or aliases
+// are preferred. Otherwise the longest name the ingredient ends with is used, so that "shredded
+// sharp cheddar cheese" matches "cheddar cheese" rather than "cheese".
+func Lookup(name string) (Food, bool) {
+	key := ingredients.Key(name)
+	if food, ok := foodsByKey[key]; ok {
+		return food, true
+	}
+
+	var (
+		best      Food
+		bestMatch string
+	)
+	for candidate, food := range foodsByKey {
+		if len(candidate) > len(bestMatch) && ingredients.Matches(candidate, key) {
+			best, bestMatch = food, candidate
+		}
+	}
+
+	return best, bestMatch != ""
+}
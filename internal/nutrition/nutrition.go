@@ -0,0 +1,140 @@
+// Package nutrition estimates the nutrition facts of recipes from their ingredient lists using a
+// bundled dataset of common foods.
+package nutrition
+
+import "github.com/cdriehuys/recipes/internal/ingredients"
+
+// Ignore is used in place of a food name to exclude an ingredient from nutrition facts, such as
+// "salt to taste".
+const Ignore = "ignore"
+
+// Nutrients holds the nutrition facts of an amount of food. Macronutrients are measured in grams
+// and minerals in milligrams.
+type Nutrients struct {
+	Calories      float64
+	Protein       float64
+	Fat           float64
+	Carbohydrates float64
+	Fiber         float64
+	Sugar         float64
+	Sodium        float64
+	Potassium     float64
+	Calcium       float64
+	Iron          float64
+}
+
+// Add returns the sum of two sets of nutrients.
+func (n Nutrients) Add(other Nutrients) Nutrients {
+	return Nutrients{
+		Calories:      n.Calories + other.Calories,
+		Protein:       n.Protein + other.Protein,
+		Fat:           n.Fat + other.Fat,
+		Carbohydrates: n.Carbohydrates + other.Carbohydrates,
+		Fiber:         n.Fiber + other.Fiber,
+		Sugar:         n.Sugar + other.Sugar,
+		Sodium:        n.Sodium + other.Sodium,
+		Potassium:     n.Potassium + other.Potassium,
+		Calcium:       n.Calcium + other.Calcium,
+		Iron:          n.Iron + other.Iron,
+	}
+}
+
+// Scale returns the nutrients multiplied by the provided factor.
+func (n Nutrients) Scale(factor float64) Nutrients {
+	return Nutrients{
+		Calories:      n.Calories * factor,
+		Protein:       n.Protein * factor,
+		Fat:           n.Fat * factor,
+		Carbohydrates: n.Carbohydrates * factor,
+		Fiber:         n.Fiber * factor,
+		Sugar:         n.Sugar * factor,
+		Sodium:        n.Sodium * factor,
+		Potassium:     n.Potassium * factor,
+		Calcium:       n.Calcium * factor,
+		Iron:          n.Iron * factor,
+	}
+}
+
+// Reason explains why an ingredient could not be included in nutrition facts.
+type Reason string
+
+const (
+	ReasonNoFood   Reason = "No matching food"
+	ReasonNoAmount Reason = "Amount could not be converted to a weight"
+)
+
+// Match is an ingredient that contributed to nutrition facts.
+type Match struct {
+	Ingredient ingredients.Ingredient
+	Food       Food
+	Grams      float64
+}
+
+// Unmatched is an ingredient left out of nutrition facts.
+type Unmatched struct {
+	Ingredient ingredients.Ingredient
+	Reason     Reason
+}
+
+// Facts are the estimated nutrition facts of an ingredient list.
+type Facts struct {
+	Total     Nutrients
+	Matched   []Match
+	Unmatched []Unmatched
+	// Ignored holds the ingredients deliberately left out of the facts.
+	Ignored []ingredients.Ingredient
+}
+
+// PerServing returns the nutrients in a single serving when the ingredients make the provided
+// number of servings.
+func (f Facts) PerServing(servings int) Nutrients {
+	if servings < 1 {
+		return f.Total
+	}
+
+	return f.Total.Scale(1 / float64(servings))
+}
+
+// Calculate estimates the nutrition facts of an ingredient list. Overrides replace the automatic
+// match of an ingredient, and are keyed by the ingredient's normalized name as given by
+// ingredients.Key. Each override is either the name of a food in the dataset or Ignore.
+func Calculate(list []ingredients.Ingredient, overrides map[string]string) Facts {
+	var facts Facts
+
+	for _, ingredient := range list {
+		if ingredient.Name == "" {
+			continue
+		}
+
+		var (
+			food Food
+			ok   bool
+		)
+		if override, found := overrides[ingredients.Key(ingredient.Name)]; found {
+			if override == Ignore {
+				facts.Ignored = append(facts.Ignored, ingredient)
+				continue
+			}
+
+			food, ok = FoodByName(override)
+		} else {
+			food, ok = Lookup(ingredient.Name)
+		}
+
+		if !ok {
+			facts.Unmatched = append(facts.Unmatched, Unmatched{Ingredient: ingredient, Reason: ReasonNoFood})
+			continue
+		}
+
+		grams, ok := food.Grams(ingredient)
+		if !ok {
+			facts.Unmatched = append(facts.Unmatched, Unmatched{Ingredient: ingredient, Reason: ReasonNoAmount})
+			continue
+		}
+
+		facts.Matched = append(facts.Matched, Match{Ingredient: ingredient, Food: food, Grams: grams})
+		facts.Total = facts.Total.Add(food.Per100g.Scale(grams / 100))
+	}
+
+	return facts
+}
@@ -0,0 +1,119 @@
+package nutrition_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/ingredients"
+	"github.com/cdriehuys/recipes/internal/nutrition"
+)
+
+func TestFoods(t *testing.T) {
+	foods := nutrition.Foods()
+	if len(foods) < 100 {
+		t.Errorf("Expected at least 100 foods, got %d", len(foods))
+	}
+
+	// Every name and alias must identify exactly one food.
+	seen := make(map[string]string)
+	for _, food := range foods {
+		for _, name := range append([]string{food.Name}, food.Aliases...) {
+			key := ingredients.Key(name)
+			if other, ok := seen[key]; ok {
+				t.Errorf("%q is used by both %q and %q", key, other, food.Name)
+			}
+
+			seen[key] = food.Name
+		}
+
+		if food.GramsPerML < 0 || food.GramsPerPiece < 0 || food.Per100g.Calories < 0 {
+			t.Errorf("%q has a negative value", food.Name)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	testCases := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{name: "flour", want: "all-purpose flour", wantOK: true},
+		{name: "Eggs", want: "egg", wantOK: true},
+		{name: "large eggs", want: "egg", wantOK: true},
+		{name: "bread flour", want: "all-purpose flour", wantOK: true},
+		{name: "whole wheat flour", want: "whole wheat flour", wantOK: true},
+		{name: "shredded sharp cheddar cheese", want: "cheddar cheese", wantOK: true},
+		{name: "cream cheese", want: "cream cheese", wantOK: true},
+		{name: "peanut butter", want: "peanut butter", wantOK: true},
+		{name: "red pepper", want: "bell pepper", wantOK: true},
+		{name: "freshly ground black pepper", want: "black pepper", wantOK: true},
+		{name: "dragon fruit", wantOK: false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			food, ok := nutrition.Lookup(tt.name)
+
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, food.Name)
+		})
+	}
+}
+
+func TestFood_Grams(t *testing.T) {
+	testCases := []struct {
+		line   string
+		want   string
+		wantOK bool
+	}{
+		{line: "200 g flour", want: "200", wantOK: true},
+		{line: "1 lb ground beef", want: "454", wantOK: true},
+		{line: "1 cup flour", want: "125", wantOK: true},
+		{line: "2 eggs", want: "100", wantOK: true},
+		{line: "3 cloves garlic", want: "9", wantOK: true},
+		{line: "1 can black beans", want: "240", wantOK: true},
+		{line: "salt to taste", wantOK: false},
+		{line: "1 cup ground beef", wantOK: false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.line, func(t *testing.T) {
+			ingredient := ingredients.Parse(tt.line)
+			food, _ := nutrition.Lookup(ingredient.Name)
+
+			grams, ok := food.Grams(ingredient)
+
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.want, fmt.Sprintf("%.0f", grams))
+			}
+		})
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	list := ingredients.ParseList("200 g all-purpose flour\n2 eggs\n1 cup water\nsalt to taste\n1 dragon fruit\n2 tbsp mystery sauce")
+	overrides := map[string]string{
+		"salt to taste": nutrition.Ignore,
+		"mystery sauce": "soy sauce",
+	}
+
+	facts := nutrition.Calculate(list, overrides)
+
+	assert.Equal(t, 4, len(facts.Matched))
+	assert.Equal(t, "soy sauce", facts.Matched[3].Food.Name)
+
+	assert.Equal(t, 1, len(facts.Ignored))
+	assert.Equal(t, "salt to taste", facts.Ignored[0].Name)
+
+	assert.Equal(t, 1, len(facts.Unmatched))
+	assert.Equal(t, "dragon fruit", facts.Unmatched[0].Ingredient.Name)
+	assert.Equal(t, nutrition.ReasonNoFood, facts.Unmatched[0].Reason)
+
+	// 200 g of flour, 100 g of eggs, and roughly 32 g of soy sauce.
+	assert.Equal(t, "888", fmt.Sprintf("%.0f", facts.Total.Calories))
+	assert.Equal(t, "444", fmt.Sprintf("%.0f", facts.PerServing(2).Calories))
+	assert.Equal(t, "888", fmt.Sprintf("%.0f", facts.PerServing(0).Calories))
+}
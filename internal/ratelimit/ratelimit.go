@@ -0,0 +1,153 @@
+// Package ratelimit limits the rate of requests made by each client using token buckets held in
+// memory.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// DefaultSweepInterval is how often stale buckets are removed if the limiter's SweepInterval is
+// zero.
+const DefaultSweepInterval = time.Minute
+
+// Limiter holds a token bucket for each key, such as a user ID or IP address. Each request takes a
+// token from its key's bucket, and buckets refill at a steady rate up to a maximum.
+//
+// A limiter with a zero Rate allows every request.
+type Limiter struct {
+	// Rate is the number of requests per second each key may sustain.
+	Rate float64
+
+	// Burst is the maximum number of requests each key may make at once after being idle.
+	Burst int
+
+	// SweepInterval is how often Run removes stale buckets.
+	SweepInterval time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Allow takes a token from the key's bucket if one is available at the given time. If not, the
+// request should be rejected, and the returned duration is how long until a token is available.
+func (l *Limiter) Allow(key string, now time.Time) (bool, time.Duration) {
+	if l.Rate <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.buckets == nil {
+		l.buckets = make(map[string]*bucket)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.capacity(), last: now}
+		l.buckets[key] = b
+	}
+
+	b.refill(now, l.Rate, l.capacity())
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.Rate * float64(time.Second))
+		return false, wait
+	}
+
+	b.tokens--
+
+	return true, 0
+}
+
+// Wait returns how long until a token is available in the key's bucket at the given time, without
+// taking one. A zero duration means that a request would be allowed. It lets callers charge only
+// some requests, such as failed attempts, while still rejecting requests once the key is limited.
+func (l *Limiter) Wait(key string, now time.Time) time.Duration {
+	if l.Rate <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		return 0
+	}
+
+	b.refill(now, l.Rate, l.capacity())
+	if b.tokens >= 1 {
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / l.Rate * float64(time.Second))
+}
+
+// Sweep removes the buckets that have refilled completely by the given time, and returns the
+// number removed. A full bucket behaves the same as a missing one, so removing them does not
+// change which requests are allowed.
+func (l *Limiter) Sweep(now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	removed := 0
+	for key, b := range l.buckets {
+		b.refill(now, l.Rate, l.capacity())
+		if b.tokens >= l.capacity() {
+			delete(l.buckets, key)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// Len returns the number of buckets currently held.
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.buckets)
+}
+
+// Run sweeps stale buckets periodically until the context is canceled.
+func (l *Limiter) Run(ctx context.Context) {
+	interval := l.SweepInterval
+	if interval <= 0 {
+		interval = DefaultSweepInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			l.Sweep(now)
+		}
+	}
+}
+
+// capacity is the maximum number of tokens a bucket holds. A limiter always allows at least one
+// request at a time.
+func (l *Limiter) capacity() float64 {
+	return math.Max(float64(l.Burst), 1)
+}
+
+func (b *bucket) refill(now time.Time, rate float64, capacity float64) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed.Seconds()*rate)
+		b.last = now
+	}
+}
@@ -0,0 +1,116 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/ratelimit"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	limiter := ratelimit.Limiter{Rate: 0.5, Burst: 3}
+	start := time.Date(2024, 5, 19, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		ok, _ := limiter.Allow("a", start)
+		assert.Equal(t, true, ok)
+	}
+
+	ok, wait := limiter.Allow("a", start)
+	assert.Equal(t, false, ok)
+	assert.Equal(t, 2*time.Second, wait)
+
+	// Other keys have their own buckets.
+	ok, _ = limiter.Allow("b", start)
+	assert.Equal(t, true, ok)
+
+	ok, wait = limiter.Allow("a", start.Add(time.Second))
+	assert.Equal(t, false, ok)
+	assert.Equal(t, time.Second, wait)
+
+	ok, _ = limiter.Allow("a", start.Add(2*time.Second))
+	assert.Equal(t, true, ok)
+
+	// Buckets never hold more than the burst, no matter how long they are idle.
+	later := start.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		ok, _ := limiter.Allow("a", later)
+		assert.Equal(t, true, ok)
+	}
+
+	ok, _ = limiter.Allow("a", later)
+	assert.Equal(t, false, ok)
+}
+
+func TestLimiter_Allow_unlimited(t *testing.T) {
+	var limiter ratelimit.Limiter
+	now := time.Now()
+
+	for i := 0; i < 100; i++ {
+		ok, _ := limiter.Allow("a", now)
+		assert.Equal(t, true, ok)
+	}
+
+	assert.Equal(t, 0, limiter.Len())
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	limiter := ratelimit.Limiter{Rate: 0.5, Burst: 1}
+	start := time.Date(2024, 5, 19, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Duration(0), limiter.Wait("a", start))
+
+	// Waiting doesn't take a token.
+	ok, _ := limiter.Allow("a", start)
+	assert.Equal(t, true, ok)
+
+	assert.Equal(t, 2*time.Second, limiter.Wait("a", start))
+	assert.Equal(t, time.Second, limiter.Wait("a", start.Add(time.Second)))
+	assert.Equal(t, time.Duration(0), limiter.Wait("a", start.Add(2*time.Second)))
+	assert.Equal(t, time.Duration(0), limiter.Wait("b", start))
+}
+
+func TestLimiter_Sweep(t *testing.T) {
+	limiter := ratelimit.Limiter{Rate: 1, Burst: 10}
+	start := time.Date(2024, 5, 19, 12, 0, 0, 0, time.UTC)
+
+	limiter.Allow("idle", start)
+	for i := 0; i < 10; i++ {
+		limiter.Allow("busy", start)
+	}
+
+	assert.Equal(t, 0, limiter.Sweep(start))
+	assert.Equal(t, 2, limiter.Len())
+
+	// The idle bucket is full again after a second, but the busy one needs ten.
+	assert.Equal(t, 1, limiter.Sweep(start.Add(time.Second)))
+	assert.Equal(t, 1, limiter.Len())
+
+	assert.Equal(t, 0, limiter.Sweep(start.Add(9*time.Second)))
+	assert.Equal(t, 1, limiter.Sweep(start.Add(10*time.Second)))
+	assert.Equal(t, 0, limiter.Len())
+}
+
+func TestLimiter_Run(t *testing.T) {
+	limiter := ratelimit.Limiter{Rate: 1000, Burst: 1, SweepInterval: time.Millisecond}
+	limiter.Allow("a", time.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		limiter.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for limiter.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	<-done
+
+	assert.Equal(t, 0, limiter.Len())
+}
@@ -0,0 +1,263 @@
+// Package timers detects lengths of time in recipe instructions, such as "bake 25 minutes" or
+// "simmer for 1-2 hours", so they can be offered as kitchen timers.
+package timers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Timer is a length of time mentioned in a piece of text.
+type Timer struct {
+	// Text is the matched text, such as "1-2 hours".
+	Text string
+	// Start and End are the byte offsets of the match within the searched text.
+	Start int
+	End   int
+	// Min and Max bound the length of time. They are equal unless a range was given.
+	Min time.Duration
+	Max time.Duration
+}
+
+// Duration returns the length of time to count down from. For ranges this is the lower bound, so
+// the cook is prompted to check on the food as early as it might be done.
+func (t Timer) Duration() time.Duration {
+	return t.Min
+}
+
+// IsRange reports if the timer covers a range of times rather than an exact length.
+func (t Timer) IsRange() bool {
+	return t.Min != t.Max
+}
+
+// Label returns a short description of the timer, such as "25 min" or "1 hr – 2 hr".
+func (t Timer) Label() string {
+	if t.IsRange() {
+		return Format(t.Min) + " – " + Format(t.Max)
+	}
+
+	return Format(t.Min)
+}
+
+// Format returns a compact representation of a duration, such as "1 hr 30 min" or "45 sec".
+func Format(d time.Duration) string {
+	d = d.Round(time.Second)
+	hours := int(d / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	seconds := int(d % time.Minute / time.Second)
+
+	var parts []string
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%d hr", hours))
+	}
+
+	if minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%d min", minutes))
+	}
+
+	if seconds > 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%d sec", seconds))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+var wordNumbers = map[string]float64{
+	"a":          1,
+	"an":         1,
+	"one":        1,
+	"two":        2,
+	"three":      3,
+	"four":       4,
+	"five":       5,
+	"six":        6,
+	"seven":      7,
+	"eight":      8,
+	"nine":       9,
+	"ten":        10,
+	"eleven":     11,
+	"twelve":     12,
+	"fifteen":    15,
+	"twenty":     20,
+	"thirty":     30,
+	"forty":      40,
+	"forty-five": 45,
+	"sixty":      60,
+	"ninety":     90,
+}
+
+var unicodeFractions = map[rune]float64{
+	'¼': 0.25,
+	'½': 0.5,
+	'¾': 0.75,
+	'⅓': 1.0 / 3,
+	'⅔': 2.0 / 3,
+}
+
+var units = map[string]time.Duration{
+	"second":  time.Second,
+	"seconds": time.Second,
+	"sec":     time.Second,
+	"secs":    time.Second,
+	"minute":  time.Minute,
+	"minutes": time.Minute,
+	"min":     time.Minute,
+	"mins":    time.Minute,
+	"hour":    time.Hour,
+	"hours":   time.Hour,
+	"hr":      time.Hour,
+	"hrs":     time.Hour,
+}
+
+const (
+	// numberPattern matches a single amount. Longer alternatives are listed first so that "1 1/2"
+	// is not matched as "1".
+	numberPattern = `(?:` +
+		`\b\d+\s+\d+/\d+` +
+		`|\b\d+/\d+` +
+		`|\b\d*\.\d+` +
+		`|\b\d+\s*[¼½¾⅓⅔]` +
+		`|\b(?:\d+|one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve)\s+and\s+a\s+half` +
+		`|\b\d+` +
+		`|[¼½¾⅓⅔]` +
+		`|\b(?:forty-five|one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve|fifteen|twenty|thirty|forty|sixty|ninety|an|a)\b` +
+		`)`
+	unitPattern = `(?:seconds?|secs?|minutes?|mins?|hours?|hrs?)\b`
+	// rangePattern matches the separator between the bounds of a range, as in "1-2" or "1 to 2".
+	rangePattern = `\s*(?:-|–|—|\bto\b|\bor\b)\s*`
+	// termPattern matches an amount of a single unit, such as "25 minutes", "1-2 hours", or the
+	// adjective "10-minute".
+	termPattern = `(?:\bhalf\s+(?:an\s+)?hour\b|` + numberPattern + `(?:` + rangePattern + numberPattern + `)?\s*-?\s*` + unitPattern + `)`
+	// amountPattern matches compound amounts such as "1 hour and 15 minutes".
+	amountPattern = termPattern + `(?:\s*,?\s*(?:and\s+)?` + termPattern + `)*`
+)
+
+var (
+	timerRX  = regexp.MustCompile(`(?i)` + amountPattern + `(?:` + rangePattern + amountPattern + `)?`)
+	amountRX = regexp.MustCompile(`(?i)` + amountPattern)
+	termRX   = regexp.MustCompile(
+		`(?i)\bhalf\s+(?:an\s+)?hour\b|(` + numberPattern + `)(?:` + rangePattern + `(` + numberPattern + `))?\s*-?\s*(` + unitPattern + `)`,
+	)
+)
+
+// Detect returns the lengths of time mentioned in the text in the order they appear.
+func Detect(text string) []Timer {
+	var timers []Timer
+	for _, loc := range timerRX.FindAllStringIndex(text, -1) {
+		start, end := loc[0], loc[1]
+
+		// Avoid matching the end of a word, such as the "a" in "pasta hour".
+		if r, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			continue
+		}
+
+		match := text[start:end]
+		amounts := amountRX.FindAllString(match, -1)
+		if len(amounts) == 0 {
+			continue
+		}
+
+		low, _ := parseAmount(amounts[0])
+		_, high := parseAmount(amounts[len(amounts)-1])
+		if low <= 0 || high < low {
+			continue
+		}
+
+		timers = append(timers, Timer{Text: match, Start: start, End: end, Min: low, Max: high})
+	}
+
+	return timers
+}
+
+// parseAmount returns the lower and upper bounds of a possibly compound amount of time.
+func parseAmount(amount string) (time.Duration, time.Duration) {
+	var low, high time.Duration
+	for _, term := range termRX.FindAllStringSubmatch(amount, -1) {
+		if term[1] == "" {
+			// The only term without a number is "half an hour".
+			low += 30 * time.Minute
+			high += 30 * time.Minute
+			continue
+		}
+
+		unit := units[strings.ToLower(term[3])]
+		lower := parseNumber(term[1])
+		upper := lower
+		if term[2] != "" {
+			upper = parseNumber(term[2])
+		}
+
+		low += time.Duration(lower * float64(unit))
+		high += time.Duration(upper * float64(unit))
+	}
+
+	return low.Round(time.Second), high.Round(time.Second)
+}
+
+// parseNumber interprets an amount matched by numberPattern.
+func parseNumber(s string) float64 {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if n, ok := wordNumbers[s]; ok {
+		return n
+	}
+
+	if whole, ok := strings.CutSuffix(s, "and a half"); ok {
+		return parseNumber(whole) + 0.5
+	}
+
+	if whole, frac, ok := strings.Cut(s, " "); ok && strings.Contains(frac, "/") {
+		return parseNumber(whole) + parseNumber(frac)
+	}
+
+	if numerator, denominator, ok := strings.Cut(s, "/"); ok {
+		n, _ := strconv.ParseFloat(numerator, 64)
+		d, _ := strconv.ParseFloat(denominator, 64)
+		if d == 0 {
+			return 0
+		}
+
+		return n / d
+	}
+
+	if r, size := utf8.DecodeLastRuneInString(s); unicodeFractions[r] > 0 {
+		whole, _ := strconv.ParseFloat(strings.TrimSpace(s[:len(s)-size]), 64)
+		return whole + unicodeFractions[r]
+	}
+
+	n, _ := strconv.ParseFloat(s, 64)
+	return n
+}
+
+// Segment is a piece of text that may be a detected timer.
+type Segment struct {
+	Text  string
+	Timer *Timer
+}
+
+// Segments splits the text into plain text and the timers mentioned in it, so that the timers
+// can be presented inline.
+func Segments(text string) []Segment {
+	var segments []Segment
+
+	offset := 0
+	for _, timer := range Detect(text) {
+		if timer.Start > offset {
+			segments = append(segments, Segment{Text: text[offset:timer.Start]})
+		}
+
+		segments = append(segments, Segment{Text: timer.Text, Timer: &timer})
+		offset = timer.End
+	}
+
+	if offset < len(text) {
+		segments = append(segments, Segment{Text: text[offset:]})
+	}
+
+	return segments
+}
@@ -0,0 +1,143 @@
+package timers_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/timers"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		text     string
+		wantText string
+		wantMin  time.Duration
+		wantMax  time.Duration
+	}{
+		{text: "Bake 25 minutes.", wantText: "25 minutes", wantMin: 25 * time.Minute, wantMax: 25 * time.Minute},
+		{text: "Simmer for 1-2 hours", wantText: "1-2 hours", wantMin: time.Hour, wantMax: 2 * time.Hour},
+		{text: "Simmer for 1 - 2 hours", wantText: "1 - 2 hours", wantMin: time.Hour, wantMax: 2 * time.Hour},
+		{text: "Roast 20–25 min", wantText: "20–25 min", wantMin: 20 * time.Minute, wantMax: 25 * time.Minute},
+		{text: "Cook 3 to 4 minutes per side", wantText: "3 to 4 minutes", wantMin: 3 * time.Minute, wantMax: 4 * time.Minute},
+		{text: "Stir 2 or 3 minutes", wantText: "2 or 3 minutes", wantMin: 2 * time.Minute, wantMax: 3 * time.Minute},
+		{text: "Bake 45 minutes to 1 hour", wantText: "45 minutes to 1 hour", wantMin: 45 * time.Minute, wantMax: time.Hour},
+		{text: "Braise 1 hour 30 minutes", wantText: "1 hour 30 minutes", wantMin: 90 * time.Minute, wantMax: 90 * time.Minute},
+		{text: "Braise 1 hour and 15 minutes", wantText: "1 hour and 15 minutes", wantMin: 75 * time.Minute, wantMax: 75 * time.Minute},
+		{text: "Braise 2 hrs, 10 mins", wantText: "2 hrs, 10 mins", wantMin: 130 * time.Minute, wantMax: 130 * time.Minute},
+		{text: "Rest 1 1/2 hours", wantText: "1 1/2 hours", wantMin: 90 * time.Minute, wantMax: 90 * time.Minute},
+		{text: "Rest 1½ hours", wantText: "1½ hours", wantMin: 90 * time.Minute, wantMax: 90 * time.Minute},
+		{text: "Rest ½ hour", wantText: "½ hour", wantMin: 30 * time.Minute, wantMax: 30 * time.Minute},
+		{text: "Rest 1/2 hour", wantText: "1/2 hour", wantMin: 30 * time.Minute, wantMax: 30 * time.Minute},
+		{text: "Rest 1.5 hours", wantText: "1.5 hours", wantMin: 90 * time.Minute, wantMax: 90 * time.Minute},
+		{text: "Rest 2 and a half hours", wantText: "2 and a half hours", wantMin: 150 * time.Minute, wantMax: 150 * time.Minute},
+		{text: "Chill for half an hour", wantText: "half an hour", wantMin: 30 * time.Minute, wantMax: 30 * time.Minute},
+		{text: "Chill for an hour", wantText: "an hour", wantMin: time.Hour, wantMax: time.Hour},
+		{text: "Whisk for a minute", wantText: "a minute", wantMin: time.Minute, wantMax: time.Minute},
+		{text: "Boil for ten minutes", wantText: "ten minutes", wantMin: 10 * time.Minute, wantMax: 10 * time.Minute},
+		{text: "Boil for Five to Six Minutes", wantText: "Five to Six Minutes", wantMin: 5 * time.Minute, wantMax: 6 * time.Minute},
+		{text: "Microwave 30 seconds", wantText: "30 seconds", wantMin: 30 * time.Second, wantMax: 30 * time.Second},
+		{text: "Microwave 90 secs", wantText: "90 secs", wantMin: 90 * time.Second, wantMax: 90 * time.Second},
+		{text: "After a 10-minute rest", wantText: "10-minute", wantMin: 10 * time.Minute, wantMax: 10 * time.Minute},
+		{text: "Bake 25 MIN", wantText: "25 MIN", wantMin: 25 * time.Minute, wantMax: 25 * time.Minute},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.text, func(t *testing.T) {
+			got := timers.Detect(tt.text)
+
+			if len(got) != 1 {
+				t.Fatalf("Expected one timer in %q; got %+v", tt.text, got)
+			}
+
+			assert.Equal(t, tt.wantText, got[0].Text)
+			assert.Equal(t, tt.wantText, tt.text[got[0].Start:got[0].End])
+			assert.Equal(t, tt.wantMin, got[0].Min)
+			assert.Equal(t, tt.wantMax, got[0].Max)
+			assert.Equal(t, tt.wantMin, got[0].Duration())
+		})
+	}
+}
+
+func TestDetect_none(t *testing.T) {
+	testCases := []string{
+		"Preheat the oven to 350 degrees.",
+		"Add 2 cups flour.",
+		"Cook until golden.",
+		"Cut into 2 inch pieces.",
+		"Stir a few minutes.",
+		"Serves 4.",
+		"Add the pasta hour by hour.",
+		"Admin notes: none.",
+		"Use 4 minced cloves of garlic.",
+		"Wait 0 minutes.",
+		"Cook 5 to 2 minutes.",
+	}
+
+	for _, text := range testCases {
+		t.Run(text, func(t *testing.T) {
+			if got := timers.Detect(text); len(got) != 0 {
+				t.Errorf("Expected no timers in %q; got %+v", text, got)
+			}
+		})
+	}
+}
+
+func TestDetect_multiple(t *testing.T) {
+	text := "Bake 25 minutes, then rotate the pan and bake 10-15 minutes more. Cool for 1 hour."
+
+	got := timers.Detect(text)
+
+	var texts []string
+	for _, timer := range got {
+		texts = append(texts, timer.Text)
+	}
+
+	assert.Equal(t, "25 minutes|10-15 minutes|1 hour", strings.Join(texts, "|"))
+}
+
+func TestTimer_Label(t *testing.T) {
+	testCases := []struct {
+		timer timers.Timer
+		want  string
+	}{
+		{timer: timers.Timer{Min: 25 * time.Minute, Max: 25 * time.Minute}, want: "25 min"},
+		{timer: timers.Timer{Min: time.Hour, Max: 2 * time.Hour}, want: "1 hr – 2 hr"},
+		{timer: timers.Timer{Min: 90 * time.Minute, Max: 90 * time.Minute}, want: "1 hr 30 min"},
+		{timer: timers.Timer{Min: 45 * time.Second, Max: 45 * time.Second}, want: "45 sec"},
+		{timer: timers.Timer{Min: 90 * time.Second, Max: 90 * time.Second}, want: "1 min 30 sec"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.timer.Label())
+		})
+	}
+}
+
+func TestSegments(t *testing.T) {
+	text := "Bake 25 minutes, then cool 1-2 hours"
+
+	segments := timers.Segments(text)
+
+	var rebuilt strings.Builder
+	var timerTexts []string
+	for _, segment := range segments {
+		rebuilt.WriteString(segment.Text)
+		if segment.Timer != nil {
+			timerTexts = append(timerTexts, segment.Timer.Text)
+		}
+	}
+
+	assert.Equal(t, text, rebuilt.String())
+	assert.Equal(t, 4, len(segments))
+	assert.Equal(t, "25 minutes|1-2 hours", strings.Join(timerTexts, "|"))
+}
+
+func TestSegments_noTimers(t *testing.T) {
+	segments := timers.Segments("Mix well.")
+
+	assert.Equal(t, 1, len(segments))
+	assert.Equal(t, "Mix well.", segments[0].Text)
+}
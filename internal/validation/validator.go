@@ -1,7 +1,10 @@
 package validation
 
 import (
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -49,3 +52,41 @@ func UUIDOrBlank(value string) bool {
 	_, err := uuid.Parse(value)
 	return err == nil
 }
+
+// WebURLOrBlank reports if the value is blank or an absolute http or https URL.
+func WebURLOrBlank(value string) bool {
+	if value == "" {
+		return true
+	}
+
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// ValidDate reports if the value is a date in the format used by HTML date inputs.
+func ValidDate(value string) bool {
+	_, err := time.Parse(time.DateOnly, value)
+	return err == nil
+}
+
+// ValidTimestamp reports if the value is a timestamp in RFC 3339 format.
+func ValidTimestamp(value string) bool {
+	_, err := time.Parse(time.RFC3339Nano, value)
+	return err == nil
+}
+
+// IntInRange reports if the value is a whole number between min and max, inclusive.
+func IntInRange(value string, min, max int) bool {
+	i, err := strconv.Atoi(value)
+	return err == nil && i >= min && i <= max
+}
+
+func PermittedValue[T comparable](value T, permitted ...T) bool {
+	for _, p := range permitted {
+		if value == p {
+			return true
+		}
+	}
+
+	return false
+}
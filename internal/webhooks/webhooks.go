@@ -0,0 +1,322 @@
+// Package webhooks sends signed event payloads to the URLs users register to be notified of changes
+// to their recipes and categories.
+//
+// Deliveries are stored before they are sent, and a Dispatcher running in the background sends the
+// deliveries that are due, retrying failures with exponential backoff.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderDelivery  = "X-Recipes-Delivery"
+	HeaderEvent     = "X-Recipes-Event"
+	HeaderSignature = "X-Recipes-Signature-256"
+)
+
+// Defaults for the Dispatcher's options.
+const (
+	DefaultBatchSize   = 20
+	DefaultInterval    = 10 * time.Second
+	DefaultMaxAttempts = 8
+	DefaultRetryDelay  = 30 * time.Second
+)
+
+// maxErrorLength limits the length of the error stored for a failed attempt.
+const maxErrorLength = 500
+
+// Store persists deliveries and the outcomes of attempts to send them.
+type Store interface {
+	ClaimDue(context.Context, int, time.Duration) ([]models.PendingWebhookDelivery, error)
+	RecordAttempt(context.Context, uuid.UUID, models.WebhookAttempt) error
+}
+
+// Sign returns the signature of a payload, which is the hex-encoded HMAC-SHA256 of the payload
+// using the webhook's secret as the key, prefixed with "sha256=".
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports if the signature matches the payload. Receivers can use it to check that a
+// delivery was sent by this application.
+func Verify(secret string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, payload)), []byte(signature))
+}
+
+// Backoff returns the delay before retrying a delivery that has failed the provided number of
+// times. The delay starts at base and doubles after each failure.
+func Backoff(base time.Duration, failures int) time.Duration {
+	if failures < 1 {
+		return base
+	}
+
+	return base << (failures - 1)
+}
+
+// ErrForbiddenAddress is returned for webhook URLs pointing at addresses deliveries may not be sent
+// to.
+var ErrForbiddenAddress = errors.New("deliveries may not be sent to private or local network addresses")
+
+// nonPublicPrefixes are networks that are not reachable on the public internet and that the
+// netip.Addr methods don't cover.
+var nonPublicPrefixes = []netip.Prefix{
+	// "This network", which some systems treat as the local host.
+	netip.MustParsePrefix("0.0.0.0/8"),
+	// Carrier-grade NAT.
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// PublicAddress reports if deliveries may be sent to the address. Loopback, link-local, private,
+// and other addresses that aren't publicly routable are refused so that users can't use webhooks
+// to make requests to the server's own network.
+func PublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckURL returns ErrForbiddenAddress if the URL's host is an address, or a name for the local
+// host or network, that deliveries may not be sent to. Other host names are checked each time a
+// delivery is sent by the client from NewClient, since the addresses they resolve to may change.
+func CheckURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse webhook URL: %w", err)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !PublicAddress(addr) {
+			return ErrForbiddenAddress
+		}
+
+		return nil
+	}
+
+	// Names under "local" are resolved by multicast DNS on the local network.
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".local") {
+		return ErrForbiddenAddress
+	}
+
+	return nil
+}
+
+// NewClient returns an HTTP client for sending deliveries. Unless allowPrivate is set, the client
+// refuses to connect to addresses that aren't public when the connection is made, so that neither
+// redirects nor host names resolving to internal addresses can get around the check.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = refusePrivateAddresses
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// A proxy would make the connection on the client's behalf, bypassing the dialer's check.
+	transport.Proxy = nil
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// refusePrivateAddresses is a net.Dialer control function that refuses connections to addresses
+// that aren't public.
+func refusePrivateAddresses(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("failed to parse address %q: %w", address, err)
+	}
+
+	if !PublicAddress(addrPort.Addr()) {
+		return fmt.Errorf("refusing to connect to %s: %w", addrPort.Addr(), ErrForbiddenAddress)
+	}
+
+	return nil
+}
+
+// Dispatcher sends deliveries that are due. The zero value of each option is replaced with its
+// default.
+type Dispatcher struct {
+	Store  Store
+	Client *http.Client
+	Logger *slog.Logger
+
+	// BatchSize is the most deliveries claimed at once.
+	BatchSize int
+	// Interval is the time between checks for deliveries that are due.
+	Interval time.Duration
+	// MaxAttempts is the number of attempts made before a delivery is abandoned.
+	MaxAttempts int
+	// RetryDelay is the delay before the first retry of a failed delivery.
+	RetryDelay time.Duration
+}
+
+// Run sends deliveries as they become due until the context is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.Logger.InfoContext(ctx, "Starting webhook dispatcher.")
+
+	ticker := time.NewTicker(orDefault(d.Interval, DefaultInterval))
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DeliverDue(ctx); err != nil {
+			d.Logger.ErrorContext(ctx, "Failed to send webhook deliveries.", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			d.Logger.InfoContext(ctx, "Webhook dispatcher stopped.")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue sends each delivery that is due and records the outcome. It returns the number of
+// deliveries attempted.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	attemptTime := time.Minute
+	if client.Timeout > 0 {
+		attemptTime = client.Timeout
+	}
+
+	// A batch is sent one delivery at a time, so every delivery is leased for long enough for all
+	// the attempts in the batch to time out, with one to spare, so that none are claimed again
+	// while the batch is being sent.
+	batchSize := orDefault(d.BatchSize, DefaultBatchSize)
+	lease := time.Duration(batchSize+1) * attemptTime
+
+	deliveries, err := d.Store.ClaimDue(ctx, batchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	// A failure to record one attempt doesn't stop the rest of the batch from being sent. The
+	// delivery is retried once its lease expires.
+	var errs []error
+	for _, delivery := range deliveries {
+		attempt := d.deliver(ctx, client, delivery)
+		if err := d.Store.RecordAttempt(ctx, delivery.ID, attempt); err != nil {
+			errs = append(errs, fmt.Errorf("failed to record attempt of delivery %s: %w", delivery.ID, err))
+		}
+	}
+
+	return len(deliveries), errors.Join(errs...)
+}
+
+// deliver makes one attempt to send a delivery.
+func (d *Dispatcher) deliver(ctx context.Context, client *http.Client, delivery models.PendingWebhookDelivery) models.WebhookAttempt {
+	var attempt models.WebhookAttempt
+
+	status, err := send(ctx, client, delivery)
+	if status != 0 {
+		attempt.ResponseStatus = pgtype.Int2{Int16: int16(status), Valid: true}
+	}
+
+	if err == nil {
+		attempt.Delivered = true
+		d.Logger.InfoContext(ctx, "Sent webhook delivery.", "id", delivery.ID, "status", status)
+
+		return attempt
+	}
+
+	attempt.Error = err.Error()
+	if len(attempt.Error) > maxErrorLength {
+		attempt.Error = attempt.Error[:maxErrorLength]
+	}
+
+	failures := delivery.Attempts + 1
+	if failures < orDefault(d.MaxAttempts, DefaultMaxAttempts) {
+		attempt.NextAttemptAt = pgtype.Timestamptz{
+			Time:  time.Now().Add(Backoff(orDefault(d.RetryDelay, DefaultRetryDelay), failures)),
+			Valid: true,
+		}
+	}
+
+	d.Logger.WarnContext(
+		ctx,
+		"Failed to send webhook delivery.",
+		"id", delivery.ID,
+		"attempts", failures,
+		"retry", attempt.NextAttemptAt.Valid,
+		"error", err,
+	)
+
+	return attempt
+}
+
+// send posts a delivery's payload to its webhook. It returns the response's status code, or 0 if
+// no response was received, and an error if the delivery was not accepted.
+func send(ctx context.Context, client *http.Client, delivery models.PendingWebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "recipes-webhooks/1")
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, delivery.Payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Drain a bounded amount of the body so the connection can be reused.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// orDefault returns value if it is positive, or fallback otherwise.
+func orDefault[T int | time.Duration](value, fallback T) T {
+	if value > 0 {
+		return value
+	}
+
+	return fallback
+}
@@ -0,0 +1,297 @@
+package webhooks_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/cdriehuys/recipes/internal/assert"
+	"github.com/cdriehuys/recipes/internal/models"
+	"github.com/cdriehuys/recipes/internal/webhooks"
+	"github.com/google/uuid"
+)
+
+// fakeStore holds deliveries in memory in place of the database.
+type fakeStore struct {
+	due      []models.PendingWebhookDelivery
+	attempts map[uuid.UUID]models.WebhookAttempt
+	lease    time.Duration
+	// failing is the ID of a delivery whose attempts can't be recorded.
+	failing uuid.UUID
+}
+
+func (s *fakeStore) ClaimDue(_ context.Context, _ int, lease time.Duration) ([]models.PendingWebhookDelivery, error) {
+	due := s.due
+	s.due = nil
+	s.lease = lease
+
+	return due, nil
+}
+
+func (s *fakeStore) RecordAttempt(_ context.Context, id uuid.UUID, attempt models.WebhookAttempt) error {
+	if id == s.failing {
+		return errors.New("database unavailable")
+	}
+
+	if s.attempts == nil {
+		s.attempts = make(map[uuid.UUID]models.WebhookAttempt)
+	}
+
+	s.attempts[id] = attempt
+
+	return nil
+}
+
+func TestSign(t *testing.T) {
+	payload := []byte(`{"event":"recipe.created"}`)
+	signature := webhooks.Sign("secret", payload)
+
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.Equal(t, 7+64, len(signature))
+	assert.Equal(t, true, webhooks.Verify("secret", payload, signature))
+	assert.Equal(t, false, webhooks.Verify("other", payload, signature))
+	assert.Equal(t, false, webhooks.Verify("secret", []byte(`{}`), signature))
+}
+
+func TestBackoff(t *testing.T) {
+	testCases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 30 * time.Second},
+		{failures: 1, want: 30 * time.Second},
+		{failures: 2, want: time.Minute},
+		{failures: 3, want: 2 * time.Minute},
+		{failures: 7, want: 32 * time.Minute},
+	}
+
+	for _, tt := range testCases {
+		assert.Equal(t, tt.want, webhooks.Backoff(30*time.Second, tt.failures))
+	}
+}
+
+func TestDispatcher_DeliverDue(t *testing.T) {
+	testCases := []struct {
+		name          string
+		status        int
+		attempts      int
+		wantDelivered bool
+		wantRetry     time.Duration
+	}{
+		{name: "accepted", status: http.StatusNoContent, wantDelivered: true},
+		{name: "first failure", status: http.StatusInternalServerError, wantRetry: 30 * time.Second},
+		{name: "later failure", status: http.StatusBadGateway, attempts: 3, wantRetry: 4 * time.Minute},
+		{name: "final failure", status: http.StatusNotFound, attempts: webhooks.DefaultMaxAttempts - 1},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := []byte(`{"event":"recipe.created","data":{"title":"Soup"}}`)
+
+			var received *http.Request
+			var receivedBody []byte
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r
+				receivedBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer receiver.Close()
+
+			delivery := models.PendingWebhookDelivery{
+				ID:       uuid.New(),
+				Event:    models.EventRecipeCreated,
+				Payload:  payload,
+				Attempts: tt.attempts,
+				URL:      receiver.URL,
+				Secret:   "secret",
+			}
+			store := &fakeStore{due: []models.PendingWebhookDelivery{delivery}}
+			dispatcher := webhooks.Dispatcher{
+				Store:  store,
+				Client: receiver.Client(),
+				Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+			}
+
+			start := time.Now()
+			count, err := dispatcher.DeliverDue(context.Background())
+
+			assert.NilError(t, err)
+			assert.Equal(t, 1, count)
+
+			if received == nil {
+				t.Fatal("Expected webhook to receive a request")
+			}
+
+			assert.Equal(t, http.MethodPost, received.Method)
+			assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+			assert.Equal(t, delivery.ID.String(), received.Header.Get(webhooks.HeaderDelivery))
+			assert.Equal(t, models.EventRecipeCreated, received.Header.Get(webhooks.HeaderEvent))
+			assert.Equal(t, string(payload), string(receivedBody))
+			assert.Equal(t, true, webhooks.Verify("secret", receivedBody, received.Header.Get(webhooks.HeaderSignature)))
+
+			attempt := store.attempts[delivery.ID]
+			assert.Equal(t, tt.wantDelivered, attempt.Delivered)
+			assert.Equal(t, int16(tt.status), attempt.ResponseStatus.Int16)
+
+			if tt.wantDelivered {
+				assert.Equal(t, "", attempt.Error)
+				return
+			}
+
+			assert.StringContains(t, attempt.Error, "status")
+			assert.Equal(t, tt.wantRetry != 0, attempt.NextAttemptAt.Valid)
+
+			if tt.wantRetry != 0 {
+				retry := attempt.NextAttemptAt.Time.Sub(start)
+				if retry < tt.wantRetry || retry > tt.wantRetry+time.Minute {
+					t.Errorf("Expected retry after %v; got %v", tt.wantRetry, retry)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatcher_DeliverDue_batch(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	var due []models.PendingWebhookDelivery
+	for i := 0; i < 3; i++ {
+		due = append(due, models.PendingWebhookDelivery{ID: uuid.New(), Payload: []byte(`{}`), URL: receiver.URL, Secret: "secret"})
+	}
+
+	client := receiver.Client()
+	client.Timeout = 10 * time.Second
+
+	store := &fakeStore{due: due, failing: due[0].ID}
+	dispatcher := webhooks.Dispatcher{
+		Store:     store,
+		Client:    client,
+		Logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		BatchSize: 3,
+	}
+
+	count, err := dispatcher.DeliverDue(context.Background())
+
+	assert.Equal(t, 3, count)
+	if err == nil {
+		t.Error("Expected the failure to record an attempt to be returned")
+	}
+
+	// The rest of the batch is still sent and recorded.
+	assert.Equal(t, 2, len(store.attempts))
+	assert.Equal(t, true, store.attempts[due[2].ID].Delivered)
+
+	// Each delivery is leased until every attempt in the batch could have timed out.
+	if store.lease < 3*client.Timeout {
+		t.Errorf("Expected a lease covering the whole batch; got %v", store.lease)
+	}
+}
+
+func TestDispatcher_DeliverDue_unreachable(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	url := receiver.URL
+	receiver.Close()
+
+	delivery := models.PendingWebhookDelivery{ID: uuid.New(), Payload: []byte(`{}`), URL: url, Secret: "secret"}
+	store := &fakeStore{due: []models.PendingWebhookDelivery{delivery}}
+	dispatcher := webhooks.Dispatcher{
+		Store:  store,
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	_, err := dispatcher.DeliverDue(context.Background())
+	assert.NilError(t, err)
+
+	attempt := store.attempts[delivery.ID]
+	assert.Equal(t, false, attempt.Delivered)
+	assert.Equal(t, false, attempt.ResponseStatus.Valid)
+	assert.Equal(t, true, attempt.NextAttemptAt.Valid)
+	if attempt.Error == "" {
+		t.Error("Expected the connection error to be recorded")
+	}
+}
+
+func TestPublicAddress(t *testing.T) {
+	testCases := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.216.34", want: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{addr: "127.0.0.1"},
+		{addr: "::1"},
+		{addr: "10.1.2.3"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.10"},
+		{addr: "169.254.169.254"},
+		{addr: "fe80::1"},
+		{addr: "fd00::1"},
+		{addr: "0.0.0.0"},
+		{addr: "::"},
+		{addr: "100.64.0.1"},
+		{addr: "224.0.0.1"},
+		{addr: "::ffff:127.0.0.1"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.want, webhooks.PublicAddress(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	testCases := []struct {
+		url       string
+		wantError bool
+	}{
+		{url: "https://example.com/hook"},
+		{url: "https://93.184.216.34/hook"},
+		{url: "http://127.0.0.1:8080/hook", wantError: true},
+		{url: "http://[::1]/hook", wantError: true},
+		{url: "http://169.254.169.254/latest/meta-data", wantError: true},
+		{url: "http://localhost:8080/hook", wantError: true},
+		{url: "http://api.localhost/hook", wantError: true},
+		{url: "http://homeassistant.local:8123/api/webhook", wantError: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.url, func(t *testing.T) {
+			err := webhooks.CheckURL(tt.url)
+
+			assert.Equal(t, tt.wantError, errors.Is(err, webhooks.ErrForbiddenAddress))
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	t.Run("private addresses refused", func(t *testing.T) {
+		_, err := webhooks.NewClient(time.Second, false).Get(receiver.URL)
+
+		if !errors.Is(err, webhooks.ErrForbiddenAddress) {
+			t.Errorf("Expected the connection to be refused; got %v", err)
+		}
+	})
+
+	t.Run("private addresses allowed", func(t *testing.T) {
+		resp, err := webhooks.NewClient(time.Second, true).Get(receiver.URL)
+		assert.NilError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+}
@@ -0,0 +1,16 @@
+CREATE TABLE recipe_shares (
+    id uuid PRIMARY KEY,
+    recipe uuid NOT NULL REFERENCES recipes (id)
+        ON DELETE CASCADE,
+    token text NOT NULL
+        CONSTRAINT recipe_shares_unq_token UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX recipe_shares_recipe_idx ON recipe_shares (recipe);
+
+---- create above / drop below ----
+
+DROP TABLE recipe_shares;
@@ -0,0 +1,70 @@
+CREATE TABLE households (
+    id uuid PRIMARY KEY,
+    "name" text NOT NULL
+        CONSTRAINT households_name_len CHECK (length("name") < 51),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+{{ template "shared/update_time.sql" "households" }}
+
+CREATE TABLE household_members (
+    household uuid NOT NULL REFERENCES households (id)
+        ON DELETE CASCADE,
+    member text NOT NULL REFERENCES "users" (id)
+        ON DELETE CASCADE,
+    "role" text NOT NULL
+        CONSTRAINT household_members_role CHECK ("role" IN ('viewer', 'editor', 'admin')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (household, member)
+);
+
+CREATE INDEX household_members_member_idx ON household_members (member);
+
+{{ template "shared/update_time.sql" "household_members" }}
+
+CREATE TABLE household_invitations (
+    id uuid PRIMARY KEY,
+    household uuid NOT NULL REFERENCES households (id)
+        ON DELETE CASCADE,
+    token text NOT NULL
+        CONSTRAINT household_invitations_unq_token UNIQUE,
+    "role" text NOT NULL
+        CONSTRAINT household_invitations_role CHECK ("role" IN ('viewer', 'editor', 'admin')),
+    created_by text NOT NULL REFERENCES "users" (id)
+        ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_by text REFERENCES "users" (id)
+        ON DELETE SET NULL,
+    accepted_at TIMESTAMPTZ
+);
+
+CREATE INDEX household_invitations_household_idx ON household_invitations (household);
+
+ALTER TABLE recipes ADD COLUMN household uuid REFERENCES households (id)
+    ON DELETE CASCADE;
+
+ALTER TABLE categories ADD COLUMN household uuid REFERENCES households (id)
+    ON DELETE CASCADE;
+
+-- Category names are unique within a household, or among the owner's personal categories.
+ALTER TABLE categories DROP CONSTRAINT categories_unq_name;
+
+CREATE UNIQUE INDEX categories_unq_household_name ON categories (household, "name")
+    WHERE household IS NOT NULL;
+
+CREATE UNIQUE INDEX categories_unq_owner_name ON categories ("owner", "name")
+    WHERE household IS NULL;
+
+---- create above / drop below ----
+
+DROP INDEX categories_unq_owner_name;
+DROP INDEX categories_unq_household_name;
+ALTER TABLE categories ADD CONSTRAINT categories_unq_name UNIQUE ("owner", "name");
+ALTER TABLE categories DROP COLUMN household;
+ALTER TABLE recipes DROP COLUMN household;
+DROP TABLE household_invitations;
+DROP TABLE household_members;
+DROP TABLE households;
@@ -0,0 +1,21 @@
+CREATE TABLE recipe_ratings (
+    recipe uuid NOT NULL REFERENCES recipes (id)
+        ON DELETE CASCADE,
+    owner text NOT NULL REFERENCES "users" (id)
+        ON DELETE CASCADE,
+    favorite boolean NOT NULL DEFAULT false,
+    rating smallint
+        CONSTRAINT recipe_ratings_rating_range CHECK (rating BETWEEN 1 AND 5),
+    make_again boolean,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (recipe, owner)
+);
+
+CREATE INDEX recipe_ratings_owner_idx ON recipe_ratings (owner);
+
+{{ template "shared/update_time.sql" "recipe_ratings" }}
+
+---- create above / drop below ----
+
+DROP TABLE recipe_ratings;
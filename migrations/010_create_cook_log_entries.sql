@@ -0,0 +1,22 @@
+CREATE TABLE cook_log_entries (
+    id uuid PRIMARY KEY,
+    recipe uuid NOT NULL REFERENCES recipes (id)
+        ON DELETE CASCADE,
+    owner text NOT NULL REFERENCES "users" (id)
+        ON DELETE CASCADE,
+    cooked_on date NOT NULL,
+    notes text NOT NULL DEFAULT ''
+        CONSTRAINT cook_log_entries_notes_len CHECK (length(notes) < 2001),
+    rating smallint
+        CONSTRAINT cook_log_entries_rating_range CHECK (rating BETWEEN 1 AND 5),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX cook_log_entries_recipe_idx ON cook_log_entries (recipe, cooked_on);
+
+{{ template "shared/update_time.sql" "cook_log_entries" }}
+
+---- create above / drop below ----
+
+DROP TABLE cook_log_entries;
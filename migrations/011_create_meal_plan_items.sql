@@ -0,0 +1,30 @@
+ALTER TABLE recipes
+    ADD COLUMN servings smallint
+        CONSTRAINT recipes_servings_range CHECK (servings BETWEEN 1 AND 100);
+
+CREATE TABLE meal_plan_items (
+    id uuid PRIMARY KEY,
+    owner text NOT NULL REFERENCES "users" (id)
+        ON DELETE CASCADE,
+    planned_on date NOT NULL,
+    slot text NOT NULL
+        CONSTRAINT meal_plan_items_slot_valid CHECK (slot IN ('breakfast', 'lunch', 'dinner', 'snack')),
+    recipe uuid REFERENCES recipes (id)
+        ON DELETE CASCADE,
+    note text NOT NULL DEFAULT ''
+        CONSTRAINT meal_plan_items_note_len CHECK (length(note) < 201),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT meal_plan_items_recipe_or_note CHECK (recipe IS NOT NULL OR note <> '')
+);
+
+CREATE INDEX meal_plan_items_owner_idx ON meal_plan_items (owner, planned_on);
+
+{{ template "shared/update_time.sql" "meal_plan_items" }}
+
+---- create above / drop below ----
+
+DROP TABLE meal_plan_items;
+
+ALTER TABLE recipes DROP COLUMN servings;
@@ -0,0 +1,25 @@
+ALTER TABLE recipes ADD COLUMN ingredients text NOT NULL DEFAULT '';
+
+CREATE TABLE shopping_list_items (
+    id uuid PRIMARY KEY,
+    owner text NOT NULL REFERENCES "users" (id)
+        ON DELETE CASCADE,
+    name text NOT NULL
+        CONSTRAINT shopping_list_items_name_len CHECK (length(name) BETWEEN 1 AND 200),
+    quantity double precision
+        CONSTRAINT shopping_list_items_quantity_positive CHECK (quantity > 0),
+    unit text NOT NULL DEFAULT '',
+    checked boolean NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX shopping_list_items_owner_idx ON shopping_list_items (owner);
+
+{{ template "shared/update_time.sql" "shopping_list_items" }}
+
+---- create above / drop below ----
+
+DROP TABLE shopping_list_items;
+
+ALTER TABLE recipes DROP COLUMN ingredients;
@@ -0,0 +1,34 @@
+CREATE TABLE store_aisles (
+    id uuid PRIMARY KEY,
+    owner text NOT NULL REFERENCES "users" (id)
+        ON DELETE CASCADE,
+    name text NOT NULL
+        CONSTRAINT store_aisles_name_len CHECK (length(name) BETWEEN 1 AND 50),
+    position integer NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX store_aisles_owner_idx ON store_aisles (owner, position);
+
+{{ template "shared/update_time.sql" "store_aisles" }}
+
+-- The aisle each ingredient is found in, keyed by the normalized ingredient name so the mapping
+-- applies to every future shopping list.
+CREATE TABLE ingredient_aisles (
+    owner text NOT NULL REFERENCES "users" (id)
+        ON DELETE CASCADE,
+    ingredient text NOT NULL,
+    aisle uuid NOT NULL REFERENCES store_aisles (id)
+        ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (owner, ingredient)
+);
+
+{{ template "shared/update_time.sql" "ingredient_aisles" }}
+
+---- create above / drop below ----
+
+DROP TABLE ingredient_aisles;
+DROP TABLE store_aisles;
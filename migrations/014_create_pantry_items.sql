@@ -0,0 +1,21 @@
+CREATE TABLE pantry_items (
+    id uuid PRIMARY KEY,
+    owner text NOT NULL REFERENCES "users" (id)
+        ON DELETE CASCADE,
+    name text NOT NULL
+        CONSTRAINT pantry_items_name_len CHECK (length(name) BETWEEN 1 AND 200),
+    quantity double precision
+        CONSTRAINT pantry_items_quantity_positive CHECK (quantity > 0),
+    unit text NOT NULL DEFAULT '',
+    expires_on date,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX pantry_items_owner_idx ON pantry_items (owner);
+
+{{ template "shared/update_time.sql" "pantry_items" }}
+
+---- create above / drop below ----
+
+DROP TABLE pantry_items;
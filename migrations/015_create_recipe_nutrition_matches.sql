@@ -0,0 +1,17 @@
+CREATE TABLE recipe_nutrition_matches (
+    recipe uuid NOT NULL REFERENCES recipes (id)
+        ON DELETE CASCADE,
+    ingredient text NOT NULL
+        CONSTRAINT recipe_nutrition_matches_ingredient_len CHECK (length(ingredient) BETWEEN 1 AND 200),
+    food text NOT NULL
+        CONSTRAINT recipe_nutrition_matches_food_len CHECK (length(food) BETWEEN 1 AND 100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (recipe, ingredient)
+);
+
+{{ template "shared/update_time.sql" "recipe_nutrition_matches" }}
+
+---- create above / drop below ----
+
+DROP TABLE recipe_nutrition_matches;
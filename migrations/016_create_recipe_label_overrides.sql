@@ -0,0 +1,17 @@
+CREATE TABLE recipe_label_overrides (
+    recipe uuid NOT NULL REFERENCES recipes (id)
+        ON DELETE CASCADE,
+    label text NOT NULL
+        CONSTRAINT recipe_label_overrides_label_valid
+            CHECK (label IN ('gluten', 'dairy', 'nuts', 'egg', 'shellfish', 'soy', 'vegetarian', 'vegan')),
+    present boolean NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (recipe, label)
+);
+
+{{ template "shared/update_time.sql" "recipe_label_overrides" }}
+
+---- create above / drop below ----
+
+DROP TABLE recipe_label_overrides;
@@ -0,0 +1,22 @@
+ALTER TABLE recipes
+    ADD COLUMN prep_time interval
+        CONSTRAINT recipes_prep_time_range CHECK (prep_time >= '0'::interval),
+    ADD COLUMN cook_time interval
+        CONSTRAINT recipes_cook_time_range CHECK (cook_time >= '0'::interval),
+    ADD COLUMN total_time interval
+        CONSTRAINT recipes_total_time_range CHECK (total_time >= '0'::interval),
+    ADD COLUMN source_name text NOT NULL DEFAULT ''
+        CONSTRAINT recipes_source_name_len CHECK (length(source_name) < 201),
+    ADD COLUMN source_url text NOT NULL DEFAULT ''
+        CONSTRAINT recipes_source_url_len CHECK (length(source_url) < 2001);
+
+CREATE INDEX recipes_total_time_idx ON recipes (total_time);
+
+---- create above / drop below ----
+
+ALTER TABLE recipes
+    DROP COLUMN prep_time,
+    DROP COLUMN cook_time,
+    DROP COLUMN total_time,
+    DROP COLUMN source_name,
+    DROP COLUMN source_url;
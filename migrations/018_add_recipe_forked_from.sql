@@ -0,0 +1,9 @@
+ALTER TABLE recipes
+    ADD COLUMN forked_from uuid REFERENCES recipes (id)
+        ON DELETE SET NULL;
+
+CREATE INDEX recipes_forked_from_idx ON recipes (forked_from);
+
+---- create above / drop below ----
+
+ALTER TABLE recipes DROP COLUMN forked_from;
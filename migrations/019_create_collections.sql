@@ -0,0 +1,36 @@
+CREATE TABLE collections (
+    id uuid PRIMARY KEY,
+    owner text NOT NULL REFERENCES "users" (id)
+        ON DELETE CASCADE,
+    name text NOT NULL
+        CONSTRAINT collections_name_len CHECK (length(name) BETWEEN 1 AND 100),
+    description text NOT NULL DEFAULT ''
+        CONSTRAINT collections_description_len CHECK (length(description) <= 2000),
+    -- A collection with a share token may be viewed by anyone with the token.
+    share_token text
+        CONSTRAINT collections_unq_share_token UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX collections_owner_idx ON collections (owner, name);
+
+{{ template "shared/update_time.sql" "collections" }}
+
+CREATE TABLE collection_recipes (
+    collection uuid NOT NULL REFERENCES collections (id)
+        ON DELETE CASCADE,
+    recipe uuid NOT NULL REFERENCES recipes (id)
+        ON DELETE CASCADE,
+    position integer NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (collection, recipe)
+);
+
+CREATE INDEX collection_recipes_position_idx ON collection_recipes (collection, position);
+CREATE INDEX collection_recipes_recipe_idx ON collection_recipes (recipe);
+
+---- create above / drop below ----
+
+DROP TABLE collection_recipes;
+DROP TABLE collections;
@@ -0,0 +1,22 @@
+-- Unsaved changes to the recipe form, saved in the background so that they survive expired
+-- sessions and closed tabs. Drafts of new recipes have no recipe.
+CREATE TABLE recipe_drafts (
+    id uuid PRIMARY KEY,
+    owner text NOT NULL REFERENCES "users" (id)
+        ON DELETE CASCADE,
+    recipe uuid REFERENCES recipes (id)
+        ON DELETE CASCADE,
+    -- The values of the form's fields, keyed by field name.
+    form_values jsonb NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX recipe_drafts_owner_idx ON recipe_drafts (owner, updated_at);
+CREATE INDEX recipe_drafts_recipe_idx ON recipe_drafts (recipe);
+
+{{ template "shared/update_time.sql" "recipe_drafts" }}
+
+---- create above / drop below ----
+
+DROP TABLE recipe_drafts;
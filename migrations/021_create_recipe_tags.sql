@@ -0,0 +1,14 @@
+CREATE TABLE recipe_tags (
+    recipe uuid NOT NULL REFERENCES recipes (id)
+        ON DELETE CASCADE,
+    tag text NOT NULL
+        CONSTRAINT recipe_tags_tag_len CHECK (length(tag) BETWEEN 1 AND 50),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (recipe, tag)
+);
+
+CREATE INDEX recipe_tags_tag_idx ON recipe_tags (tag);
+
+---- create above / drop below ----
+
+DROP TABLE recipe_tags;
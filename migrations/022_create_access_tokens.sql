@@ -0,0 +1,21 @@
+CREATE TABLE access_tokens (
+    id uuid PRIMARY KEY,
+    owner text NOT NULL REFERENCES "users" (id)
+        ON DELETE CASCADE,
+    name text NOT NULL
+        CONSTRAINT access_tokens_name_len CHECK (length(name) BETWEEN 1 AND 100),
+    -- Only a SHA-256 hash of the token is stored, so a leaked database does not leak usable tokens.
+    token_hash bytea NOT NULL
+        CONSTRAINT access_tokens_unq_token_hash UNIQUE,
+    scopes text[] NOT NULL
+        CONSTRAINT access_tokens_scopes_valid CHECK (scopes <@ ARRAY['read', 'write']::text[]),
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX access_tokens_owner_idx ON access_tokens (owner, created_at);
+
+---- create above / drop below ----
+
+DROP TABLE access_tokens;
@@ -0,0 +1,53 @@
+CREATE TABLE webhooks (
+    id uuid PRIMARY KEY,
+    owner text NOT NULL REFERENCES "users" (id)
+        ON DELETE CASCADE,
+    url text NOT NULL
+        CONSTRAINT webhooks_url_len CHECK (length(url) BETWEEN 1 AND 2000),
+    -- The key used to sign payloads. It is stored as-is since it is needed to sign each delivery.
+    secret text NOT NULL,
+    events text[] NOT NULL
+        CONSTRAINT webhooks_events_valid CHECK (
+            cardinality(events) > 0
+            AND events <@ ARRAY[
+                'recipe.created', 'recipe.updated', 'recipe.deleted',
+                'category.created', 'category.updated', 'category.deleted'
+            ]::text[]
+        ),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX webhooks_owner_idx ON webhooks (owner, created_at);
+
+{{ template "shared/update_time.sql" "webhooks" }}
+
+-- Each event sent to a webhook. Deliveries are written when the event happens and sent in the
+-- background, so they survive restarts and can be retried.
+CREATE TABLE webhook_deliveries (
+    id uuid PRIMARY KEY,
+    webhook uuid NOT NULL REFERENCES webhooks (id)
+        ON DELETE CASCADE,
+    event text NOT NULL,
+    payload jsonb NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    -- The time of the next attempt, or NULL once the delivery has succeeded or been abandoned.
+    next_attempt_at TIMESTAMPTZ DEFAULT now(),
+    delivered_at TIMESTAMPTZ,
+    -- The result of the most recent attempt.
+    response_status smallint,
+    error text NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at)
+    WHERE next_attempt_at IS NOT NULL;
+CREATE INDEX webhook_deliveries_webhook_idx ON webhook_deliveries (webhook, created_at);
+
+{{ template "shared/update_time.sql" "webhook_deliveries" }}
+
+---- create above / drop below ----
+
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
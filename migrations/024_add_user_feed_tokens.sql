@@ -0,0 +1,11 @@
+-- A user with a feed token publishes an Atom feed of their recipes to anyone with the token. Only a
+-- SHA-256 hash of the token is stored, like access tokens, so that the database doesn't hold
+-- anything that can be used to read a user's feed.
+ALTER TABLE users
+    ADD COLUMN feed_token_hash bytea
+        CONSTRAINT users_unq_feed_token_hash UNIQUE;
+
+---- create above / drop below ----
+
+ALTER TABLE users
+    DROP COLUMN feed_token_hash;
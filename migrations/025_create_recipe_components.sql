@@ -0,0 +1,23 @@
+-- Links the ingredients of a recipe to the recipes they are made from, such as "1 batch pizza
+-- dough" to a recipe for pizza dough. The ingredient is its normalized name, as with nutrition
+-- matches.
+CREATE TABLE recipe_components (
+    recipe uuid NOT NULL REFERENCES recipes (id)
+        ON DELETE CASCADE,
+    ingredient text NOT NULL
+        CONSTRAINT recipe_components_ingredient_len CHECK (length(ingredient) BETWEEN 1 AND 200),
+    component uuid NOT NULL REFERENCES recipes (id)
+        ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (recipe, ingredient),
+    CONSTRAINT recipe_components_not_self CHECK (component <> recipe)
+);
+
+CREATE INDEX recipe_components_idx_component ON recipe_components (component);
+
+{{ template "shared/update_time.sql" "recipe_components" }}
+
+---- create above / drop below ----
+
+DROP TABLE recipe_components;